@@ -1,5 +1,13 @@
 package main
 
+import (
+	"fmt"
+	"os"
+	dbcontroller "sedb/modules/db_controller"
+	dbinfo "sedb/modules/db_info"
+	"sedb/modules/server"
+)
+
 //test 1
 /*
 func main() {
@@ -48,3 +56,15 @@ func main() {
 		println("Fuck!")
 	}
 }*/
+
+func main() {
+	var info dbinfo.DBInfo
+	if dbinfo.LoadInfo("./db_info.json", &info) != 0 {
+		fmt.Println("error: failed to load db_info.json")
+		os.Exit(1)
+	}
+	if dbcontroller.Startup(info) != 0 {
+		os.Exit(1)
+	}
+	os.Exit(server.DB_server(info.ServerPort))
+}
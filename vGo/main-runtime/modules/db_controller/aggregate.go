@@ -0,0 +1,321 @@
+package dbcontroller
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sedb/modules/parsers"
+	"sedb/modules/table"
+	"strings"
+)
+
+// aggregate는 질의에 나온 집계 함수 하나입니다.
+type aggregate struct {
+	expr *parsers.Expr
+	arg  *table.Column // 인자 열 (COUNT(*)이면 nil)
+	col  table.Column  // 결과 열: 이름은 집계 함수의 표기, 예) SUM(amount)
+}
+
+// aggregates는 질의의 집계 함수 목록입니다. 표기가 같은 집계 함수는 한 번만 계산합니다.
+type aggregates []*aggregate
+
+// add는 집계 함수를 목록에 더하고 결과 열을 반환합니다.
+// SUM과 AVG는 숫자 열만, MIN과 MAX는 값을 비교할 수 있는 열만 받습니다.
+func (aggs *aggregates) add(e *parsers.Expr, columns []table.Column) (table.Column, error) {
+	name := parsers.FormatExpr(e)
+	for _, agg := range *aggs {
+		if agg.col.Name == name {
+			return agg.col, nil
+		}
+	}
+
+	agg := &aggregate{expr: e}
+	if e.Left != nil {
+		idx := findColumnIndex(columns, e.Left.Name)
+		if idx < 0 {
+			return table.Column{}, fmt.Errorf("column '%s' does not exist", e.Left.Name)
+		}
+		agg.arg = &columns[idx]
+	}
+
+	switch e.Name {
+	case "count":
+		agg.col = table.Column{Name: name, Type: table.CT_integer, Not_null: true}
+	case "sum", "avg":
+		if valueClass(agg.arg.Type) != "number" {
+			return table.Column{}, fmt.Errorf("%s needs a numeric column; '%s' is %s",
+				strings.ToUpper(e.Name), agg.arg.Name, columnTypeName(*agg.arg))
+		}
+		agg.col = table.Column{Name: name, Type: agg.arg.Type, Scale: agg.arg.Scale}
+		if e.Name == "avg" {
+			agg.col = table.Column{Name: name, Type: table.CT_float}
+		}
+	case "min", "max":
+		if agg.arg.Type == table.CT_json || agg.arg.Type == table.CT_blob {
+			return table.Column{}, fmt.Errorf("%s cannot compare %s column '%s'",
+				strings.ToUpper(e.Name), columnTypeName(*agg.arg), agg.arg.Name)
+		}
+		agg.col = table.Column{Name: name, Type: agg.arg.Type, Scale: agg.arg.Scale}
+	default:
+		return table.Column{}, fmt.Errorf("unknown aggregate '%s'", e.Name)
+	}
+
+	*aggs = append(*aggs, agg)
+	return agg.col, nil
+}
+
+// collect는 조건식 안의 집계 함수를 모두 목록에 더합니다.
+func (aggs *aggregates) collect(e *parsers.Expr, columns []table.Column) error {
+	if e == nil {
+		return nil
+	}
+	if e.Kind == parsers.EX_aggregate {
+		_, err := aggs.add(e, columns)
+		return err
+	}
+	if err := aggs.collect(e.Left, columns); err != nil {
+		return err
+	}
+	if err := aggs.collect(e.Right, columns); err != nil {
+		return err
+	}
+	for _, item := range e.List {
+		if err := aggs.collect(item, columns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compute는 묶음의 행들로 집계 값을 계산합니다.
+// COUNT(*)는 모든 행을, 나머지는 NULL이 아닌 값만 셉니다. 값이 하나도 없으면 COUNT는 0, 나머지는 NULL입니다.
+// DISTINCT는 같은 값을 한 번만 셉니다.
+func (agg *aggregate) compute(rows []map[string]interface{}) (interface{}, error) {
+	if agg.arg == nil {
+		return int64(len(rows)), nil
+	}
+
+	var values []interface{}
+	seen := make(map[string]bool)
+	for _, data := range rows {
+		value := data[agg.arg.Name]
+		if value == nil {
+			continue
+		}
+		if agg.expr.Distinct {
+			key := formatTffValue(*agg.arg, value)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		values = append(values, value)
+	}
+
+	switch agg.expr.Name {
+	case "count":
+		return int64(len(values)), nil
+	case "min", "max":
+		var best interface{}
+		for _, value := range values {
+			c := compareValues(value, best)
+			if best == nil || (agg.expr.Name == "min" && c < 0) || (agg.expr.Name == "max" && c > 0) {
+				best = value
+			}
+		}
+		return best, nil
+	}
+
+	// SUM, AVG는 정확한 유리수로 더한 뒤 결과 열의 타입으로 바꿉니다.
+	if len(values) == 0 {
+		return nil, nil
+	}
+	sum := new(big.Rat)
+	for _, value := range values {
+		r, ok := numericRat(value)
+		if !ok {
+			return nil, fmt.Errorf("%s: '%s' is not a number", agg.col.Name, formatValue(value))
+		}
+		sum.Add(sum, r)
+	}
+	if agg.expr.Name == "avg" {
+		sum.Quo(sum, new(big.Rat).SetInt64(int64(len(values))))
+	}
+	return ratValue(sum, agg.col)
+}
+
+// ratValue는 유리수를 열 타입의 값으로 바꿉니다. INTEGER 범위를 넘으면 오류입니다.
+func ratValue(r *big.Rat, col table.Column) (interface{}, error) {
+	switch col.Type {
+	case table.CT_integer:
+		if !r.IsInt() || !r.Num().IsInt64() {
+			return nil, fmt.Errorf("%s overflows INTEGER", col.Name)
+		}
+		return r.Num().Int64(), nil
+	case table.CT_decimal:
+		unscaled := new(big.Int).Mul(r.Num(), pow10(col.Scale))
+		return Decimal{Unscaled: unscaled.Quo(unscaled, r.Denom()), Scale: col.Scale}, nil
+	}
+	f, _ := r.Float64()
+	if math.IsInf(f, 0) {
+		return nil, fmt.Errorf("%s overflows %s", col.Name, columnTypeName(col))
+	}
+	return f, nil
+}
+
+// checkGrouped는 조건식이 집계 함수 밖에서 GROUP BY 열만 참조하는지 확인합니다.
+func checkGrouped(e *parsers.Expr, grouped map[string]bool) error {
+	if e == nil || e.Kind == parsers.EX_aggregate {
+		return nil
+	}
+	if e.Kind == parsers.EX_column && !grouped[e.Name] {
+		return fmt.Errorf("column '%s' must appear in GROUP BY or be used in an aggregate", e.Name)
+	}
+	if err := checkGrouped(e.Left, grouped); err != nil {
+		return err
+	}
+	if err := checkGrouped(e.Right, grouped); err != nil {
+		return err
+	}
+	for _, item := range e.List {
+		if err := checkGrouped(item, grouped); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceAggregates는 조건식의 집계 함수를 같은 표기의 집계 결과 열 참조로 바꾼 복사본을 반환합니다.
+func replaceAggregates(e *parsers.Expr) *parsers.Expr {
+	if e == nil {
+		return nil
+	}
+	if e.Kind == parsers.EX_aggregate {
+		return &parsers.Expr{Kind: parsers.EX_column, Name: parsers.FormatExpr(e)}
+	}
+	copied := *e
+	copied.Left = replaceAggregates(e.Left)
+	copied.Right = replaceAggregates(e.Right)
+	if e.List != nil {
+		copied.List = make([]*parsers.Expr, len(e.List))
+		for i, item := range e.List {
+			copied.List[i] = replaceAggregates(item)
+		}
+	}
+	return &copied
+}
+
+// groupRows는 집계 질의의 결과입니다. 행을 GROUP BY 열 값으로 묶어(NULL도 하나의 값) 묶음마다 한 행을 만들고,
+// HAVING 조건이 참인 묶음만 남깁니다. GROUP BY가 없으면 모든 행이 한 묶음이며, 행이 없어도 결과는 한 행입니다.
+// 결과 열 목록을 생략하면 GROUP BY 열을 보여줍니다.
+func groupRows(query *selectQuery, columns []table.Column, rows []map[string]interface{}) (*resultRows, error) {
+	var groupCols []table.Column
+	grouped := make(map[string]bool)
+	for _, name := range query.groupBy {
+		idx := findColumnIndex(columns, name)
+		if idx < 0 {
+			return nil, fmt.Errorf("column '%s' does not exist", name)
+		}
+		if grouped[name] {
+			return nil, fmt.Errorf("column '%s' appears more than once in GROUP BY", name)
+		}
+		grouped[name] = true
+		groupCols = append(groupCols, columns[idx])
+	}
+
+	var aggs aggregates
+	output := groupCols
+	if len(query.items) > 0 {
+		output = make([]table.Column, 0, len(query.items))
+		for _, item := range query.items {
+			if item.Kind == parsers.EX_aggregate {
+				col, err := aggs.add(item, columns)
+				if err != nil {
+					return nil, err
+				}
+				output = append(output, col)
+				continue
+			}
+			if err := checkGrouped(item, grouped); err != nil {
+				return nil, err
+			}
+			output = append(output, groupCols[findColumnIndex(groupCols, item.Name)])
+		}
+	} else if len(groupCols) == 0 {
+		return nil, fmt.Errorf("aggregate query without GROUP BY needs a column list")
+	}
+	if query.having != nil {
+		if err := checkGrouped(query.having, grouped); err != nil {
+			return nil, fmt.Errorf("invalid HAVING condition: %v", err)
+		}
+		if err := aggs.collect(query.having, columns); err != nil {
+			return nil, fmt.Errorf("invalid HAVING condition: %v", err)
+		}
+	}
+	for _, key := range query.order {
+		if err := aggs.collect(key.expr, columns); err != nil {
+			return nil, err
+		}
+	}
+
+	// 묶음은 처음 나온 순서를 유지합니다.
+	var groups [][]map[string]interface{}
+	if len(groupCols) == 0 {
+		groups = append(groups, rows)
+	} else {
+		index := make(map[string]int)
+		for _, data := range rows {
+			key := valuesKey(groupCols, data)
+			idx, ok := index[key]
+			if !ok {
+				idx = len(groups)
+				index[key] = idx
+				groups = append(groups, nil)
+			}
+			groups[idx] = append(groups[idx], data)
+		}
+	}
+
+	res := &resultRows{
+		columns: append([]table.Column{}, groupCols...),
+		output:  output,
+		rows:    make([]map[string]interface{}, 0, len(groups)),
+		unique:  groupCols,
+	}
+	for _, agg := range aggs {
+		res.columns = append(res.columns, agg.col)
+	}
+	for _, group := range groups {
+		data := make(map[string]interface{}, len(res.columns))
+		for _, col := range groupCols {
+			data[col.Name] = group[0][col.Name]
+		}
+		for _, agg := range aggs {
+			value, err := agg.compute(group)
+			if err != nil {
+				return nil, err
+			}
+			data[agg.col.Name] = value
+		}
+		res.rows = append(res.rows, data)
+	}
+
+	if query.having != nil {
+		cond := replaceAggregates(query.having)
+		if err := bindCondition(cond, res.columns); err != nil {
+			return nil, fmt.Errorf("invalid HAVING condition: %v", err)
+		}
+		kept := res.rows[:0]
+		for _, data := range res.rows {
+			t, err := evalCondition(cond, res.columns, data)
+			if err != nil {
+				return nil, err
+			}
+			if t == truthTrue {
+				kept = append(kept, data)
+			}
+		}
+		res.rows = kept
+	}
+	return res, nil
+}
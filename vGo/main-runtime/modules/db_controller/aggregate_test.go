@@ -0,0 +1,121 @@
+package dbcontroller
+
+import (
+	dbinfo "sedb/modules/db_info"
+	"testing"
+)
+
+// newOrdersDB는 집계 테스트에 쓰는 orders 테이블을 만듭니다.
+func newOrdersDB(t *testing.T) dbinfo.DBInfo {
+	t.Helper()
+	info := newTestDB(t)
+	mustExec(t, info, `create_table orders (integer id NOTNULL KEY, text region, text item, integer price);`)
+	mustExec(t, info, `add orders (1, "east", "pen", 100);`)
+	mustExec(t, info, `add orders (2, "west", "pen", 200);`)
+	mustExec(t, info, `add orders (3, "east", "ink", 300);`)
+	mustExec(t, info, `add orders (4, NULL, "pen", NULL);`)
+	mustExec(t, info, `add orders (5, "west", "cap", 50);`)
+	mustExec(t, info, `add orders (6, "east", "pen", NULL);`)
+	return info
+}
+
+func TestAggregateWithoutGroupBy(t *testing.T) {
+	info := newOrdersDB(t)
+
+	res := mustQuery(t, info, `select orders (count(*), count(price), count(distinct item), sum(price), avg(price), min(item), max(price));`)
+	checkValues(t, res, "COUNT(*)", int64(6))
+	checkValues(t, res, "COUNT(price)", int64(4))
+	checkValues(t, res, "COUNT(DISTINCT item)", int64(3))
+	checkValues(t, res, "SUM(price)", int64(650))
+	checkValues(t, res, "AVG(price)", 162.5)
+	checkValues(t, res, "MIN(item)", "cap")
+	checkValues(t, res, "MAX(price)", int64(300))
+
+	// 고른 행이 없어도 결과는 한 행이며, COUNT 외의 집계는 NULL입니다.
+	res = mustQuery(t, info, `select orders (count(*), sum(price), max(item)) where price > 1000;`)
+	checkValues(t, res, "COUNT(*)", int64(0))
+	checkValues(t, res, "SUM(price)", nil)
+	checkValues(t, res, "MAX(item)", nil)
+}
+
+func TestAggregateGroupBy(t *testing.T) {
+	info := newOrdersDB(t)
+
+	// 정렬하지 않으면 묶음이 처음 나온 순서이고, NULL도 하나의 묶음입니다.
+	res := mustQuery(t, info, `select orders (region, count(*), sum(price)) group by region;`)
+	checkValues(t, res, "region", "east", "west", nil)
+	checkValues(t, res, "COUNT(*)", int64(3), int64(2), int64(1))
+	checkValues(t, res, "SUM(price)", int64(400), int64(250), nil)
+
+	res = mustQuery(t, info, `select orders (region, count(*)) where item = "pen" group by region having count(*) > 1;`)
+	checkValues(t, res, "region", "east")
+	checkValues(t, res, "COUNT(*)", int64(2))
+
+	res = mustQuery(t, info, `select orders (region, sum(price)) group by region order by sum(price) desc;`)
+	checkValues(t, res, "region", "east", "west", nil)
+
+	// 열 목록을 생략하면 GROUP BY 열을 보여줍니다.
+	res = mustQuery(t, info, `select orders group by region, item order by region, item limit 2;`)
+	if len(res.Columns) != 2 || res.Columns[0] != "region" || res.Columns[1] != "item" {
+		t.Errorf("columns: got %v, want [region item]", res.Columns)
+	}
+	checkValues(t, res, "item", "pen", "ink")
+	if res.Cursor == "" {
+		t.Error("expected a cursor for the remaining groups")
+	}
+}
+
+func TestSelectDistinct(t *testing.T) {
+	info := newOrdersDB(t)
+
+	res := mustQuery(t, info, `select distinct orders (region);`)
+	checkValues(t, res, "region", "east", "west", nil)
+
+	res = mustQuery(t, info, `select distinct orders (region, item) order by region desc, item;`)
+	checkValues(t, res, "item", "cap", "pen", "ink", "pen", "pen")
+}
+
+func TestAggregateErrors(t *testing.T) {
+	info := newOrdersDB(t)
+
+	for _, script := range []string{
+		`select orders (sum(item));`,
+		`select orders (region, count(*));`,
+		`select orders (count(*)) where count(*) > 1;`,
+		`select orders (region, count(*)) group by region, region;`,
+		`select orders (region) group by item;`,
+		`select orders group by region having price > 1;`,
+		`select orders (region, count(*)) group by region order by item;`,
+		`select orders count(*);`,
+		`select orders (sum(*));`,
+	} {
+		if _, err := Query(script, info); err == nil {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+}
+
+func TestAggregateSumOverflow(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table big (integer id NOTNULL KEY, integer n);`)
+	mustExec(t, info, `add big (1, 9223372036854775807);`)
+	mustExec(t, info, `add big (2, 1);`)
+
+	if _, err := Query(`select big (sum(n));`, info); err == nil {
+		t.Error("expected INTEGER overflow error")
+	}
+	res := mustQuery(t, info, `select big (sum(n)) where id = 1;`)
+	checkValues(t, res, "SUM(n)", int64(9223372036854775807))
+}
+
+func TestAggregateWordsAsColumnNames(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table count (integer id NOTNULL KEY, text group, integer sum);`)
+	mustExec(t, info, `add count (1, "a", 3);`)
+	mustExec(t, info, `add count (2, "a", 5);`)
+	mustExec(t, info, `add count (3, "b", 1);`)
+
+	res := mustQuery(t, info, `select count (group, max(sum)) group by group having count(*) > 1;`)
+	checkValues(t, res, "group", "a")
+	checkValues(t, res, "MAX(sum)", int64(5))
+}
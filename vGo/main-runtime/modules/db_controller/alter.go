@@ -0,0 +1,428 @@
+package dbcontroller
+
+import (
+	"fmt"
+	dbinfo "sedb/modules/db_info"
+	"sedb/modules/parsers"
+	"sedb/modules/table"
+	"strings"
+)
+
+// findColumnIndex는 이름이 name인 열의 위치를 반환합니다. 없으면 -1입니다.
+func findColumnIndex(columns []table.Column, name string) int {
+	for i, col := range columns {
+		if col.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// columnNameAt은 tokens[i]가 열 이름이면 그 이름을 반환합니다.
+func columnNameAt(tokens []parsers.SC_token, i int) (string, bool) {
+	if i >= len(tokens) || tokens[i].Token_type != parsers.SC_columnName {
+		return "", false
+	}
+	name, ok := tokens[i].Token.(string)
+	return name, ok && name != ""
+}
+
+// expectEnd는 tokens[i]부터 명령 끝(; 또는 토큰 끝)인지 확인합니다.
+func expectEnd(tokens []parsers.SC_token, i int) string {
+	if i < len(tokens) && tokens[i].Token_type != parsers.SC_endCmd {
+		return fmt.Sprintf("syntax error: unexpected '%v' in ALTER_TABLE statement", tokens[i].Token)
+	}
+	return ""
+}
+
+// convertColumnValue는 기존 값을 정규화된 표기를 거쳐 col 타입의 값으로 변환합니다.
+// DECIMAL 값은 소수부 끝의 0을 떼어 내므로 1.00은 INTEGER 1이나 DECIMAL(1) 1.0이 됩니다.
+// NULL은 그대로 NULL입니다.
+func convertColumnValue(col table.Column, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	raw := formatValue(value)
+	if _, ok := value.(Decimal); ok && col.Type != table.CT_text && strings.Contains(raw, ".") {
+		raw = strings.TrimSuffix(strings.TrimRight(raw, "0"), ".")
+	}
+	return convertValue(col, raw)
+}
+
+// copyRowData는 행 데이터를 복사합니다. 바꾼 값을 반영하기 전에 확인할 때 사용합니다.
+func copyRowData(data map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		copied[k] = v
+	}
+	return copied
+}
+
+// alterAddColumn은 열을 끝에 추가하고 기존 행에 기본값을 채웁니다.
+// 문법: add_column [열 타입] [열 이름] NOTNULL(선택) UNIQUE(선택) DEFAULT [값](선택) CHECK (조건식)(선택) REFERENCES [테이블 이름](선택)
+// CHECK 제약이나 외래 키가 있으면 기존 행이 채워진 값으로 제약을 만족해야 합니다.
+func alterAddColumn(tableData *TableData, tableName string, dbInfo dbinfo.DBInfo, tokens []parsers.SC_token, i int) (string, string) {
+	colType, scale, i, errMsg := parseColumnType(tokens, i)
+	if errMsg != "" {
+		return "", errMsg
+	}
+
+	name, ok := columnNameAt(tokens, i)
+	if !ok {
+		return "", "syntax error: column name is missing"
+	}
+	i++
+	if findColumnIndex(tableData.Columns, name) >= 0 {
+		return "", fmt.Sprintf("error: column '%s' already exists", name)
+	}
+
+	col := table.Column{Type: colType, Name: name, Scale: scale}
+	checks := append([]table.Check(nil), tableData.Checks...)
+	defaultStart := -1
+	for i < len(tokens) && tokens[i].Token_type != parsers.SC_endCmd {
+		switch tokens[i].Token_type {
+		case parsers.SC_check, parsers.SC_constraint:
+			constraint, expr, next, errMsg := parseCheckClause(tokens, i)
+			if errMsg != "" {
+				return "", errMsg
+			}
+			if constraint == "" {
+				constraint = checkName(checks, name+"_check")
+			}
+			for _, check := range checks {
+				if check.Name == constraint {
+					return "", fmt.Sprintf("error: constraint '%s' already exists", constraint)
+				}
+			}
+			checks = append(checks, table.Check{Name: constraint, Expr: parsers.FormatExpr(expr)})
+			i = next
+			continue
+		case parsers.SC_references:
+			parent, action, next, errMsg := parseReferencesClause(tokens, i)
+			if errMsg != "" {
+				return "", errMsg
+			}
+			col.References, col.On_delete = parent, action
+			i = next
+			continue
+		case parsers.SC_notNull:
+			col.Not_null = true
+		case parsers.SC_key:
+			return "", "error: only one KEY column is allowed per table"
+		case parsers.SC_unique:
+			col.Unique = true
+		case parsers.SC_generated:
+			return "", "error: generated keys can only be declared on KEY columns when the table is created"
+		case parsers.SC_default:
+			defaultStart = i + 1
+			i++
+			if i >= len(tokens) || tokens[i].Token_type == parsers.SC_comma ||
+				tokens[i].Token_type == parsers.SC_parenClose || tokens[i].Token_type == parsers.SC_endCmd {
+				return "", "syntax error: default value is missing"
+			}
+			if i < len(tokens) && tokens[i].Token_type == parsers.SC_func {
+				i += 2 // 함수 이름 뒤의 ()
+			}
+		default:
+			return "", fmt.Sprintf("syntax error: unknown attribute '%v'", tokens[i].Token)
+		}
+		i++
+	}
+	if defaultStart >= 0 {
+		if _, errMsg := parseDefaultClause(&col, tokens, defaultStart); errMsg != "" {
+			return "", errMsg
+		}
+	}
+
+	if col.Not_null && (col.Default_kind == table.DK_none || col.Default_kind == table.DK_null) && len(tableData.Rows) > 0 {
+		return "", fmt.Sprintf("error: column '%s' is NOTNULL and needs a default value for existing rows", name)
+	}
+
+	// 함수 기본값은 행마다 계산합니다. (UUID()는 행마다 다른 값)
+	values := make([]interface{}, len(tableData.Rows))
+	for r := range tableData.Rows {
+		value, err := evalDefault(col)
+		if err != nil {
+			return "", fmt.Sprintf("error: failed to compute default value: %v", err)
+		}
+		values[r] = value
+	}
+	if col.Unique {
+		if r, value := findUniqueDuplicate(tableData.Rows, col, values); r >= 0 {
+			return "", fmt.Sprintf("error: column '%s' is UNIQUE but the default value '%s' would repeat in row '%s'",
+				name, value, tableData.Rows[r].Key)
+		}
+	}
+
+	columns := append(append([]table.Column(nil), tableData.Columns...), col)
+	if col.References != "" {
+		if err := checkReferenceTarget(col, tableName, columns, dbInfo); err != nil {
+			return "", fmt.Sprintf("error: %v", err)
+		}
+		for r, row := range tableData.Rows {
+			if values[r] == nil {
+				continue
+			}
+			found, err := referenceExists(col, values[r], tableName, tableData, row.Key, dbInfo)
+			if err != nil {
+				return "", fmt.Sprintf("error: %v", err)
+			}
+			if !found {
+				return "", fmt.Sprintf("error: row '%s': value '%s' does not exist in table '%s'",
+					row.Key, formatValue(values[r]), col.References)
+			}
+		}
+	}
+	if len(checks) > len(tableData.Checks) {
+		if _, err := compileChecks(checks, columns); err != nil {
+			return "", fmt.Sprintf("error: %v", err)
+		}
+		for r, row := range tableData.Rows {
+			data := copyRowData(row.Data)
+			data[name] = values[r]
+			if err := checkRow(checks, columns, data); err != nil {
+				return "", fmt.Sprintf("error: row '%s': %v", row.Key, err)
+			}
+		}
+	}
+
+	tableData.Columns = columns
+	tableData.Checks = checks
+	for r, row := range tableData.Rows {
+		row.Data[name] = values[r]
+	}
+	return fmt.Sprintf("column '%s' added", name), ""
+}
+
+// alterDropColumn은 열과 모든 행의 해당 값을 삭제합니다. KEY 열은 삭제할 수 없습니다.
+// 문법: drop_column [열 이름]
+func alterDropColumn(tableData *TableData, tokens []parsers.SC_token, i int) (string, string) {
+	name, ok := columnNameAt(tokens, i)
+	if !ok {
+		return "", "syntax error: column name is missing"
+	}
+	if errMsg := expectEnd(tokens, i+1); errMsg != "" {
+		return "", errMsg
+	}
+
+	idx := findColumnIndex(tableData.Columns, name)
+	if idx < 0 {
+		return "", fmt.Sprintf("error: column '%s' does not exist", name)
+	}
+	if tableData.Columns[idx].Is_key {
+		return "", fmt.Sprintf("error: cannot drop KEY column '%s'", name)
+	}
+	if check := checkUsesColumn(tableData.Checks, name); check != "" {
+		return "", fmt.Sprintf("error: cannot drop column '%s' used by CHECK constraint '%s'", name, check)
+	}
+
+	tableData.Columns = append(tableData.Columns[:idx:idx], tableData.Columns[idx+1:]...)
+	for _, row := range tableData.Rows {
+		delete(row.Data, name)
+	}
+	return fmt.Sprintf("column '%s' dropped", name), ""
+}
+
+// alterRenameColumn은 열 이름을 바꿉니다.
+// 문법: rename_column [열 이름] TO [새 이름]
+func alterRenameColumn(tableData *TableData, tokens []parsers.SC_token, i int) (string, string) {
+	oldName, ok := columnNameAt(tokens, i)
+	if !ok {
+		return "", "syntax error: column name is missing"
+	}
+	if i+1 >= len(tokens) || tokens[i+1].Token_type != parsers.SC_to {
+		return "", "syntax error: expected TO after column name"
+	}
+	newName, ok := columnNameAt(tokens, i+2)
+	if !ok {
+		return "", "syntax error: new column name is missing"
+	}
+	if errMsg := expectEnd(tokens, i+3); errMsg != "" {
+		return "", errMsg
+	}
+
+	idx := findColumnIndex(tableData.Columns, oldName)
+	if idx < 0 {
+		return "", fmt.Sprintf("error: column '%s' does not exist", oldName)
+	}
+	if findColumnIndex(tableData.Columns, newName) >= 0 {
+		return "", fmt.Sprintf("error: column '%s' already exists", newName)
+	}
+
+	tableData.Columns[idx].Name = newName
+	renameCheckColumn(tableData.Checks, oldName, newName)
+	for _, row := range tableData.Rows {
+		row.Data[newName] = row.Data[oldName]
+		delete(row.Data, oldName)
+	}
+	return fmt.Sprintf("column '%s' renamed to '%s'", oldName, newName), ""
+}
+
+// alterModifyColumn은 열 타입을 바꾸고 모든 행의 값을 새 타입으로 변환합니다.
+// 변환할 수 없는 값이 하나라도 있으면 아무것도 바꾸지 않습니다.
+// KEY 열이나 UNIQUE 열의 타입을 바꾸면 변환 후 값이 겹치지 않는지 확인하고, 변환된 값으로 CHECK 제약을 다시 확인합니다.
+// 외래 키 열은 참조하는 테이블의 키와 타입이 같아야 하며, 다른 테이블이 참조하는 KEY 열의 타입은 바꿀 수 없습니다.
+// 문법: modify_column [열 이름] [새 열 타입]
+func alterModifyColumn(tableData *TableData, tableName string, dbInfo dbinfo.DBInfo, tokens []parsers.SC_token, i int) (string, string) {
+	name, ok := columnNameAt(tokens, i)
+	if !ok {
+		return "", "syntax error: column name is missing"
+	}
+	colType, scale, next, errMsg := parseColumnType(tokens, i+1)
+	if errMsg != "" {
+		return "", errMsg
+	}
+	if errMsg := expectEnd(tokens, next); errMsg != "" {
+		return "", errMsg
+	}
+
+	idx := findColumnIndex(tableData.Columns, name)
+	if idx < 0 {
+		return "", fmt.Sprintf("error: column '%s' does not exist", name)
+	}
+	col := tableData.Columns[idx]
+	col.Type = colType
+	col.Scale = scale
+
+	// 리터럴 기본값도 새 타입으로 변환합니다.
+	if col.Default_kind == table.DK_value {
+		old, err := convertValue(tableData.Columns[idx], col.Default)
+		if err == nil {
+			old, err = convertColumnValue(col, old)
+		}
+		if err != nil {
+			return "", fmt.Sprintf("error: cannot convert default value to %s: %v", columnTypeName(col), err)
+		}
+		col.Default = formatValue(old)
+	}
+	if err := checkDefault(col); err != nil {
+		return "", fmt.Sprintf("error: %v", err)
+	}
+
+	columns := append([]table.Column(nil), tableData.Columns...)
+	columns[idx] = col
+	if errMsg := checkGenerated(columns); errMsg != "" {
+		return "", errMsg
+	}
+
+	if col.References != "" {
+		if err := checkReferenceTarget(col, tableName, columns, dbInfo); err != nil {
+			return "", fmt.Sprintf("error: %v", err)
+		}
+	}
+	if col.Is_key {
+		refs, err := referencingColumns(tableName, dbInfo)
+		if err != nil {
+			return "", fmt.Sprintf("error: %v", err)
+		}
+		for _, ref := range refs {
+			if ref.table != tableName || ref.column.Name != name {
+				return "", fmt.Sprintf("error: cannot change type of KEY column '%s' referenced by column '%s' of table '%s'",
+					name, ref.column.Name, ref.table)
+			}
+		}
+	}
+
+	// 먼저 모든 값을 변환해 보고, 모두 성공한 경우에만 반영합니다.
+	// 키 열이면 바뀐 값으로 행 키(복합 키는 전체 튜플)를 다시 만듭니다.
+	converted := make([]interface{}, len(tableData.Rows))
+	newKeys := make([]string, len(tableData.Rows))
+	keys := make(map[string]bool)
+	for r, row := range tableData.Rows {
+		value, err := convertColumnValue(col, row.Data[name])
+		if err != nil {
+			return "", fmt.Sprintf("error: cannot convert row '%s' to %s: %v", row.Key, columnTypeName(col), err)
+		}
+		if col.Is_key {
+			data := copyRowData(row.Data)
+			data[name] = value
+			key, _ := rowKey(columns, data)
+			if keys[key] {
+				return "", fmt.Sprintf("error: key '%s' would be duplicated after conversion", key)
+			}
+			keys[key] = true
+			newKeys[r] = key
+		}
+		converted[r] = value
+	}
+	if col.Unique {
+		if r, value := findUniqueDuplicate(tableData.Rows, col, converted); r >= 0 {
+			return "", fmt.Sprintf("error: unique value '%s' would be duplicated after conversion (row '%s')",
+				value, tableData.Rows[r].Key)
+		}
+	}
+
+	// CHECK 제약의 값은 새 타입으로 다시 변환되므로 변환된 행으로 다시 확인합니다.
+	if _, err := compileChecks(tableData.Checks, columns); err != nil {
+		return "", fmt.Sprintf("error: %v", err)
+	}
+	for r, row := range tableData.Rows {
+		data := copyRowData(row.Data)
+		data[name] = converted[r]
+		if err := checkRow(tableData.Checks, columns, data); err != nil {
+			return "", fmt.Sprintf("error: row '%s': %v", row.Key, err)
+		}
+	}
+
+	tableData.Columns[idx] = col
+	for r := range tableData.Rows {
+		tableData.Rows[r].Data[name] = converted[r]
+		if col.Is_key {
+			tableData.Rows[r].Key = newKeys[r]
+		}
+	}
+	if col.Is_key {
+		sortRowsByKey(tableData.Columns, tableData.Rows)
+	}
+	return fmt.Sprintf("column '%s' changed to %s", name, columnTypeName(col)), ""
+}
+
+// handleAlterTable은 ALTER_TABLE 명령을 처리합니다.
+// 열을 추가, 삭제, 이름 변경, 타입 변경한 뒤 테이블 파일 전체를 새 구조로 다시 씁니다.
+// 다시 쓰기는 임시 파일을 거치므로 도중에 중단되어도 이전 구조의 파일이 남습니다.
+func handleAlterTable(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
+	if len(tokens) < 4 {
+		return printError("syntax error: incomplete ALTER_TABLE statement")
+	}
+
+	if tokens[1].Token_type != parsers.SC_tableName {
+		return printError("syntax error: expected table name")
+	}
+	tableName := tokens[1].Token.(string)
+
+	if !tableExists(tableName, dbInfo) {
+		return printError(fmt.Sprintf("error: table '%s' does not exist", tableName))
+	}
+
+	tableData, err := loadTableData(tableName, dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: failed to load table: %v", err))
+	}
+
+	var done, errMsg string
+	switch tokens[2].Token_type {
+	case parsers.SC_addColumn:
+		done, errMsg = alterAddColumn(tableData, tableName, dbInfo, tokens, 3)
+	case parsers.SC_dropColumn:
+		done, errMsg = alterDropColumn(tableData, tokens, 3)
+	case parsers.SC_renameColumn:
+		done, errMsg = alterRenameColumn(tableData, tokens, 3)
+	case parsers.SC_modifyColumn:
+		done, errMsg = alterModifyColumn(tableData, tableName, dbInfo, tokens, 3)
+	default:
+		return printError(fmt.Sprintf("syntax error: unknown ALTER_TABLE action '%v'", tokens[2].Token))
+	}
+	if errMsg != "" {
+		return printError(errMsg)
+	}
+
+	if err := saveTableData(tableData, tableName, dbInfo); err != nil {
+		return printError(fmt.Sprintf("error: failed to save table: %v", err))
+	}
+	if err := catalogPutTable(tableName, tableData, dbInfo); err != nil {
+		return printError(fmt.Sprintf("error: failed to update catalog: %v", err))
+	}
+
+	fmt.Printf("Table '%s' altered successfully: %s\n", tableName, done)
+	return 0
+}
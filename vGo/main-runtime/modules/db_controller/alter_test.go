@@ -0,0 +1,78 @@
+package dbcontroller
+
+import (
+	dbinfo "sedb/modules/db_info"
+	"testing"
+)
+
+// checkColumnValues는 키 순서와 상관없이 각 행의 한 열 값이 want와 같은지 확인합니다.
+func checkColumnValues(t *testing.T, info dbinfo.DBInfo, tableName, column string, want map[string]string) {
+	t.Helper()
+	tableData, err := loadTableData(tableName, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(tableData.Rows), len(want))
+	}
+	for _, row := range tableData.Rows {
+		value, ok := row.Data[column]
+		if !ok {
+			t.Fatalf("row %s has no column '%s'", row.Key, column)
+		}
+		if got := formatValue(value); got != want[row.Key] {
+			t.Errorf("row %s column %s: got %s, want %s", row.Key, column, got, want[row.Key])
+		}
+	}
+}
+
+func TestAlterTable(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, decimal(2) price, text name);`)
+	mustExec(t, info, `add t (1, 1.00, "kim");`)
+	mustExec(t, info, `add t (2, NULL, "lee");`)
+
+	mustExec(t, info, `alter_table t add_column bool active NOTNULL default true;`)
+	checkColumnValues(t, info, "t", "active", map[string]string{"1": "true", "2": "true"})
+
+	mustExec(t, info, `alter_table t rename_column name to nick;`)
+	checkColumnValues(t, info, "t", "nick", map[string]string{"1": "kim", "2": "lee"})
+
+	// 소수부 끝의 0을 떼고 변환하므로 1.00은 INTEGER 1이 되고, NULL은 NULL로 남습니다.
+	mustExec(t, info, `alter_table t modify_column price integer;`)
+	checkColumnValues(t, info, "t", "price", map[string]string{"1": "1", "2": "NULL"})
+
+	mustExec(t, info, `alter_table t drop_column nick;`)
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Columns) != 3 || findColumnIndex(tableData.Columns, "nick") >= 0 {
+		t.Errorf("columns after drop = %v", tableData.Columns)
+	}
+}
+
+func TestAlterTableErrors(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, text name);`)
+	mustExec(t, info, `add t (1, "kim");`)
+
+	for _, script := range []string{
+		`alter_table missing add_column text x;`,
+		`alter_table t add_column text name;`,
+		`alter_table t add_column text x NOTNULL;`,
+		`alter_table t add_column integer x default "a";`,
+		`alter_table t add_column integer x KEY;`,
+		`alter_table t drop_column id;`,
+		`alter_table t drop_column missing;`,
+		`alter_table t rename_column name to id;`,
+		`alter_table t modify_column name integer;`,
+		`alter_table t shuffle_column name;`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+	// 실패한 변경은 아무것도 바꾸지 않습니다.
+	checkColumnValues(t, info, "t", "name", map[string]string{"1": "kim"})
+}
@@ -0,0 +1,93 @@
+package dbcontroller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tmpSuffix는 원자적 쓰기 중인 임시 파일에 붙는 확장자입니다.
+const tmpSuffix = ".tmp"
+
+// writeFileAtomic은 write로 path의 새 내용을 임시 파일에 기록한 뒤
+// fsync 후 rename으로 원자적으로 교체합니다.
+// 실패하면 임시 파일을 지우고 기존 파일은 그대로 둡니다.
+func writeFileAtomic(path string, write func(file *os.File) error) error {
+	tmpPath := path + tmpSuffix
+
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if err := write(file); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// 내용이 디스크에 기록된 뒤에만 교체
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// rename 자체가 유실되지 않도록 디렉토리 엔트리도 동기화
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir은 디렉토리 엔트리 변경 사항을 디스크에 동기화합니다.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// cleanupTempFiles는 중단된 쓰기로 남은 임시 파일을 dirs의 디렉토리에서 제거합니다.
+// rename 전에 중단되었다면 원본 파일은 이전 버전 그대로이므로 임시 파일만 지우면 됩니다.
+// 없는 디렉토리는 건너뛰며, 반환값은 지운 파일의 경로입니다.
+func cleanupTempFiles(dirs ...string) ([]string, error) {
+	var removed []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return removed, err
+		}
+
+		count := len(removed)
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), tmpSuffix) {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if err := os.Remove(path); err != nil {
+				return removed, fmt.Errorf("failed to remove temp file '%s': %v", path, err)
+			}
+			removed = append(removed, path)
+		}
+
+		if len(removed) > count {
+			if err := syncDir(dir); err != nil {
+				return removed, err
+			}
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,117 @@
+package dbcontroller
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "t.tff")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 쓰기가 실패하면 기존 파일은 그대로이고 임시 파일은 남지 않습니다.
+	err := writeFileAtomic(path, func(file *os.File) error {
+		file.WriteString("partial")
+		return errors.New("disk full")
+	})
+	if err == nil {
+		t.Fatal("expected the write error")
+	}
+	checkFileContent(t, path, "old")
+	if _, err := os.Stat(path + tmpSuffix); !os.IsNotExist(err) {
+		t.Errorf("temp file was left behind (stat err %v)", err)
+	}
+
+	if err := writeFileAtomic(path, func(file *os.File) error {
+		_, err := file.WriteString("new")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	checkFileContent(t, path, "new")
+	if _, err := os.Stat(path + tmpSuffix); !os.IsNotExist(err) {
+		t.Errorf("temp file was left behind (stat err %v)", err)
+	}
+}
+
+// checkFileContent는 파일 내용이 want와 같은지 확인합니다.
+func checkFileContent(t *testing.T, path, want string) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != want {
+		t.Errorf("%s: got %q, want %q", path, content, want)
+	}
+}
+
+func TestStartupRemovesTempFiles(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (number id NOTNULL KEY, text name);`)
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tableData.Rows = append(tableData.Rows, Row{Key: "1", Data: map[string]interface{}{"id": "1", "name": "a"}})
+	if err := saveTableData(tableData, "t", info); err != nil {
+		t.Fatal(err)
+	}
+
+	// rename 전에 중단된 쓰기는 기존 파일을 건드리지 않고 임시 파일만 남깁니다.
+	tmpPath := tableFilePath("t", info) + tmpSuffix
+	if err := os.WriteFile(tmpPath, []byte("Title : \"t\"\n\nTABLE_S BEG"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if Startup(info) != 0 {
+		t.Fatal("startup failed")
+	}
+	if _, err := os.Stat(tmpPath); !os.IsNotExist(err) {
+		t.Errorf("temp file was not removed (stat err %v)", err)
+	}
+
+	tableData, err = loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 1 || tableData.Rows[0].Key != "1" {
+		t.Errorf("rows = %v, want only key 1", tableData.Rows)
+	}
+}
+
+func TestStartupRemovesTempFilesOutsideTables(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY);`)
+	mustExec(t, info, `drop_table t;`)
+
+	// 카탈로그, WAL 체크포인트, 격리 파일의 쓰기도 rename 전에 중단되면 임시 파일을 남깁니다.
+	tmpPaths := []string{
+		catalogFilePath(info) + tmpSuffix,
+		walFilePath(info) + tmpSuffix,
+		filepath.Join(archiveDirPath(info), "t.20250101-120000.quarantine"+tmpSuffix),
+	}
+	for _, path := range tmpPaths {
+		if err := os.WriteFile(path, []byte("partial"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if Startup(info) != 0 {
+		t.Fatal("startup failed")
+	}
+	for _, path := range tmpPaths {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed (stat err %v)", path, err)
+		}
+	}
+	// 임시 파일이 아닌 파일은 그대로 둡니다.
+	if archived, _ := filepath.Glob(filepath.Join(archiveDirPath(info), "t.*.tff")); len(archived) != 1 {
+		t.Errorf("archived files = %v, want the dropped table", archived)
+	}
+	if _, exists, err := readCatalog(info); err != nil || !exists {
+		t.Errorf("catalog is missing after startup (exists %v, err %v)", exists, err)
+	}
+}
@@ -0,0 +1,837 @@
+package dbcontroller
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	dbinfo "sedb/modules/db_info"
+	"sedb/modules/parsers"
+	"sedb/modules/table"
+	"strings"
+	"time"
+)
+
+// 바이너리 TFF v2 형식
+//
+// 파일은 binaryPageSize 크기의 페이지로 이루어지며, 모든 페이지의 마지막 4바이트는
+// 그 앞 내용의 CRC32입니다.
+//
+//	헤더 페이지 (0번): 매직(8) | 버전(2) | 페이지 크기(4) | 헤더 길이(4) | 텍스트 TFF 헤더
+//	데이터 페이지:     종류(1) | 플래그(1) | 레코드 수(2) | Lsn(8) | 파일 체크섬(4) | 행 디렉토리 | ... | 레코드
+//
+// 행 디렉토리는 레코드마다 (오프셋(2), 길이(2))이며, 레코드는 페이지 끝에서부터 채웁니다.
+// 레코드는 끝에 자신의 CRC32(4)를 담고, 파일 체크섬은 그 페이지 앞까지의 파일 내용 CRC32입니다.
+// 한 명령이 덧붙이는 레코드 묶음은 하나 이상의 페이지이며, 마지막 페이지에
+// pageFlagCommit이 있어야 확정됩니다. 묶음이 마지막 확정 페이지에 모두 들어가면
+// 새 페이지 대신 그 페이지에 더해 다시 씁니다(fillLastPage).
+const (
+	binaryMagic        = "SEDBTFF2"
+	binaryVersion      = 2
+	binaryPageSize     = 8192
+	pageChecksumSize   = 4
+	headerPagePrefix   = 18
+	dataPageHeaderSize = 16
+	slotSize           = 4
+	recordChecksumSize = 4
+)
+
+// 데이터 페이지 종류와 플래그
+const (
+	pageTypeData   byte = 1
+	pageFlagCommit byte = 1 // 레코드 묶음의 마지막 페이지
+)
+
+// 레코드 종류
+const (
+	recordData      byte = 0
+	recordTombstone byte = 1
+)
+
+// 값 타입 태그
+const (
+	valueNull    byte = 0
+	valueInt64   byte = 1 // 8바이트 빅 엔디언
+	valueFloat64 byte = 2 // IEEE 754 8바이트 빅 엔디언
+	valueDecimal byte = 3 // 부호(1) | 길이(uvarint) | 절댓값 바이트 (자릿수는 헤더의 열 정의)
+	valueText    byte = 4 // 길이(uvarint) | UTF-8 바이트 (JSON 포함)
+	valueBool    byte = 5 // 1바이트 (0 또는 1)
+	valueDate    byte = 6 // 1970-01-01부터의 일 수 (varint)
+	valueTime    byte = 7 // 유닉스 초(8) | 나노초(4), 빅 엔디언, UTC
+	valueBlob    byte = 8 // 길이(uvarint) | 바이트
+)
+
+// unixEpochDate는 DATE 값의 기준 날짜입니다.
+var unixEpochDate = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// isBinaryTableFile은 파일이 바이너리 매직 값으로 시작하는지 확인합니다.
+func isBinaryTableFile(file *os.File) bool {
+	magic := make([]byte, len(binaryMagic))
+	n, _ := file.ReadAt(magic, 0)
+	return n == len(magic) && string(magic) == binaryMagic
+}
+
+// sealPage는 페이지 끝에 체크섬을 기록합니다.
+func sealPage(page []byte) {
+	end := len(page) - pageChecksumSize
+	binary.BigEndian.PutUint32(page[end:], crc32.ChecksumIEEE(page[:end]))
+}
+
+// pageIntact는 페이지 체크섬이 맞는지 확인합니다.
+func pageIntact(page []byte) bool {
+	end := len(page) - pageChecksumSize
+	return binary.BigEndian.Uint32(page[end:]) == crc32.ChecksumIEEE(page[:end])
+}
+
+// encodeHeaderPage는 테이블 구조를 담은 헤더 페이지를 만듭니다.
+func encodeHeaderPage(tableData *TableData, tableName string) ([]byte, error) {
+	header := formatTableHeader(tableData, tableName)
+	if headerPagePrefix+len(header) > binaryPageSize-pageChecksumSize {
+		return nil, fmt.Errorf("table header does not fit in a %d byte page", binaryPageSize)
+	}
+
+	page := make([]byte, binaryPageSize)
+	copy(page, binaryMagic)
+	binary.BigEndian.PutUint16(page[8:], binaryVersion)
+	binary.BigEndian.PutUint32(page[10:], binaryPageSize)
+	binary.BigEndian.PutUint32(page[14:], uint32(len(header)))
+	copy(page[headerPagePrefix:], header)
+	sealPage(page)
+	return page, nil
+}
+
+// appendValue는 값을 타입 태그와 함께 buf에 덧붙입니다.
+func appendValue(buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, valueNull), nil
+	case int64:
+		buf = append(buf, valueInt64)
+		return binary.BigEndian.AppendUint64(buf, uint64(v)), nil
+	case float64:
+		buf = append(buf, valueFloat64)
+		return binary.BigEndian.AppendUint64(buf, math.Float64bits(v)), nil
+	case Decimal:
+		buf = append(buf, valueDecimal)
+		sign := byte(0)
+		if v.Unscaled.Sign() < 0 {
+			sign = 1
+		}
+		magnitude := new(big.Int).Abs(v.Unscaled).Bytes()
+		buf = append(buf, sign)
+		buf = binary.AppendUvarint(buf, uint64(len(magnitude)))
+		return append(buf, magnitude...), nil
+	case string:
+		buf = append(buf, valueText)
+		buf = binary.AppendUvarint(buf, uint64(len(v)))
+		return append(buf, v...), nil
+	case bool:
+		if v {
+			return append(buf, valueBool, 1), nil
+		}
+		return append(buf, valueBool, 0), nil
+	case Date:
+		days := time.Date(v.Year, v.Month, v.Day, 0, 0, 0, 0, time.UTC).Unix() / 86400
+		buf = append(buf, valueDate)
+		return binary.AppendVarint(buf, days), nil
+	case time.Time:
+		buf = append(buf, valueTime)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(v.Unix()))
+		return binary.BigEndian.AppendUint32(buf, uint32(v.Nanosecond())), nil
+	case []byte:
+		buf = append(buf, valueBlob)
+		buf = binary.AppendUvarint(buf, uint64(len(v)))
+		return append(buf, v...), nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", value)
+}
+
+// decodeValue는 buf 앞부분의 값을 col 타입으로 읽고, 읽은 바이트 수를 반환합니다.
+func decodeValue(buf []byte, col table.Column) (interface{}, int, error) {
+	if len(buf) == 0 {
+		return nil, 0, fmt.Errorf("truncated value for column '%s'", col.Name)
+	}
+
+	tag, body := buf[0], buf[1:]
+	if tag == valueNull {
+		return nil, 1, nil
+	}
+
+	// 태그는 열 타입과 일치해야 합니다.
+	var expected byte
+	switch col.Type {
+	case table.CT_integer:
+		expected = valueInt64
+	case table.CT_float, table.CT_number:
+		expected = valueFloat64
+	case table.CT_decimal:
+		expected = valueDecimal
+	case table.CT_text, table.CT_json:
+		expected = valueText
+	case table.CT_bool:
+		expected = valueBool
+	case table.CT_date:
+		expected = valueDate
+	case table.CT_timestamp:
+		expected = valueTime
+	case table.CT_blob:
+		expected = valueBlob
+	}
+	if tag != expected {
+		return nil, 0, fmt.Errorf("unexpected value tag %d for column '%s'", tag, col.Name)
+	}
+
+	switch tag {
+	case valueInt64, valueFloat64:
+		if len(body) < 8 {
+			return nil, 0, fmt.Errorf("truncated value for column '%s'", col.Name)
+		}
+		bits := binary.BigEndian.Uint64(body)
+		if tag == valueInt64 {
+			return int64(bits), 9, nil
+		}
+		return math.Float64frombits(bits), 9, nil
+
+	case valueDecimal:
+		if len(body) < 1 {
+			return nil, 0, fmt.Errorf("truncated value for column '%s'", col.Name)
+		}
+		length, n := binary.Uvarint(body[1:])
+		if n <= 0 || uint64(len(body)-1-n) < length {
+			return nil, 0, fmt.Errorf("truncated value for column '%s'", col.Name)
+		}
+		start := 1 + n
+		unscaled := new(big.Int).SetBytes(body[start : start+int(length)])
+		if body[0] == 1 {
+			unscaled.Neg(unscaled)
+		}
+		return Decimal{Unscaled: unscaled, Scale: col.Scale}, 1 + start + int(length), nil
+
+	case valueBool:
+		if len(body) < 1 || body[0] > 1 {
+			return nil, 0, fmt.Errorf("invalid boolean value for column '%s'", col.Name)
+		}
+		return body[0] == 1, 2, nil
+
+	case valueDate:
+		days, n := binary.Varint(body)
+		if n <= 0 {
+			return nil, 0, fmt.Errorf("truncated value for column '%s'", col.Name)
+		}
+		t := unixEpochDate.AddDate(0, 0, int(days))
+		return Date{Year: t.Year(), Month: t.Month(), Day: t.Day()}, 1 + n, nil
+
+	case valueTime:
+		if len(body) < 12 {
+			return nil, 0, fmt.Errorf("truncated value for column '%s'", col.Name)
+		}
+		sec := int64(binary.BigEndian.Uint64(body))
+		nsec := int64(binary.BigEndian.Uint32(body[8:]))
+		return time.Unix(sec, nsec).UTC(), 13, nil
+
+	case valueBlob:
+		length, n := binary.Uvarint(body)
+		if n <= 0 || uint64(len(body)-n) < length {
+			return nil, 0, fmt.Errorf("truncated value for column '%s'", col.Name)
+		}
+		return append([]byte{}, body[n:n+int(length)]...), 1 + n + int(length), nil
+
+	default: // valueText
+		length, n := binary.Uvarint(body)
+		if n <= 0 || uint64(len(body)-n) < length {
+			return nil, 0, fmt.Errorf("truncated value for column '%s'", col.Name)
+		}
+		return string(body[n : n+int(length)]), 1 + n + int(length), nil
+	}
+}
+
+// encodeRecord는 레코드를 바이너리 레코드로 변환합니다.
+// 데이터 레코드는 열 순서대로 모든 값을, 삭제 표시는 키 열 순서대로 키 값만 담고, 끝에 레코드 체크섬을 붙입니다.
+func encodeRecord(columns []table.Column, rec tableRecord) ([]byte, error) {
+	var buf []byte
+	var err error
+	if rec.tombstone {
+		buf = []byte{recordTombstone}
+		for _, col := range keyColumns(columns) {
+			buf, err = appendValue(buf, rec.row.Data[col.Name])
+			if err != nil {
+				break
+			}
+		}
+	} else {
+		buf = []byte{recordData}
+		for _, col := range columns {
+			buf, err = appendValue(buf, rec.row.Data[col.Name])
+			if err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf)), nil
+}
+
+// decodeRecord는 바이너리 레코드를 레코드로 변환합니다.
+func decodeRecord(buf []byte, columns []table.Column) (tableRecord, error) {
+	var rec tableRecord
+	if len(buf) <= recordChecksumSize {
+		return rec, fmt.Errorf("empty record")
+	}
+	end := len(buf) - recordChecksumSize
+	if binary.BigEndian.Uint32(buf[end:]) != crc32.ChecksumIEEE(buf[:end]) {
+		return rec, fmt.Errorf("row checksum mismatch")
+	}
+	buf = buf[:end]
+
+	if buf[0] == recordTombstone {
+		keyCols := keyColumns(columns)
+		if len(keyCols) == 0 {
+			return rec, fmt.Errorf("cannot find key column")
+		}
+		row := Row{Data: make(map[string]interface{})}
+		pos := 1
+		for _, col := range keyCols {
+			value, n, err := decodeValue(buf[pos:], col)
+			if err != nil {
+				return rec, err
+			}
+			pos += n
+			row.Data[col.Name] = value
+		}
+		if pos != len(buf) {
+			return rec, fmt.Errorf("unexpected trailing bytes in record")
+		}
+		return newTombstoneRecord(columns, row), nil
+	}
+	if buf[0] != recordData {
+		return rec, fmt.Errorf("unknown record kind %d", buf[0])
+	}
+
+	row := Row{Data: make(map[string]interface{})}
+	pos := 1
+	for _, col := range columns {
+		value, n, err := decodeValue(buf[pos:], col)
+		if err != nil {
+			return rec, err
+		}
+		pos += n
+		row.Data[col.Name] = value
+	}
+	row.Key, _ = rowKey(columns, row.Data)
+	if pos != len(buf) {
+		return rec, fmt.Errorf("unexpected trailing bytes in record")
+	}
+	return newDataRecord(row), nil
+}
+
+// dataPages는 레코드 묶음을 담은 데이터 페이지들입니다.
+type dataPages struct {
+	bytes   []byte
+	payload int64  // 레코드가 차지하는 바이트 수
+	sum     uint32 // 페이지들까지 포함한 파일 내용 체크섬
+}
+
+// encodeDataPages는 레코드들을 데이터 페이지에 차례로 채웁니다.
+// 모든 페이지에 lsn과 그 페이지 앞까지의 파일 체크섬을 기록하고, 마지막 페이지를
+// 묶음의 확정 페이지로 표시합니다. sum은 페이지들 앞까지의 파일 내용 체크섬입니다.
+// 레코드가 없어도 Lsn을 기록하기 위해 빈 페이지 하나를 만듭니다.
+func encodeDataPages(columns []table.Column, records []tableRecord, lsn int64, sum uint32) (dataPages, error) {
+	out := dataPages{sum: sum}
+	page := newDataPage(lsn)
+	slots, used := 0, 0
+	capacity := binaryPageSize - pageChecksumSize - dataPageHeaderSize
+
+	flush := func(flags byte) {
+		page[1] = flags
+		binary.BigEndian.PutUint16(page[2:], uint16(slots))
+		binary.BigEndian.PutUint32(page[12:], out.sum)
+		sealPage(page)
+		out.sum = crc32.Update(out.sum, crc32.IEEETable, page)
+		out.bytes = append(out.bytes, page...)
+	}
+
+	for _, rec := range records {
+		encoded, err := encodeRecord(columns, rec)
+		if err != nil {
+			return dataPages{}, err
+		}
+		if len(encoded)+slotSize > capacity {
+			return dataPages{}, fmt.Errorf("row '%s' does not fit in a %d byte page", rec.key, binaryPageSize)
+		}
+
+		// 현재 페이지에 자리가 없으면 다음 페이지로
+		if (slots+1)*slotSize+used+len(encoded) > capacity {
+			flush(0)
+			page = newDataPage(lsn)
+			slots, used = 0, 0
+		}
+
+		used += len(encoded)
+		offset := binaryPageSize - pageChecksumSize - used
+		copy(page[offset:], encoded)
+		slot := dataPageHeaderSize + slots*slotSize
+		binary.BigEndian.PutUint16(page[slot:], uint16(offset))
+		binary.BigEndian.PutUint16(page[slot+2:], uint16(len(encoded)))
+		slots++
+		out.payload += int64(len(encoded))
+	}
+
+	flush(pageFlagCommit)
+	return out, nil
+}
+
+// newDataPage는 빈 데이터 페이지를 만듭니다.
+func newDataPage(lsn int64) []byte {
+	page := make([]byte, binaryPageSize)
+	page[0] = pageTypeData
+	binary.BigEndian.PutUint64(page[4:], uint64(lsn))
+	return page
+}
+
+// writeBinaryTableFile은 테이블 데이터를 바이너리 TFF 형식으로 file에 기록합니다.
+func writeBinaryTableFile(file *os.File, tableData *TableData, tableName string) error {
+	header, err := encodeHeaderPage(tableData, tableName)
+	if err != nil {
+		return err
+	}
+
+	records := make([]tableRecord, len(tableData.Rows))
+	for i, row := range tableData.Rows {
+		records[i] = newDataRecord(row)
+	}
+	pages, err := encodeDataPages(tableData.Columns, records, tableData.Lsn, crc32.ChecksumIEEE(header))
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(header); err != nil {
+		return err
+	}
+	if _, err := file.Write(pages.bytes); err != nil {
+		return err
+	}
+	tableData.storage.payload = pages.payload
+	tableData.storage.checksum = pages.sum
+	return nil
+}
+
+// appendBinaryRecords는 레코드 묶음을 바이너리 테이블 파일에 기록합니다.
+// 묶음이 마지막 데이터 페이지의 남은 자리에 모두 들어가면 그 페이지를 다시 쓰고,
+// 들어가지 않으면 새 데이터 페이지로 파일 끝에 덧붙입니다.
+func appendBinaryRecords(tableData *TableData, tableName string, dbInfo dbinfo.DBInfo, records []tableRecord, lsn int64) error {
+	path := tableFilePath(tableName, dbInfo)
+	if filled, err := fillLastPage(tableData, path, records, lsn); err != nil || filled {
+		return err
+	}
+
+	pages, err := encodeDataPages(tableData.Columns, records, lsn, tableData.storage.checksum)
+	if err != nil {
+		return err
+	}
+
+	size, err := writeAtCommitted(path, tableData.storage.committedSize, pages.bytes)
+	if err != nil {
+		return err
+	}
+	tableData.storage.committedSize = size
+	tableData.storage.checksum = pages.sum
+	tableData.storage.payload += pages.payload
+	return nil
+}
+
+// fillLastPage는 레코드 묶음이 마지막 확정 페이지의 남은 자리에 모두 들어가면 레코드를 더한
+// 페이지를 그 자리에 다시 쓰고 true를 반환합니다. 페이지의 파일 체크섬은 그대로이고 Lsn만 바뀝니다.
+// 다시 쓰는 도중 중단되면 이미 확정된 레코드까지 잃으므로, 먼저 이전 페이지를 보관본으로 기록해 두고
+// Startup이 restorePageImages로 되돌립니다.
+func fillLastPage(tableData *TableData, path string, records []tableRecord, lsn int64) (bool, error) {
+	st := &tableData.storage
+	offset := st.committedSize - binaryPageSize
+	if offset < binaryPageSize {
+		return false, nil // 데이터 페이지가 없음
+	}
+
+	encoded := make([][]byte, len(records))
+	need := 0
+	for i, rec := range records {
+		buf, err := encodeRecord(tableData.Columns, rec)
+		if err != nil {
+			return false, err
+		}
+		encoded[i] = buf
+		need += slotSize + len(buf)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	page := make([]byte, binaryPageSize)
+	if _, err := file.ReadAt(page, offset); err != nil {
+		return false, err
+	}
+	if !pageIntact(page) || page[0] != pageTypeData || page[1]&pageFlagCommit == 0 {
+		return false, nil
+	}
+	slots := int(binary.BigEndian.Uint16(page[2:]))
+	low := binaryPageSize - pageChecksumSize // 가장 앞에 놓인 레코드의 위치
+	for i := 0; i < slots; i++ {
+		if at := int(binary.BigEndian.Uint16(page[dataPageHeaderSize+i*slotSize:])); at < low {
+			low = at
+		}
+	}
+	if dataPageHeaderSize+slots*slotSize+need > low {
+		return false, nil
+	}
+
+	if err := writePageImage(path, offset, page); err != nil {
+		return false, err
+	}
+
+	var payload int64
+	for _, buf := range encoded {
+		low -= len(buf)
+		copy(page[low:], buf)
+		slot := dataPageHeaderSize + slots*slotSize
+		binary.BigEndian.PutUint16(page[slot:], uint16(low))
+		binary.BigEndian.PutUint16(page[slot+2:], uint16(len(buf)))
+		slots++
+		payload += int64(len(buf))
+	}
+	binary.BigEndian.PutUint16(page[2:], uint16(slots))
+	binary.BigEndian.PutUint64(page[4:], uint64(lsn))
+	sealPage(page)
+
+	if err := file.Truncate(st.committedSize); err != nil {
+		return false, err
+	}
+	if _, err := file.WriteAt(page, offset); err != nil {
+		return false, err
+	}
+	if err := file.Sync(); err != nil {
+		return false, err
+	}
+	st.checksum = crc32.Update(binary.BigEndian.Uint32(page[12:]), crc32.IEEETable, page)
+	st.payload += payload
+	return true, nil
+}
+
+// pageImageSuffix는 제자리에서 다시 쓰는 페이지의 이전 내용을 담는 보관본 파일에 붙는 확장자입니다.
+//
+//	보관본: 페이지 위치(8) | 이전 페이지 | CRC32(4)
+//
+// 보관본은 테이블마다 하나이며 다음 페이지를 다시 쓸 때 덮어쓰고, Startup이 확인한 뒤 지웁니다.
+const pageImageSuffix = ".page"
+
+// writePageImage는 테이블 파일 path의 offset 위치에 있는 page를 보관본으로 기록합니다.
+func writePageImage(path string, offset int64, page []byte) error {
+	image := make([]byte, 8, 8+len(page)+4)
+	binary.BigEndian.PutUint64(image, uint64(offset))
+	image = append(image, page...)
+	image = binary.BigEndian.AppendUint32(image, crc32.ChecksumIEEE(image))
+
+	imagePath := path + pageImageSuffix
+	_, statErr := os.Stat(imagePath)
+	file, err := os.OpenFile(imagePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Write(image); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	if os.IsNotExist(statErr) {
+		return syncDir(filepath.Dir(path))
+	}
+	return nil
+}
+
+// restorePageImages는 dir의 페이지 보관본을 확인하고 모두 지웁니다.
+// 보관본의 위치에 있는 페이지가 손상되어 있으면 페이지를 다시 쓰다 중단된 것이므로 이전 내용으로 되돌리고
+// 그 뒤의 완료되지 않은 페이지를 잘라냅니다. 중단된 명령은 WAL 재실행으로 다시 기록됩니다.
+// 테이블 파일이 그 뒤 다시 쓰였으면 페이지 앞까지의 파일 체크섬이 맞지 않으므로 되돌리지 않습니다.
+// 되돌린 테이블 파일 경로를 반환합니다.
+func restorePageImages(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var restored []string
+	removed := false
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), pageImageSuffix) {
+			continue
+		}
+		imagePath := filepath.Join(dir, entry.Name())
+		path := strings.TrimSuffix(imagePath, pageImageSuffix)
+		ok, err := restorePageImage(path, imagePath)
+		if err != nil {
+			return restored, fmt.Errorf("failed to restore page of '%s': %v", path, err)
+		}
+		if ok {
+			restored = append(restored, path)
+		}
+		if err := os.Remove(imagePath); err != nil {
+			return restored, err
+		}
+		removed = true
+	}
+
+	if removed {
+		if err := syncDir(dir); err != nil {
+			return restored, err
+		}
+	}
+	return restored, nil
+}
+
+// restorePageImage는 보관본 하나를 확인하고, 필요하면 테이블 파일의 페이지를 되돌립니다.
+func restorePageImage(path, imagePath string) (bool, error) {
+	image, err := os.ReadFile(imagePath)
+	if err != nil {
+		return false, err
+	}
+	// 보관본 자체가 온전하지 않으면 페이지를 다시 쓰기 전에 중단된 것입니다.
+	end := len(image) - 4
+	if len(image) != 8+binaryPageSize+4 || binary.BigEndian.Uint32(image[end:]) != crc32.ChecksumIEEE(image[:end]) {
+		return false, nil
+	}
+	offset := int64(binary.BigEndian.Uint64(image))
+	old := image[8:end]
+
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	page := make([]byte, binaryPageSize)
+	if n, _ := file.ReadAt(page, offset); n == len(page) && pageIntact(page) {
+		return false, nil // 쓰기가 끝났거나 시작하지 않았음
+	}
+	sum := crc32.NewIEEE()
+	if _, err := io.Copy(sum, io.NewSectionReader(file, 0, offset)); err != nil {
+		return false, err
+	}
+	if sum.Sum32() != binary.BigEndian.Uint32(old[12:]) {
+		return false, nil
+	}
+
+	if _, err := file.WriteAt(old, offset); err != nil {
+		return false, err
+	}
+	if err := file.Truncate(offset + binaryPageSize); err != nil {
+		return false, err
+	}
+	return true, file.Sync()
+}
+
+// binaryReader는 바이너리 TFF 파일을 페이지 단위로 읽습니다.
+type binaryReader struct {
+	reader   *bufio.Reader
+	path     string
+	cols     []table.Column
+	chks     []table.Check
+	lsn      int64
+	offset   int64
+	autoNext int64  // 헤더에 기록된 AUTO_INCREMENT 카운터
+	sum      uint32 // 지금까지 읽은 페이지들의 파일 체크섬
+	st       storageState
+
+	committed      bool          // 확정 페이지를 읽었는지 여부 (이후는 덧붙여진 영역)
+	pending        []tableRecord // 확정 페이지를 기다리는 레코드 묶음
+	pendingPayload int64
+	pendingErr     error         // pending 묶음에서 발견한 첫 번째 손상
+	damageFn       damageHandler // 설정되면 손상을 오류 대신 보고하고 계속 읽음
+	ready          []tableRecord
+	done           bool
+}
+
+// newBinaryReader는 헤더 페이지를 읽고 열 정의를 구성합니다.
+// path는 오류 메시지에 표시할 파일 경로입니다.
+func newBinaryReader(r io.Reader, path string) (*binaryReader, error) {
+	br := &binaryReader{
+		reader: bufio.NewReaderSize(r, binaryPageSize),
+		path:   path,
+		st:     storageState{format: formatBinary},
+	}
+
+	page := make([]byte, binaryPageSize)
+	if _, err := io.ReadFull(br.reader, page); err != nil {
+		return nil, pageCorruption(path, 0, fmt.Sprintf("failed to read table header page: %v", err))
+	}
+	if !pageIntact(page) || string(page[:len(binaryMagic)]) != binaryMagic {
+		return nil, pageCorruption(path, 0, "page checksum mismatch")
+	}
+	if version := binary.BigEndian.Uint16(page[8:]); version != binaryVersion {
+		return nil, fmt.Errorf("unsupported table format version %d", version)
+	}
+	if size := binary.BigEndian.Uint32(page[10:]); size != binaryPageSize {
+		return nil, fmt.Errorf("unsupported page size %d", size)
+	}
+	length := int(binary.BigEndian.Uint32(page[14:]))
+	if headerPagePrefix+length > binaryPageSize-pageChecksumSize {
+		return nil, pageCorruption(path, 0, "invalid table header length")
+	}
+
+	// 헤더(테이블 구조) 파싱
+	var headerTokens []parsers.Tff_token
+	header := string(page[headerPagePrefix:headerPagePrefix+length]) + "DATA_SECTION :\n"
+	if parsers.ParseHeader(header, &headerTokens) != 0 {
+		return nil, pageCorruption(path, 0, "failed to parse table header")
+	}
+	br.cols = columnsFromHeader(headerTokens)
+	br.chks = checksFromHeader(headerTokens)
+	br.autoNext = autoIncrementFromHeader(headerTokens)
+	br.offset = binaryPageSize
+	br.sum = crc32.ChecksumIEEE(page)
+	br.st.committedSize = br.offset
+	br.st.checksum = br.sum
+	return br, nil
+}
+
+func (br *binaryReader) columns() []table.Column { return br.cols }
+func (br *binaryReader) checks() []table.Check   { return br.chks }
+func (br *binaryReader) lastLsn() int64          { return br.lsn }
+func (br *binaryReader) storage() storageState   { return br.st }
+func (br *binaryReader) autoIncrement() int64    { return br.autoNext }
+
+func (br *binaryReader) onDamage(fn damageHandler) { br.damageFn = fn }
+
+// next는 확정된 다음 레코드를 반환합니다. 더 이상 레코드가 없으면 ok가 false입니다.
+func (br *binaryReader) next() (tableRecord, bool, error) {
+	for len(br.ready) == 0 {
+		if br.done {
+			return tableRecord{}, false, nil
+		}
+		if err := br.readPage(); err != nil {
+			return tableRecord{}, false, err
+		}
+	}
+
+	rec := br.ready[0]
+	br.ready = br.ready[1:]
+	return rec, true, nil
+}
+
+// readPage는 데이터 페이지 하나를 읽어 레코드를 pending 또는 ready에 반영합니다.
+// 끝까지 기록되지 않은 페이지에서 읽기를 마치고, 확정 페이지 없이 끝난 묶음은 버립니다.
+func (br *binaryReader) readPage() error {
+	page := make([]byte, binaryPageSize)
+	if _, err := io.ReadFull(br.reader, page); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			br.done = true
+			return nil
+		}
+		return err
+	}
+
+	pageNo := br.offset / binaryPageSize
+	fileSum := br.sum
+	br.offset += binaryPageSize
+	br.sum = crc32.Update(br.sum, crc32.IEEETable, page)
+
+	if !pageIntact(page) || page[0] != pageTypeData {
+		return br.damaged(pageNo, "page checksum mismatch", page)
+	}
+
+	var records []tableRecord
+	var payload int64
+	slots := int(binary.BigEndian.Uint16(page[2:]))
+	if dataPageHeaderSize+slots*slotSize > binaryPageSize-pageChecksumSize {
+		return br.damaged(pageNo, "invalid row directory", page)
+	}
+	for i := 0; i < slots; i++ {
+		slot := dataPageHeaderSize + i*slotSize
+		offset := int(binary.BigEndian.Uint16(page[slot:]))
+		length := int(binary.BigEndian.Uint16(page[slot+2:]))
+		if offset+length > binaryPageSize-pageChecksumSize {
+			return br.damaged(pageNo, "invalid row directory", page)
+		}
+		rec, err := decodeRecord(page[offset:offset+length], br.cols)
+		if err != nil {
+			damageErr := br.damaged(pageNo, fmt.Sprintf("row %d: %v", i, err), page[offset:offset+length])
+			if br.damageFn == nil {
+				return damageErr
+			}
+			continue // 검사 중에는 손상된 레코드만 건너뜁니다.
+		}
+		records = append(records, rec)
+		payload += int64(length)
+	}
+	br.pending = append(br.pending, records...)
+	br.pendingPayload += payload
+
+	if page[1]&pageFlagCommit == 0 {
+		if binary.BigEndian.Uint32(page[12:]) != fileSum {
+			return br.damaged(pageNo, fileChecksumMismatch, nil)
+		}
+		return nil
+	}
+
+	// 확정 페이지 자체는 온전하므로 앞선 묶음은 확정된 것입니다. 그 안의 손상은 잘린 기록이 아닙니다.
+	if br.pendingErr != nil {
+		return br.pendingErr
+	}
+	if binary.BigEndian.Uint32(page[12:]) != fileSum {
+		err := pageCorruption(br.path, pageNo, fileChecksumMismatch)
+		if br.damageFn == nil {
+			return err
+		}
+		br.damageFn(err, "")
+	}
+
+	br.committed = true
+	br.ready = append(br.ready, br.pending...)
+	br.pending = br.pending[:0]
+	br.st.payload += br.pendingPayload
+	br.pendingPayload = 0
+	br.lsn = int64(binary.BigEndian.Uint64(page[4:]))
+	br.st.committedSize = br.offset
+	br.st.checksum = br.sum
+	return nil
+}
+
+// damaged는 페이지의 손상을 처리합니다.
+// 첫 확정 페이지까지는 파일 교체로 원자적으로 기록되므로 손상은 바로 오류입니다.
+// (확정 페이지를 제자리에서 다시 쓰다 중단된 경우는 Startup이 읽기 전에 되돌립니다.)
+// 덧붙여진 영역의 손상은 뒤따르는 온전한 확정 페이지가 있을 때 오류가 되며,
+// 그 전에 파일이 끝나면 완료되지 않은 꼬리로 보고 버립니다.
+// 손상 처리기가 설정되어 있으면 손상된 내용(raw)을 Raw-> [16진수] 줄로 보고하고 건너뜁니다.
+func (br *binaryReader) damaged(pageNo int64, reason string, raw []byte) error {
+	err := pageCorruption(br.path, pageNo, reason)
+	if br.damageFn != nil {
+		quarantined := ""
+		if raw != nil {
+			quarantined = "Raw-> " + hex.EncodeToString(raw)
+		}
+		br.damageFn(err, quarantined)
+		return nil
+	}
+	if !br.committed {
+		return err
+	}
+	if br.pendingErr == nil {
+		br.pendingErr = err
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package dbcontroller
+
+import (
+	"os"
+	"sedb/modules/table"
+	"strconv"
+	"testing"
+)
+
+// binaryTestColumns는 모든 열 타입을 담은 테이블 구조입니다.
+var binaryTestColumns = []table.Column{
+	{Name: "id", Type: table.CT_text, Is_key: true, Not_null: true},
+	{Name: "i", Type: table.CT_integer},
+	{Name: "f", Type: table.CT_float},
+	{Name: "m", Type: table.CT_decimal, Scale: 2},
+	{Name: "b", Type: table.CT_bool},
+	{Name: "d", Type: table.CT_date},
+	{Name: "ts", Type: table.CT_timestamp},
+	{Name: "bl", Type: table.CT_blob},
+	{Name: "j", Type: table.CT_json},
+}
+
+// testRow는 열 순서대로 주어진 값 표기를 열 타입으로 변환한 행을 만듭니다. "NULL"은 NULL입니다.
+func testRow(t *testing.T, columns []table.Column, raws ...string) Row {
+	t.Helper()
+	row := Row{Data: make(map[string]interface{})}
+	for i, col := range columns {
+		if raws[i] == "NULL" {
+			row.Data[col.Name] = nil
+			continue
+		}
+		value, err := convertValue(col, raws[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		row.Data[col.Name] = value
+	}
+	row.Key, _ = rowKey(columns, row.Data)
+	return row
+}
+
+// sameRow는 두 행의 모든 열 값이 같은 표기인지 확인합니다.
+func sameRow(columns []table.Column, a, b Row) bool {
+	for _, col := range columns {
+		va, vb := a.Data[col.Name], b.Data[col.Name]
+		if (va == nil) != (vb == nil) || formatValue(va) != formatValue(vb) {
+			return false
+		}
+	}
+	return a.Key == b.Key
+}
+
+func TestBinaryRecordRoundTrip(t *testing.T) {
+	rows := []Row{
+		testRow(t, binaryTestColumns, "a", "-9223372036854775808", "0.1", "-12.34", "true",
+			"1969-12-31", "2024-03-01T09:00:00.123456789+09:00", "0xdeadbeef", `{"a": [1, 2]}`),
+		testRow(t, binaryTestColumns, "b", "NULL", "NULL", "NULL", "false", "NULL", "NULL", "0x", "NULL"),
+	}
+	for _, row := range rows {
+		buf, err := encodeRecord(binaryTestColumns, newDataRecord(row))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec, err := decodeRecord(buf, binaryTestColumns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.tombstone || !sameRow(binaryTestColumns, row, rec.row) {
+			t.Errorf("got %v, want %v", rec.row.Data, row.Data)
+		}
+
+		// 레코드 체크섬이 바이트 하나의 변경도 찾아냅니다.
+		buf[2] ^= 0x01
+		if _, err := decodeRecord(buf, binaryTestColumns); err == nil {
+			t.Error("corrupted record was accepted")
+		}
+	}
+
+	tomb := newTombstoneRecord(binaryTestColumns, rows[0])
+	buf, err := encodeRecord(binaryTestColumns, tomb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := decodeRecord(buf, binaryTestColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.tombstone || rec.key != rows[0].Key {
+		t.Errorf("got tombstone=%v key=%q, want tombstone for %q", rec.tombstone, rec.key, rows[0].Key)
+	}
+}
+
+func TestConvertTableRoundTrip(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (text id NOTNULL KEY, integer i, float f, decimal(2) m, bool b, date d, timestamp ts, blob bl, json j);`)
+	mustExec(t, info, `add t ("a", 1, 1.5, 3.25, true, "2024-01-31", "2024-03-01T09:00:00+09:00", 0xdeadbeef, "{\"k\": 1}");`)
+	mustExec(t, info, `add t ("b", NULL, NULL, -0.01, false, NULL, NULL, NULL, NULL);`)
+	mustExec(t, info, `add t ("c", 3, 2.5, 100, true, "2000-02-29", "1999-12-31T23:59:59.5Z", 0x00ff, "[]");`)
+	mustExec(t, info, `delete t "c";`)
+	before, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(format tableFormat) {
+		t.Helper()
+		after, err := loadTableData("t", info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if after.storage.format != format {
+			t.Fatalf("format = %v, want %v", after.storage.format, format)
+		}
+		if len(after.Rows) != len(before.Rows) {
+			t.Fatalf("got %d rows, want %d", len(after.Rows), len(before.Rows))
+		}
+		for i := range before.Rows {
+			if !sameRow(before.Columns, before.Rows[i], after.Rows[i]) {
+				t.Errorf("row %d = %v, want %v", i, after.Rows[i].Data, before.Rows[i].Data)
+			}
+		}
+	}
+
+	mustExec(t, info, `convert_table t binary;`)
+	check(formatBinary)
+	file, err := os.Open(tableFilePath("t", info))
+	if err != nil {
+		t.Fatal(err)
+	}
+	isBinary := isBinaryTableFile(file)
+	file.Close()
+	if !isBinary {
+		t.Error("converted file is not a binary table file")
+	}
+
+	// 바이너리 형식에서도 수정과 삭제가 레코드로 덧붙습니다.
+	mustExec(t, info, `add t ("c", 3, 2.5, 100, true, "2000-02-29", "1999-12-31T23:59:59.5Z", 0x00ff, "[]");`)
+	mustExec(t, info, `delete t "c";`)
+	check(formatBinary)
+
+	mustExec(t, info, `convert_table t text;`)
+	check(formatText)
+}
+
+func TestBinaryTornPageIsIgnored(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, text n);`)
+	mustExec(t, info, `add t (1, "a");`)
+	mustExec(t, info, `convert_table t binary;`)
+	mustExec(t, info, `add t (2, "b");`)
+
+	// 페이지를 쓰는 도중에 중단되어 끝에 완성되지 않은 페이지가 남은 경우
+	file, err := os.OpenFile(tableFilePath("t", info), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write(make([]byte, 3000))
+	file.Close()
+
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(tableData.Rows))
+	}
+	mustExec(t, info, `add t (3, "c");`)
+	tableData, err = loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 3 {
+		t.Errorf("got %d rows after append, want 3", len(tableData.Rows))
+	}
+}
+
+func TestBinaryAppendFillsLastPage(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, text n);`)
+	mustExec(t, info, `convert_table t binary;`)
+	for i := 1; i <= 50; i++ {
+		mustExec(t, info, `add t (`+strconv.Itoa(i)+`, "row");`)
+	}
+	mustExec(t, info, `update t 7 (7, "changed");`)
+	mustExec(t, info, `delete t 8;`)
+
+	// 작은 명령들은 새 페이지를 쓰지 않고 마지막 데이터 페이지를 채웁니다.
+	if info, err := os.Stat(tableFilePath("t", info)); err != nil || info.Size() != 2*binaryPageSize {
+		t.Fatalf("file size = %v (err %v), want the header and one data page", info.Size(), err)
+	}
+	resetTableCache()
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 49 {
+		t.Fatalf("got %d rows, want 49", len(tableData.Rows))
+	}
+	for _, row := range tableData.Rows {
+		if row.Key == "8" || row.Key == "7" && row.Data["n"] != "changed" {
+			t.Errorf("row %s = %v", row.Key, row.Data)
+		}
+	}
+}
+
+func TestBinaryInterruptedPageRewriteIsRestored(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, text n);`)
+	mustExec(t, info, `convert_table t binary;`)
+	mustExec(t, info, `add t (1, "a");`)
+	mustExec(t, info, `add t (2, "b");`)
+
+	// 이전 페이지를 보관한 뒤 페이지를 다시 쓰는 도중 중단된 경우
+	path := tableFilePath("t", info)
+	page := make([]byte, binaryPageSize)
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.ReadAt(page, binaryPageSize); err != nil {
+		t.Fatal(err)
+	}
+	if err := writePageImage(path, binaryPageSize, page); err != nil {
+		t.Fatal(err)
+	}
+	file.WriteAt(make([]byte, 100), binaryPageSize+10)
+	file.Close()
+
+	if Startup(info) != 0 {
+		t.Fatal("startup failed")
+	}
+	if _, err := os.Stat(path + pageImageSuffix); !os.IsNotExist(err) {
+		t.Errorf("page image was not removed (stat err %v)", err)
+	}
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(tableData.Rows))
+	}
+	mustExec(t, info, `add t (3, "c");`)
+
+	// 페이지가 온전하면 보관본은 지우기만 합니다.
+	if err := writePageImage(path, binaryPageSize, page); err != nil {
+		t.Fatal(err)
+	}
+	if Startup(info) != 0 {
+		t.Fatal("startup failed")
+	}
+	if tableData, err := loadTableData("t", info); err != nil || len(tableData.Rows) != 3 {
+		t.Errorf("rows after startup: %v (err %v), want 3", tableData, err)
+	}
+}
@@ -0,0 +1,296 @@
+package dbcontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	dbinfo "sedb/modules/db_info"
+	"slices"
+	"sort"
+	"time"
+)
+
+// catalog는 데이터베이스의 테이블 목록입니다. [DB이름]/catalog.json에 기록합니다.
+// 테이블을 만들거나 구조, 이름, 형식을 바꾸는 명령은 테이블 파일을 기록한 뒤 카탈로그를 원자적으로 교체하며,
+// 그 사이에 중단되면 시작 시 reconcileCatalog가 테이블 파일에 맞춰 고칩니다.
+// 행을 바꾸는 명령(ADD, UPDATE, DELETE, TRUNCATE)은 카탈로그를 고치지 않습니다.
+type catalog struct {
+	Tables []catalogEntry `json:"tables"` // 이름 순서
+}
+
+// catalogEntry는 테이블 하나의 카탈로그 정보입니다.
+type catalogEntry struct {
+	Name    string         `json:"name"`
+	Schema  []string       `json:"schema"`  // 열 정의와 CHECK 제약 (TFF 헤더 표기, AUTO_INCREMENT 카운터 제외)
+	Version int            `json:"version"` // 구조 버전: 생성 시 1, 구조가 바뀔 때마다 1 증가
+	Options catalogOptions `json:"options"`
+	Created string         `json:"created"` // 생성 시각 (RFC 3339, UTC)
+	Altered string         `json:"altered"` // 마지막으로 구조나 이름이 바뀐 시각
+
+	// Unreadable은 테이블 파일을 읽을 수 없을 때의 오류입니다. 읽을 수 있으면 비어 있습니다.
+	// 읽을 수 없는 파일도 카탈로그에 남겨 같은 이름의 테이블을 만들거나 그 이름으로 바꾸지 못하게 합니다.
+	Unreadable string `json:"unreadable,omitempty"`
+}
+
+// catalogOptions는 테이블 구조 밖의 저장 옵션입니다.
+type catalogOptions struct {
+	Format string `json:"format"` // 파일 형식: text 또는 binary
+}
+
+// catalogFilePath는 데이터베이스의 카탈로그 파일 경로를 반환합니다.
+func catalogFilePath(dbInfo dbinfo.DBInfo) string {
+	return filepath.Join("./", dbInfo.DbName, "catalog.json")
+}
+
+// catalogTime은 카탈로그에 기록할 현재 시각입니다.
+func catalogTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// tableSchema는 카탈로그에 기록할 테이블 구조입니다.
+func tableSchema(tableData *TableData) []string {
+	schema := make([]string, 0, len(tableData.Columns)+len(tableData.Checks))
+	for _, col := range tableData.Columns {
+		schema = append(schema, formatColumnDefinition(col, 0))
+	}
+	for _, check := range tableData.Checks {
+		schema = append(schema, formatCheckDefinition(check))
+	}
+	return schema
+}
+
+// readCatalog는 카탈로그 파일을 읽습니다. 파일이 없으면 exists는 false입니다.
+func readCatalog(dbInfo dbinfo.DBInfo) (*catalog, bool, error) {
+	content, err := os.ReadFile(catalogFilePath(dbInfo))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &catalog{}, false, nil
+		}
+		return nil, false, err
+	}
+
+	cat := &catalog{}
+	if err := json.Unmarshal(content, cat); err != nil {
+		return nil, true, fmt.Errorf("invalid catalog: %v", err)
+	}
+	return cat, true, nil
+}
+
+// loadCatalog는 카탈로그를 불러옵니다.
+// 카탈로그가 없는 데이터베이스(이전 버전에서 만든 경우 등)는 테이블 파일로부터 만들어 바로 저장하므로,
+// 테이블 파일을 모두 읽는 일은 처음 한 번만 합니다.
+func loadCatalog(dbInfo dbinfo.DBInfo) (*catalog, error) {
+	cat, exists, err := readCatalog(dbInfo)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if _, err := cat.reconcile(dbInfo); err != nil {
+			return nil, err
+		}
+		// 데이터베이스 디렉토리가 아직 없으면 저장할 곳이 없으므로 넘어갑니다.
+		if err := saveCatalog(cat, dbInfo); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to save catalog: %v", err)
+		}
+	}
+	return cat, nil
+}
+
+// saveCatalog는 카탈로그 파일을 원자적으로 교체합니다.
+func saveCatalog(cat *catalog, dbInfo dbinfo.DBInfo) error {
+	return writeFileAtomic(catalogFilePath(dbInfo), func(file *os.File) error {
+		enc := json.NewEncoder(file)
+		enc.SetEscapeHTML(false) // CHECK 조건식의 <, >를 그대로 기록
+		enc.SetIndent("", "  ")
+		return enc.Encode(cat)
+	})
+}
+
+// updateCatalog는 카탈로그를 불러와 change를 적용한 뒤 저장합니다.
+func updateCatalog(dbInfo dbinfo.DBInfo, change func(cat *catalog)) error {
+	cat, err := loadCatalog(dbInfo)
+	if err != nil {
+		return err
+	}
+	change(cat)
+	return saveCatalog(cat, dbInfo)
+}
+
+// catalogPutTable은 테이블의 현재 상태를 카탈로그에 기록합니다.
+func catalogPutTable(tableName string, tableData *TableData, dbInfo dbinfo.DBInfo) error {
+	return updateCatalog(dbInfo, func(cat *catalog) {
+		cat.put(tableName, tableData, time.Now())
+	})
+}
+
+// find는 이름이 name인 테이블의 항목을 반환합니다. 없으면 nil입니다.
+func (cat *catalog) find(name string) *catalogEntry {
+	for i := range cat.Tables {
+		if cat.Tables[i].Name == name {
+			return &cat.Tables[i]
+		}
+	}
+	return nil
+}
+
+// names는 카탈로그의 테이블 이름을 정렬된 순서로 반환합니다.
+func (cat *catalog) names() []string {
+	names := make([]string, len(cat.Tables))
+	for i, entry := range cat.Tables {
+		names[i] = entry.Name
+	}
+	return names
+}
+
+// put은 테이블의 구조와 옵션을 기록합니다. 새 테이블은 버전 1이며, 구조가 바뀌면 버전을 올립니다.
+func (cat *catalog) put(tableName string, tableData *TableData, now time.Time) {
+	schema := tableSchema(tableData)
+	entry := cat.find(tableName)
+	if entry == nil {
+		cat.Tables = append(cat.Tables, catalogEntry{
+			Name:    tableName,
+			Version: 1,
+			Created: catalogTime(now),
+			Altered: catalogTime(now),
+		})
+		cat.sort()
+		entry = cat.find(tableName)
+	} else if !slices.Equal(entry.Schema, schema) {
+		entry.Version++
+		entry.Altered = catalogTime(now)
+	}
+
+	entry.Schema = schema
+	entry.Options = catalogOptions{Format: tableData.storage.format.String()}
+	entry.Unreadable = ""
+}
+
+// putUnreadable은 읽을 수 없는 테이블 파일을 기록합니다. 이미 있는 항목은 마지막으로 읽은 정보를 유지합니다.
+func (cat *catalog) putUnreadable(tableName string, reason string, modified time.Time) {
+	entry := cat.find(tableName)
+	if entry == nil {
+		cat.Tables = append(cat.Tables, catalogEntry{
+			Name:    tableName,
+			Schema:  []string{},
+			Created: catalogTime(modified),
+			Altered: catalogTime(modified),
+		})
+		cat.sort()
+		entry = cat.find(tableName)
+	}
+	entry.Unreadable = reason
+}
+
+// remove는 테이블 항목을 지웁니다.
+func (cat *catalog) remove(name string) {
+	cat.Tables = slices.DeleteFunc(cat.Tables, func(entry catalogEntry) bool {
+		return entry.Name == name
+	})
+}
+
+// rename은 테이블 항목의 이름을 바꿉니다. 생성 시각과 구조 버전은 유지합니다.
+func (cat *catalog) rename(from, to string, now time.Time) {
+	if entry := cat.find(from); entry != nil {
+		entry.Name = to
+		entry.Altered = catalogTime(now)
+		cat.sort()
+	}
+}
+
+func (cat *catalog) sort() {
+	sort.Slice(cat.Tables, func(i, j int) bool {
+		return cat.Tables[i].Name < cat.Tables[j].Name
+	})
+}
+
+// reconcile은 카탈로그를 tables 디렉토리의 테이블 파일에 맞춥니다.
+// 파일이 없는 항목은 지우고, 카탈로그에 없는 파일은 추가하며, 반영되지 않은 변경(구조, 형식)이 있는 항목은 고칩니다.
+// 지운 항목과 구조가 같은 파일이 새로 나타났으면 이름 변경 도중 중단된 것으로 보고 생성 시각과 버전을 이어받습니다.
+// 읽을 수 없는 테이블 파일은 항목에 읽을 수 없음으로 표시합니다. 반환값은 고친 내용의 요약입니다.
+func (cat *catalog) reconcile(dbInfo dbinfo.DBInfo) ([]string, error) {
+	names, err := listTables(dbInfo)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var changes []string
+	var missing []catalogEntry
+	for _, entry := range cat.Tables {
+		if !slices.Contains(names, entry.Name) {
+			missing = append(missing, entry)
+		}
+	}
+	for _, entry := range missing {
+		cat.remove(entry.Name)
+	}
+
+	for _, name := range names {
+		modified := time.Now()
+		if info, err := os.Stat(tableFilePath(name, dbInfo)); err == nil {
+			modified = info.ModTime()
+		}
+
+		entry := cat.find(name)
+		tableData, err := loadTableData(name, dbInfo)
+		if err != nil {
+			if entry == nil || entry.Unreadable != err.Error() {
+				cat.putUnreadable(name, err.Error(), modified)
+				changes = append(changes, fmt.Sprintf("cannot read table '%s': %v", name, err))
+			}
+			continue
+		}
+
+		if entry == nil {
+			schema := tableSchema(tableData)
+			idx := slices.IndexFunc(missing, func(old catalogEntry) bool {
+				return slices.Equal(old.Schema, schema)
+			})
+			if idx >= 0 {
+				renamed := missing[idx]
+				missing = slices.Delete(missing, idx, idx+1)
+				changes = append(changes, fmt.Sprintf("table '%s' was renamed to '%s'", renamed.Name, name))
+				renamed.Name = name
+				renamed.Altered = catalogTime(modified)
+				cat.Tables = append(cat.Tables, renamed)
+				cat.sort()
+			}
+			cat.put(name, tableData, modified)
+			if idx < 0 {
+				changes = append(changes, fmt.Sprintf("added table '%s'", name))
+			}
+			continue
+		}
+
+		if entry.Unreadable != "" || entry.Options.Format != tableData.storage.format.String() ||
+			!slices.Equal(entry.Schema, tableSchema(tableData)) {
+			cat.put(name, tableData, time.Now())
+			changes = append(changes, fmt.Sprintf("updated table '%s'", name))
+		}
+	}
+	for _, entry := range missing {
+		changes = append(changes, fmt.Sprintf("removed table '%s' (file is missing)", entry.Name))
+	}
+	return changes, nil
+}
+
+// reconcileCatalog는 시작 시 카탈로그를 테이블 파일에 맞추고, 바뀐 것이 있으면 저장합니다.
+// 카탈로그 파일을 읽을 수 없으면 테이블 파일로부터 다시 만듭니다.
+func reconcileCatalog(dbInfo dbinfo.DBInfo) ([]string, error) {
+	var changes []string
+	cat, exists, err := readCatalog(dbInfo)
+	if err != nil {
+		changes = append(changes, fmt.Sprintf("rebuilt unreadable catalog (%v)", err))
+		cat, exists = &catalog{}, false
+	}
+
+	fixed, err := cat.reconcile(dbInfo)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, fixed...)
+	if exists && len(changes) == 0 {
+		return nil, nil
+	}
+	return changes, saveCatalog(cat, dbInfo)
+}
@@ -0,0 +1,203 @@
+package dbcontroller
+
+import (
+	"bytes"
+	"os"
+	dbinfo "sedb/modules/db_info"
+	"testing"
+)
+
+// catalogEntryOf는 카탈로그에서 테이블 항목을 찾고, 없으면 테스트를 중단합니다.
+func catalogEntryOf(t *testing.T, info dbinfo.DBInfo, name string) catalogEntry {
+	t.Helper()
+	cat, err := loadCatalog(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := cat.find(name)
+	if entry == nil {
+		t.Fatalf("catalog has no table '%s': %v", name, cat.names())
+	}
+	return *entry
+}
+
+func TestCatalogTracksTables(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table users (integer id NOTNULL KEY, text name);`)
+	entry := catalogEntryOf(t, info, "users")
+	if entry.Version != 1 || entry.Options.Format != "text" || len(entry.Schema) != 2 {
+		t.Errorf("entry = %+v", entry)
+	}
+	created := entry.Created
+
+	// 행을 바꾸는 명령은 카탈로그를 다시 쓰지 않습니다.
+	before, err := os.ReadFile(catalogFilePath(info))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustExec(t, info, `add users (1, "kim");`)
+	mustExec(t, info, `add users (2, "lee");`)
+	mustExec(t, info, `update users 2 (2, "park");`)
+	mustExec(t, info, `delete users 1;`)
+	mustExec(t, info, `truncate users;`)
+	if after, err := os.ReadFile(catalogFilePath(info)); err != nil || !bytes.Equal(after, before) {
+		t.Errorf("row commands changed the catalog (err %v)", err)
+	}
+
+	// 구조가 바뀔 때만 버전이 오르고, 이름을 바꿔도 생성 시각과 버전은 유지됩니다.
+	mustExec(t, info, `alter_table users add_column integer age;`)
+	mustExec(t, info, `convert_table users binary;`)
+	mustExec(t, info, `rename_table users to members;`)
+	entry = catalogEntryOf(t, info, "members")
+	if entry.Version != 2 || entry.Created != created || entry.Options.Format != "binary" || len(entry.Schema) != 3 {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	mustExec(t, info, `drop_table members;`)
+	cat, err := loadCatalog(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cat.Tables) != 0 {
+		t.Errorf("catalog after drop = %v", cat.names())
+	}
+	if CmdExec(`add members (3, "park", 1);`, info) == 0 {
+		t.Error("added a row to a dropped table")
+	}
+}
+
+func TestReconcileCatalog(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table a (integer id NOTNULL KEY, text name);`)
+	mustExec(t, info, `create_table b (text code NOTNULL KEY);`)
+	mustExec(t, info, `add a (1, "kim");`)
+	before := catalogEntryOf(t, info, "a")
+
+	// 이름 변경 도중 중단된 경우: 파일만 새 이름으로 옮겨졌습니다.
+	if err := os.Rename(tableFilePath("a", info), tableFilePath("renamed", info)); err != nil {
+		t.Fatal(err)
+	}
+	// 카탈로그 밖에서 지운 파일
+	if err := os.Remove(tableFilePath("b", info)); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := reconcileCatalog(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Errorf("changes = %v, want a rename and a removal", changes)
+	}
+	cat, err := loadCatalog(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cat.find("a") != nil || cat.find("b") != nil {
+		t.Errorf("catalog still has the missing tables: %v", cat.names())
+	}
+	entry := catalogEntryOf(t, info, "renamed")
+	if entry.Created != before.Created || entry.Version != before.Version {
+		t.Errorf("renamed entry = %+v, want the history of %+v", entry, before)
+	}
+
+	// 바뀐 것이 없으면 아무것도 보고하지 않습니다.
+	if changes, err := reconcileCatalog(info); err != nil || len(changes) != 0 {
+		t.Errorf("second reconcile: changes %v, err %v", changes, err)
+	}
+
+	// 카탈로그를 읽을 수 없으면 테이블 파일로부터 다시 만듭니다.
+	if err := os.WriteFile(catalogFilePath(info), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if Startup(info) != 0 {
+		t.Fatal("startup failed")
+	}
+	catalogEntryOf(t, info, "renamed")
+}
+
+func TestLoadCatalogSavesRebuiltCatalog(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table users (integer id NOTNULL KEY, text name);`)
+	if err := os.Remove(catalogFilePath(info)); err != nil {
+		t.Fatal(err)
+	}
+
+	cat, err := loadCatalog(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cat.find("users") == nil {
+		t.Fatalf("rebuilt catalog has no 'users' table: %v", cat.names())
+	}
+	// 다시 만든 카탈로그는 저장되므로 다음 호출은 테이블 파일을 다시 읽지 않습니다.
+	if _, exists, err := readCatalog(info); err != nil || !exists {
+		t.Fatalf("catalog was not saved (exists %v, err %v)", exists, err)
+	}
+}
+
+func TestUnreadableTableIsKept(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (text id NOTNULL KEY, integer v);`)
+	mustExec(t, info, `add t ("a", 1);`)
+	mustExec(t, info, `create_table other (text id NOTNULL KEY);`)
+	path := tableFilePath("t", info)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeDamaged(t, path, content, `"a", 1`, `"a", 2`)
+	damaged, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := reconcileCatalog(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Errorf("changes = %v, want one unreadable table report", changes)
+	}
+	cat, err := loadCatalog(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := cat.find("t")
+	if entry == nil || entry.Unreadable == "" {
+		t.Fatalf("entry = %+v, want an unreadable entry for 't'", entry)
+	}
+	// 상태가 그대로이면 다시 보고하지 않습니다.
+	if changes, err := reconcileCatalog(info); err != nil || len(changes) != 0 {
+		t.Errorf("second reconcile: changes %v, err %v", changes, err)
+	}
+
+	// 읽을 수 없는 파일을 새 테이블이나 이름을 바꾼 테이블로 덮어쓰지 않습니다.
+	if CmdExec(`create_table t (integer id NOTNULL KEY);`, info) == 0 {
+		t.Error("create_table overwrote the unreadable table")
+	}
+	if CmdExec(`rename_table other to t;`, info) == 0 {
+		t.Error("rename_table overwrote the unreadable table")
+	}
+	if current, err := os.ReadFile(path); err != nil || !bytes.Equal(current, damaged) {
+		t.Fatalf("unreadable table file was changed (err %v)", err)
+	}
+
+	// 파일을 되돌리면 다음 정합에서 다시 읽을 수 있는 테이블이 됩니다.
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reconcileCatalog(info); err != nil {
+		t.Fatal(err)
+	}
+	cat, err = loadCatalog(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := cat.find("t"); entry == nil || entry.Unreadable != "" {
+		t.Errorf("entry after restore = %+v, want a readable entry", entry)
+	}
+	if desc, err := DescribeTable("t", info); err != nil || desc.Rows != 1 {
+		t.Errorf("describe after restore: %+v, %v, want 1 row", desc, err)
+	}
+}
@@ -0,0 +1,142 @@
+package dbcontroller
+
+import (
+	"fmt"
+	"sedb/modules/parsers"
+	"sedb/modules/table"
+)
+
+// parseCheckClause는 tokens[i]부터 [CONSTRAINT 이름] CHECK (조건식)을 읽습니다.
+// 이름을 지정하지 않으면 빈 문자열이며, 조건식 다음 토큰의 위치를 반환합니다.
+func parseCheckClause(tokens []parsers.SC_token, i int) (string, *parsers.Expr, int, string) {
+	name := ""
+	if i < len(tokens) && tokens[i].Token_type == parsers.SC_constraint {
+		if i+1 >= len(tokens) || tokens[i+1].Token_type != parsers.SC_constraintName {
+			return "", nil, i, "syntax error: constraint name is missing"
+		}
+		name = tokens[i+1].Token.(string)
+		i += 2
+	}
+	if i >= len(tokens) || tokens[i].Token_type != parsers.SC_check {
+		return "", nil, i, "syntax error: expected CHECK after constraint name"
+	}
+	if i+1 >= len(tokens) || tokens[i+1].Token_type != parsers.SC_parenOpen {
+		return "", nil, i, "syntax error: expected ( after CHECK"
+	}
+
+	expr, next, errMsg := parsers.ParseExpr(tokens, i+2)
+	if errMsg != "" {
+		return "", nil, next, errMsg
+	}
+	if next >= len(tokens) || tokens[next].Token_type != parsers.SC_parenClose {
+		return "", nil, next, "syntax error: missing closing parenthesis after CHECK condition"
+	}
+	return name, expr, next + 1, ""
+}
+
+// checkName은 이름을 지정하지 않은 CHECK 제약의 이름을 만듭니다.
+// 열 제약은 "열이름_check", 테이블 제약은 "테이블이름_check"이며 겹치면 _2, _3, ...을 붙입니다.
+func checkName(checks []table.Check, base string) string {
+	name := base
+	for n := 2; ; n++ {
+		taken := false
+		for _, check := range checks {
+			if check.Name == name {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return name
+		}
+		name = fmt.Sprintf("%s_%d", base, n)
+	}
+}
+
+// compileChecks는 CHECK 제약의 조건식을 읽고 열에 맞춰 검사합니다.
+func compileChecks(checks []table.Check, columns []table.Column) ([]*parsers.Expr, error) {
+	exprs := make([]*parsers.Expr, len(checks))
+	for i, check := range checks {
+		expr, errMsg := parsers.ParseCondition(check.Expr)
+		if errMsg != "" {
+			return nil, fmt.Errorf("check constraint '%s': %s", check.Name, errMsg)
+		}
+		if err := bindCondition(expr, columns); err != nil {
+			return nil, fmt.Errorf("check constraint '%s': %v", check.Name, err)
+		}
+		exprs[i] = expr
+	}
+	return exprs, nil
+}
+
+// checkRow는 행 데이터가 모든 CHECK 제약을 만족하는지 확인합니다.
+// 조건식이 거짓일 때만 위반이며, NULL 때문에 결과를 알 수 없으면(unknown) 통과합니다.
+func checkRow(checks []table.Check, columns []table.Column, data map[string]interface{}) error {
+	exprs, err := compileChecks(checks, columns)
+	if err != nil {
+		return err
+	}
+	for i, expr := range exprs {
+		result, err := evalCondition(expr, columns, data)
+		if err != nil {
+			return fmt.Errorf("check constraint '%s': %v", checks[i].Name, err)
+		}
+		if result == truthFalse {
+			return fmt.Errorf("check constraint '%s' violated: %s", checks[i].Name, checks[i].Expr)
+		}
+	}
+	return nil
+}
+
+// checkRows는 모든 행이 CHECK 제약을 만족하는지 확인합니다. ALTER_TABLE로 구조를 바꿀 때 사용합니다.
+func checkRows(checks []table.Check, columns []table.Column, rows []Row) error {
+	for _, row := range rows {
+		if err := checkRow(checks, columns, row.Data); err != nil {
+			return fmt.Errorf("row '%s': %v", row.Key, err)
+		}
+	}
+	return nil
+}
+
+// checksFromHeader는 헤더 토큰에서 CHECK 제약을 추출합니다.
+func checksFromHeader(headerTokens []parsers.Tff_token) []table.Check {
+	var checks []table.Check
+	for i := 0; i+1 < len(headerTokens); i++ {
+		if headerTokens[i].Token_type == parsers.Tff_Check {
+			checks = append(checks, table.Check{
+				Name: headerTokens[i].Token.(string),
+				Expr: headerTokens[i+1].Token.(string),
+			})
+			i++
+		}
+	}
+	return checks
+}
+
+// checkUsesColumn은 name 열을 참조하는 첫 번째 CHECK 제약의 이름을 반환합니다. 없으면 빈 문자열입니다.
+func checkUsesColumn(checks []table.Check, name string) string {
+	for _, check := range checks {
+		expr, errMsg := parsers.ParseCondition(check.Expr)
+		if errMsg != "" {
+			continue
+		}
+		for _, col := range parsers.ExprColumns(expr) {
+			if col == name {
+				return check.Name
+			}
+		}
+	}
+	return ""
+}
+
+// renameCheckColumn은 CHECK 제약의 조건식에서 열 이름 from을 to로 바꿉니다.
+func renameCheckColumn(checks []table.Check, from, to string) {
+	for i, check := range checks {
+		expr, errMsg := parsers.ParseCondition(check.Expr)
+		if errMsg != "" {
+			continue
+		}
+		renameExprColumn(expr, from, to)
+		checks[i].Expr = parsers.FormatExpr(expr)
+	}
+}
@@ -0,0 +1,98 @@
+package dbcontroller
+
+import (
+	dbinfo "sedb/modules/db_info"
+	"strings"
+	"testing"
+)
+
+// newProductsDB는 CHECK 테스트에 쓰는 products 테이블을 만듭니다.
+func newProductsDB(t *testing.T) dbinfo.DBInfo {
+	t.Helper()
+	info := newTestDB(t)
+	mustExec(t, info, `create_table products (
+		integer id NOTNULL KEY,
+		integer stock NOTNULL CHECK (stock >= 0),
+		text status CHECK (status IN ('draft', 'active', 'retired')),
+		decimal(2) price,
+		CONSTRAINT priced CHECK (status = "draft" OR price IS NOT NULL)
+	);`)
+	return info
+}
+
+func TestCheckConstraints(t *testing.T) {
+	info := newProductsDB(t)
+	mustExec(t, info, `add products (1, 5, "draft", NULL);`)
+	mustExec(t, info, `add products (2, 0, "active", 9.99);`)
+	// NULL과의 비교는 unknown이므로 위반이 아닙니다.
+	mustExec(t, info, `add products (3, 1, NULL, NULL);`)
+
+	for _, script := range []string{
+		`add products (4, -1, "draft", NULL);`,
+		`add products (4, 1, "sold", NULL);`,
+		`add products (4, 1, "active", NULL);`,
+		`update products 2 (2, 0, "active", NULL);`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected check violation", script)
+		}
+	}
+
+	// 조건식은 정규화된 표기로 헤더에 기록되고, 다시 읽어도 같은 제약입니다.
+	content := readTableFile(t, "products", info)
+	for _, want := range []string{
+		`CHECK stock_check "stock >= 0"`,
+		`CHECK status_check "status IN (\"draft\", \"active\", \"retired\")"`,
+		`CHECK priced "status = \"draft\" OR price IS NOT NULL"`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("table file has no %s", want)
+		}
+	}
+	if CmdExec(`add products (4, -1, "draft", NULL);`, info) == 0 {
+		t.Error("check constraint was lost after reloading the header")
+	}
+	if CmdExec("verify;", info) != 0 {
+		t.Error("verify reported problems in rows that satisfy every check")
+	}
+}
+
+func TestCheckConstraintErrors(t *testing.T) {
+	info := newTestDB(t)
+	for _, script := range []string{
+		`create_table t (integer id NOTNULL KEY, integer n CHECK (m > 0));`,
+		`create_table t (integer id NOTNULL KEY, integer n CHECK (n));`,
+		`create_table t (integer id NOTNULL KEY, integer n, text s CHECK (s = n));`,
+		`create_table t (integer id NOTNULL KEY, date d CHECK (d > "yesterday"));`,
+		`create_table t (integer id NOTNULL KEY, integer n, CONSTRAINT c CHECK (n > 0), CONSTRAINT c CHECK (n < 9));`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+}
+
+func TestAlterTableChecks(t *testing.T) {
+	info := newProductsDB(t)
+	mustExec(t, info, `add products (1, 5, "draft", NULL);`)
+
+	// 열 이름을 바꾸면 조건식의 열 이름도 바뀝니다.
+	mustExec(t, info, `alter_table products rename_column stock to qty;`)
+	if !strings.Contains(readTableFile(t, "products", info), `CHECK stock_check "qty >= 0"`) {
+		t.Error("check condition still uses the old column name")
+	}
+	if CmdExec(`add products (2, -1, "draft", NULL);`, info) == 0 {
+		t.Error("renamed column lost its check")
+	}
+
+	for _, script := range []string{
+		`alter_table products drop_column qty;`,
+		`alter_table products add_column integer rank NOTNULL DEFAULT 0 CHECK (rank > 0);`,
+		`alter_table products modify_column status integer;`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+	mustExec(t, info, `alter_table products add_column integer rank NOTNULL DEFAULT 1 CHECK (rank > 0);`)
+}
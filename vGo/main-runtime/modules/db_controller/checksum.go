@@ -0,0 +1,96 @@
+package dbcontroller
+
+import (
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// fileChecksumMismatch는 파일 체크섬이 맞지 않을 때의 손상 사유입니다.
+const fileChecksumMismatch = "file checksum mismatch (earlier content is damaged)"
+
+// corruptionError는 테이블 파일의 손상을 나타냅니다.
+// 손상된 파일과 위치(줄 또는 페이지)를 함께 알려줍니다.
+type corruptionError struct {
+	path   string
+	where  string // 예) "line 12", "page 3", "header"
+	reason string
+}
+
+func (e *corruptionError) Error() string {
+	return fmt.Sprintf("table file '%s' is corrupted at %s: %s", e.path, e.where, e.reason)
+}
+
+// lineCorruption은 텍스트 파일 줄의 손상 오류를 만듭니다.
+func lineCorruption(path string, line int, reason string) *corruptionError {
+	return &corruptionError{path: path, where: fmt.Sprintf("line %d", line), reason: reason}
+}
+
+// pageCorruption은 바이너리 파일 페이지의 손상 오류를 만듭니다.
+func pageCorruption(path string, page int64, reason string) *corruptionError {
+	return &corruptionError{path: path, where: fmt.Sprintf("page %d", page), reason: reason}
+}
+
+// formatChecksum은 체크섬을 TFF 표기(8자리 16진수)로 변환합니다.
+func formatChecksum(sum uint32) string {
+	return fmt.Sprintf("%08x", sum)
+}
+
+// withLineChecksum은 줄 내용 뒤에 그 내용의 체크섬을 붙입니다.
+// 예) Data-> [1, "a"] ->End 3f2a9c01
+func withLineChecksum(body string) string {
+	return body + " " + formatChecksum(crc32.ChecksumIEEE([]byte(body)))
+}
+
+// splitLineChecksum은 줄을 내용과 줄 체크섬으로 나눕니다.
+// ->End로 끝나는 줄은 체크섬이 없는 이전 형식이므로 hasSum이 false입니다.
+func splitLineChecksum(line string) (body string, hasSum bool, err error) {
+	if strings.HasSuffix(line, "->End") {
+		return line, false, nil
+	}
+
+	idx := strings.LastIndexByte(line, ' ')
+	if idx < 0 {
+		return "", false, fmt.Errorf("malformed line")
+	}
+	body, field := line[:idx], line[idx+1:]
+	sum, err := strconv.ParseUint(field, 16, 32)
+	if err != nil || len(field) != 8 {
+		return "", false, fmt.Errorf("malformed line checksum '%s'", field)
+	}
+	if crc32.ChecksumIEEE([]byte(body)) != uint32(sum) {
+		return "", false, fmt.Errorf("line checksum mismatch")
+	}
+	return body, true, nil
+}
+
+// checksumWriter는 기록한 내용의 파일 체크섬(CRC32)을 이어서 계산합니다.
+// sum은 w에 기록하기 전까지의 파일 내용 체크섬으로 시작합니다.
+type checksumWriter struct {
+	w   io.Writer
+	sum uint32
+}
+
+func (cw *checksumWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.sum = crc32.Update(cw.sum, crc32.IEEETable, p[:n])
+	return n, err
+}
+
+// writeString은 s를 그대로 기록합니다.
+func (cw *checksumWriter) writeString(s string) error {
+	_, err := io.WriteString(cw, s)
+	return err
+}
+
+// writeLine은 줄 체크섬을 붙여 한 줄을 기록합니다.
+func (cw *checksumWriter) writeLine(body string) error {
+	return cw.writeString(withLineChecksum(body) + "\n")
+}
+
+// writeLsnLine은 지금까지의 파일 체크섬을 담은 Lsn-> 줄을 기록합니다.
+func (cw *checksumWriter) writeLsnLine(lsn int64) error {
+	return cw.writeLine(formatLsnLine(lsn, cw.sum))
+}
@@ -0,0 +1,125 @@
+package dbcontroller
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestLineChecksum(t *testing.T) {
+	line := withLineChecksum(`Data-> [1, "a"] ->End`)
+	body, hasSum, err := splitLineChecksum(line)
+	if err != nil || !hasSum || body != `Data-> [1, "a"] ->End` {
+		t.Fatalf("splitLineChecksum(%q) = %q, %v, %v", line, body, hasSum, err)
+	}
+
+	// 체크섬이 없는 이전 형식의 줄
+	if _, hasSum, err := splitLineChecksum(`Data-> [1, a] ->End`); err != nil || hasSum {
+		t.Errorf("legacy line: hasSum=%v err=%v", hasSum, err)
+	}
+
+	for _, bad := range []string{
+		`Data-> [1, "b"] ->End` + line[len(line)-9:], // 내용이 바뀜
+		`Data-> [1, "a"] ->End 3f2a9c0`,              // 체크섬 자릿수가 모자람
+		`Data-> [1, "a"] ->End zzzzzzzz`,
+	} {
+		if _, _, err := splitLineChecksum(bad); err == nil {
+			t.Errorf("splitLineChecksum(%q) accepted a damaged line", bad)
+		}
+	}
+}
+
+// writeDamaged는 테이블 파일의 old를 replacement로 바꿔 씁니다.
+func writeDamaged(t *testing.T, path string, content []byte, old, replacement string) {
+	t.Helper()
+	damaged := bytes.Replace(content, []byte(old), []byte(replacement), 1)
+	if bytes.Equal(damaged, content) {
+		t.Fatalf("%q is not in the table file", old)
+	}
+	if err := os.WriteFile(path, damaged, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCorruptedTableFileIsReported(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (text id NOTNULL KEY, integer v);`)
+	mustExec(t, info, `add t ("a", 1);`)
+	mustExec(t, info, `add t ("b", 2);`)
+	path := tableFilePath("t", info)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct{ name, old, replacement string }{
+		{"row", `"b", 2`, `"b", 3`},
+		{"header", `INTEGER v`, `INTEGER w`},
+	} {
+		writeDamaged(t, path, content, tt.old, tt.replacement)
+		_, err := loadTableData("t", info)
+		var corruption *corruptionError
+		if !errors.As(err, &corruption) {
+			t.Errorf("%s damage: got %v, want a corruption error", tt.name, err)
+		}
+	}
+}
+
+func TestTextTornTailIsIgnored(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (text id NOTNULL KEY, integer v);`)
+	mustExec(t, info, `add t ("a", 1);`)
+	path := tableFilePath("t", info)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 마지막 확정(Lsn->) 뒤의 레코드는 기록이 끝나지 않은 것이므로 버립니다.
+	torn := append(append([]byte{}, content...), "Data-> [\"zz\", 1] ->End 00000000\n\x00\x00"...)
+	if err := os.WriteFile(path, torn, 0644); err != nil {
+		t.Fatal(err)
+	}
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 1 || tableData.Rows[0].Key != "a" {
+		t.Fatalf("rows = %v, want only 'a'", tableData.Rows)
+	}
+
+	mustExec(t, info, `add t ("b", 2);`)
+	tableData, err = loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 2 {
+		t.Errorf("got %d rows after append, want 2", len(tableData.Rows))
+	}
+}
+
+func TestCorruptedBinaryPageIsReported(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, text n);`)
+	mustExec(t, info, `add t (1, "a");`)
+	mustExec(t, info, `convert_table t binary;`)
+	mustExec(t, info, `add t (2, "b");`)
+	path := tableFilePath("t", info)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 뒤따르는 온전한 페이지가 확정한 페이지의 손상은 완료되지 않은 기록이 아닙니다.
+	damaged := append([]byte{}, content...)
+	damaged[binaryPageSize+20] ^= 0xff
+	if err := os.WriteFile(path, damaged, 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = loadTableData("t", info)
+	var corruption *corruptionError
+	if !errors.As(err, &corruption) || corruption.where != "page 1" {
+		t.Errorf("got %v, want a corruption error at page 1", err)
+	}
+}
@@ -4,13 +4,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	dbinfo "sedb/db_info"
+	dbinfo "sedb/modules/db_info"
+	indexsystem "sedb/modules/index_system"
 	"sedb/modules/parsers"
 	"sedb/modules/table"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// execMu는 명령 실행과 WAL 기록 순서를 직렬화합니다.
+var execMu sync.Mutex
+
 // Row는 테이블의 단일 행을 나타냅니다.
 type Row struct {
 	Key  string                 `json:"key"`
@@ -20,7 +27,14 @@ type Row struct {
 // TableData는 완전한 테이블 데이터 구조를 나타냅니다.
 type TableData struct {
 	Columns []table.Column `json:"columns"`
+	Checks  []table.Check  `json:"checks"` // CHECK 제약
 	Rows    []Row          `json:"rows"`
+	Lsn     int64          `json:"lsn"` // 마지막으로 반영된 WAL 레코드 번호
+
+	records  int                                  // 파일에 기록된 레코드 수 (대체된 버전과 삭제 표시 포함)
+	storage  storageState                         // 파일 형식과 다음 레코드를 덧붙일 위치
+	unique   map[string]*indexsystem.Unique_index // UNIQUE 열 이름 -> 유일 인덱스 (처음 확인할 때 생성, 명령 사이에 보관)
+	nextAuto int64                                // AUTO_INCREMENT 카운터: 다음에 만들 키 값
 }
 
 // printError는 오류 메시지를 출력하고 오류 코드를 반환합니다.
@@ -29,32 +43,93 @@ func printError(err string) int {
 	return 1
 }
 
-// tableExists는 테이블이 이미 존재하는지 확인합니다.
-func tableExists(tableName string, dbInfo dbinfo.DB_info) bool {
-	tablePath := filepath.Join("./", dbInfo.Db_name, "tables", tableName+".tff")
-	_, err := os.Stat(tablePath)
-	return !os.IsNotExist(err)
+// dbDirPath는 데이터베이스 디렉토리 경로를 반환합니다.
+func dbDirPath(dbInfo dbinfo.DBInfo) string {
+	return filepath.Join("./", dbInfo.DbName)
 }
 
-// loadTableData는 TFF 파일에서 테이블 구조와 데이터를 불러옵니다.
-func loadTableData(tableName string, dbInfo dbinfo.DB_info) (*TableData, error) {
-	tablePath := filepath.Join("./", dbInfo.Db_name, "tables", tableName+".tff")
+// tablesDirPath는 데이터베이스의 tables 디렉토리 경로를 반환합니다.
+func tablesDirPath(dbInfo dbinfo.DBInfo) string {
+	return filepath.Join("./", dbInfo.DbName, "tables")
+}
+
+// archiveDirPath는 데이터베이스의 archive 디렉토리 경로를 반환합니다.
+func archiveDirPath(dbInfo dbinfo.DBInfo) string {
+	return filepath.Join("./", dbInfo.DbName, "archive")
+}
+
+// tableFilePath는 테이블의 TFF 파일 경로를 반환합니다.
+func tableFilePath(tableName string, dbInfo dbinfo.DBInfo) string {
+	return filepath.Join(tablesDirPath(dbInfo), tableName+".tff")
+}
 
-	content, err := os.ReadFile(tablePath)
+// tableExists는 테이블이 카탈로그에 있는지 확인합니다.
+// 카탈로그를 읽을 수 없으면 테이블 파일이 있는지로 판단합니다.
+func tableExists(tableName string, dbInfo dbinfo.DBInfo) bool {
+	cat, err := loadCatalog(dbInfo)
+	if err != nil {
+		_, err := os.Stat(tableFilePath(tableName, dbInfo))
+		return !os.IsNotExist(err)
+	}
+	return cat.find(tableName) != nil
+}
+
+// checkTableFileFree는 새 테이블 파일을 쓸 자리가 비어 있는지 확인합니다.
+// 카탈로그에 없더라도 파일이 있으면(읽을 수 없는 파일 등) 덮어쓰지 않도록 오류 메시지를 반환합니다.
+func checkTableFileFree(tableName string, dbInfo dbinfo.DBInfo) string {
+	_, err := os.Stat(tableFilePath(tableName, dbInfo))
+	if err == nil {
+		return fmt.Sprintf("error: table file '%s.tff' already exists", tableName)
+	}
+	if !os.IsNotExist(err) {
+		return fmt.Sprintf("error: cannot check table file '%s.tff': %v", tableName, err)
+	}
+	return ""
+}
+
+// loadTableData는 TFF 파일에서 테이블 구조와 데이터를 불러옵니다.
+// 레코드를 순서대로 적용하여 살아 있는 행만 메모리에 남깁니다.
+func loadTableData(tableName string, dbInfo dbinfo.DBInfo) (*TableData, error) {
+	reader, file, err := openTableReader(tableName, dbInfo)
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
-	// 헤더(테이블 구조) 파싱
-	var headerTokens []parsers.Tff_token
-	if parsers.ParseHeader(string(content), &headerTokens) != 0 {
-		return nil, fmt.Errorf("failed to parse table header")
+	rows := newRowSet()
+	nextAuto := reader.autoIncrement()
+	for {
+		rec, ok, err := reader.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		rows.apply(rec)
+		nextAuto = trackAutoIncrement(nextAuto, reader.columns(), rec)
 	}
 
+	// 복합 키 테이블의 행은 키 튜플 순서로 놓입니다.
+	live := rows.live()
+	sortRowsByKey(reader.columns(), live)
+
 	tableData := &TableData{
-		Columns: make([]table.Column, 0),
-		Rows:    make([]Row, 0),
+		Columns: reader.columns(),
+		Checks:  reader.checks(),
+		Rows:    live,
+		Lsn:     reader.lastLsn(),
+		records: rows.records,
+		storage: reader.storage(),
+
+		nextAuto: nextAuto,
 	}
+	return tableData, nil
+}
+
+// columnsFromHeader는 헤더 토큰에서 열 정의를 추출합니다.
+func columnsFromHeader(headerTokens []parsers.Tff_token) []table.Column {
+	columns := make([]table.Column, 0)
 
 	// 헤더 토큰에서 열 정의 추출
 	i := 0
@@ -66,14 +141,37 @@ func loadTableData(tableName string, dbInfo dbinfo.DB_info) (*TableData, error)
 
 				// END가 나올 때까지 열 파싱
 				for i < len(headerTokens) && headerTokens[i].Token_type != parsers.Tff_end {
+					// CHECK 제약은 checksFromHeader가 읽습니다.
+					if headerTokens[i].Token_type == parsers.Tff_Check {
+						i += 2
+						continue
+					}
 					if i+1 < len(headerTokens) {
 						// 열 타입
 						var colType table.Column_type
+						scale := 0
 						switch headerTokens[i].Token_type {
 						case parsers.Tff_Cnumber:
 							colType = table.CT_number
 						case parsers.Tff_Ctext:
 							colType = table.CT_text
+						case parsers.Tff_Cinteger:
+							colType = table.CT_integer
+						case parsers.Tff_Cfloat:
+							colType = table.CT_float
+						case parsers.Tff_Cdecimal:
+							colType = table.CT_decimal
+							scale = headerTokens[i].Token.(int)
+						case parsers.Tff_Cbool:
+							colType = table.CT_bool
+						case parsers.Tff_Cdate:
+							colType = table.CT_date
+						case parsers.Tff_Ctimestamp:
+							colType = table.CT_timestamp
+						case parsers.Tff_Cblob:
+							colType = table.CT_blob
+						case parsers.Tff_Cjson:
+							colType = table.CT_json
 						default:
 							colType = table.CT_none
 						}
@@ -84,29 +182,49 @@ func loadTableData(tableName string, dbInfo dbinfo.DB_info) (*TableData, error)
 							colName := headerTokens[i].Token.(string)
 							i++
 
-							// 속성 확인
-							isKey := false
-							notNull := false
+							col := table.Column{
+								Type:  colType,
+								Name:  colName,
+								Scale: scale,
+							}
 
+							// 속성 확인
 							for i < len(headerTokens) &&
 								(headerTokens[i].Token_type == parsers.Tff_Key ||
-									headerTokens[i].Token_type == parsers.Tff_Notnull) {
+									headerTokens[i].Token_type == parsers.Tff_Notnull ||
+									headerTokens[i].Token_type == parsers.Tff_Unique ||
+									headerTokens[i].Token_type == parsers.Tff_Generated ||
+									headerTokens[i].Token_type == parsers.Tff_Default ||
+									headerTokens[i].Token_type == parsers.Tff_References ||
+									headerTokens[i].Token_type == parsers.Tff_OnDelete) {
 
 								switch headerTokens[i].Token_type {
 								case parsers.Tff_Key:
-									isKey = true
+									col.Is_key = true
 								case parsers.Tff_Notnull:
-									notNull = true
+									col.Not_null = true
+								case parsers.Tff_Unique:
+									col.Unique = true
+								case parsers.Tff_Generated:
+									col.Generated = generatedKinds[strings.ToLower(headerTokens[i].Token.(string))]
+									if col.Generated == table.GK_increment {
+										i++ // 카운터 값은 autoIncrementFromHeader가 읽습니다.
+									}
+								case parsers.Tff_References:
+									col.References = headerTokens[i].Token.(string)
+								case parsers.Tff_OnDelete:
+									col.On_delete = refActions[headerTokens[i].Token.(string)]
+								case parsers.Tff_Default:
+									// DEFAULT 다음 토큰이 기본값
+									if i+1 < len(headerTokens) {
+										i++
+										defaultFromHeader(&col, headerTokens[i])
+									}
 								}
 								i++
 							}
 
-							tableData.Columns = append(tableData.Columns, table.Column{
-								Type:     colType,
-								Name:     colName,
-								Is_key:   isKey,
-								Not_null: notNull,
-							})
+							columns = append(columns, col)
 						}
 					} else {
 						i++
@@ -118,181 +236,225 @@ func loadTableData(tableName string, dbInfo dbinfo.DB_info) (*TableData, error)
 		}
 	}
 
-	// 데이터 섹션 파싱
-	lines := strings.Split(string(content), "\n")
-	inDataSection := false
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if strings.HasPrefix(line, "DATA_SECTION") {
-			inDataSection = true
-			continue
-		}
-
-		if inDataSection && strings.HasPrefix(line, "Data->") && strings.HasSuffix(line, "->End") {
-			var dataTokens []parsers.Tff_token
-			if parsers.ParseDataLine(line, &dataTokens) == 0 {
-				row := Row{
-					Data: make(map[string]interface{}),
-				}
+	return columns
+}
 
-				dataIndex := 0
-				for _, token := range dataTokens {
-					if token.Token_type == parsers.Tff_float64 || token.Token_type == parsers.Tff_string {
-						if dataIndex < len(tableData.Columns) {
-							col := tableData.Columns[dataIndex]
-
-							var value interface{}
-							if token.Token_type == parsers.Tff_float64 {
-								value = fmt.Sprintf("%.0f", token.Token.(float64))
-							} else {
-								value = token.Token.(string)
-							}
+// saveTableData는 테이블 데이터를 테이블의 파일 형식으로 저장합니다.
+// 임시 파일에 전체 내용을 기록하고 디스크에 동기화한 뒤 원자적으로 교체하므로
+// 도중에 중단되더라도 테이블은 이전 버전 또는 새 버전 중 하나로 남습니다.
+func saveTableData(tableData *TableData, tableName string, dbInfo dbinfo.DBInfo) error {
+	// 실행 중인 WAL 레코드 번호를 기록하여 재시작 시 중복 적용을 막습니다.
+	if currentLsn > tableData.Lsn {
+		tableData.Lsn = currentLsn
+	}
 
-							if col.Is_key {
-								row.Key = fmt.Sprintf("%v", value)
-							}
-							row.Data[col.Name] = value
-							dataIndex++
-						}
-					}
-				}
+	write := writeTableFile
+	if tableData.storage.format == formatBinary {
+		write = writeBinaryTableFile
+	}
 
-				if len(row.Data) > 0 {
-					tableData.Rows = append(tableData.Rows, row)
-				}
-			}
+	var size int64
+	err := writeFileAtomic(tableFilePath(tableName, dbInfo), func(file *os.File) error {
+		if err := write(file, tableData, tableName); err != nil {
+			return err
 		}
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		size = info.Size()
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	return tableData, nil
+	// 다시 쓴 파일에는 살아 있는 행만 남습니다.
+	tableData.records = len(tableData.Rows)
+	tableData.storage.committedSize = size
+	tableData.storage.hasLsn = true
+	tableData.storage.needsNewline = false
+	return nil
 }
 
-// saveTableData는 테이블 데이터를 TFF 파일에 저장합니다.
-func saveTableData(tableData *TableData, tableName string, dbInfo dbinfo.DB_info) error {
-	tablePath := filepath.Join("./", dbInfo.Db_name, "tables", tableName+".tff")
+// writeTableFile은 테이블 데이터를 텍스트 TFF 형식으로 file에 기록합니다.
+// 레코드 줄마다 줄 체크섬을, 마지막 Lsn-> 줄에 파일 전체의 체크섬을 기록합니다.
+func writeTableFile(file *os.File, tableData *TableData, tableName string) error {
+	cw := &checksumWriter{w: file}
 
-	file, err := os.Create(tablePath)
+	// 제목과 TABLE_S 섹션 작성
+	err := cw.writeString(formatTableHeader(tableData, tableName))
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	// 제목 작성
-	_, err = file.WriteString(fmt.Sprintf("Title : \"%s\"\n\n", tableName))
+	// DATA_SECTION 작성
+	err = cw.writeString("DATA_SECTION :\n")
 	if err != nil {
 		return err
 	}
 
-	// TABLE_S 섹션 작성
-	_, err = file.WriteString("TABLE_S BEGIN\n")
+	for _, row := range tableData.Rows {
+		err = cw.writeLine(formatDataLine(tableData.Columns, row))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = cw.writeLsnLine(tableData.Lsn)
 	if err != nil {
 		return err
 	}
 
-	for i, col := range tableData.Columns {
-		var colTypeStr string
-		switch col.Type {
-		case table.CT_number:
-			colTypeStr = "NUMBER"
-		case table.CT_text:
-			colTypeStr = "TEXT"
-		default:
-			colTypeStr = "UNKNOWN"
-		}
+	tableData.storage.checksum = cw.sum
+	return nil
+}
 
-		line := fmt.Sprintf("    %s %s", colTypeStr, col.Name)
+// formatTableHeader는 제목과 TABLE_S 섹션(테이블 구조)을 TFF 헤더 문자열로 만듭니다.
+// 텍스트 형식과 바이너리 형식이 같은 헤더 표기를 사용합니다.
+// AUTO_INCREMENT 열에는 카운터의 다음 값을 함께 기록하고, CHECK 제약은 열 정의 뒤에 기록합니다.
+func formatTableHeader(tableData *TableData, tableName string) string {
+	var b strings.Builder
+	columns := tableData.Columns
 
-		if col.Not_null {
-			line += " NOTNULL"
-		}
-		if col.Is_key {
-			line += " KEY"
-		}
+	// 제목 작성
+	b.WriteString(fmt.Sprintf("Title : \"%s\"\n\n", tableName))
 
-		if i < len(tableData.Columns)-1 {
+	// TABLE_S 섹션 작성
+	b.WriteString("TABLE_S BEGIN\n")
+
+	for i, col := range columns {
+		line := "    " + formatColumnDefinition(col, max(tableData.nextAuto, 1))
+		if i < len(columns)-1 || len(tableData.Checks) > 0 {
 			line += ","
 		}
-		line += "\n"
+		b.WriteString(line + "\n")
+	}
 
-		_, err = file.WriteString(line)
-		if err != nil {
-			return err
+	for i, check := range tableData.Checks {
+		line := "    " + formatCheckDefinition(check)
+		if i < len(tableData.Checks)-1 {
+			line += ","
 		}
+		b.WriteString(line + "\n")
 	}
 
-	_, err = file.WriteString("END\n\n")
-	if err != nil {
-		return err
-	}
+	b.WriteString("END\n\n")
+	return b.String()
+}
 
-	// DATA_SECTION 작성
-	_, err = file.WriteString("DATA_SECTION :\n")
-	if err != nil {
-		return err
-	}
+// formatColumnDefinition은 열 하나의 TFF 헤더 정의를 만듭니다. 예) INTEGER id NOTNULL KEY
+// nextAuto가 0보다 크면 AUTO_INCREMENT 뒤에 카운터의 다음 값을 붙입니다.
+func formatColumnDefinition(col table.Column, nextAuto int64) string {
+	def := fmt.Sprintf("%s %s", columnTypeName(col), col.Name)
 
-	for _, row := range tableData.Rows {
-		dataStr := "Data-> ["
-		for i, col := range tableData.Columns {
-			if i > 0 {
-				dataStr += ", "
-			}
-			dataStr += fmt.Sprintf("%v", row.Data[col.Name])
+	if col.Not_null {
+		def += " NOTNULL"
+	}
+	if col.Is_key {
+		def += " KEY"
+	}
+	if col.Unique {
+		def += " UNIQUE"
+	}
+	switch col.Generated {
+	case table.GK_increment:
+		def += " AUTO_INCREMENT"
+		if nextAuto > 0 {
+			def += fmt.Sprintf(" %d", nextAuto)
 		}
-		dataStr += "] ->End\n"
-
-		_, err = file.WriteString(dataStr)
-		if err != nil {
-			return err
+	case table.GK_uuid:
+		def += " AUTO_UUID"
+	case table.GK_ulid:
+		def += " AUTO_ULID"
+	}
+	if col.Default_kind != table.DK_none {
+		def += " DEFAULT " + formatColumnDefault(col)
+	}
+	if col.References != "" {
+		def += " REFERENCES " + col.References
+		if col.On_delete != table.RA_restrict {
+			def += " ON_DELETE " + refActionName(col.On_delete)
 		}
 	}
+	return def
+}
 
-	return nil
+// formatCheckDefinition은 CHECK 제약의 TFF 헤더 정의를 만듭니다. 예) CHECK age_check "age >= 0"
+func formatCheckDefinition(check table.Check) string {
+	return fmt.Sprintf("CHECK %s %s", check.Name, parsers.QuoteTffString(check.Expr))
 }
 
-// validateDataTypes는 열 타입에 따라 데이터를 검증합니다.
-func validateDataTypes(data []string, columns []table.Column) error {
+// columnTypeName은 열 타입의 TFF 헤더 표기를 반환합니다.
+func columnTypeName(col table.Column) string {
+	switch col.Type {
+	case table.CT_number:
+		return "NUMBER"
+	case table.CT_text:
+		return "TEXT"
+	case table.CT_integer:
+		return "INTEGER"
+	case table.CT_float:
+		return "FLOAT"
+	case table.CT_decimal:
+		return fmt.Sprintf("DECIMAL(%d)", col.Scale)
+	case table.CT_bool:
+		return "BOOL"
+	case table.CT_date:
+		return "DATE"
+	case table.CT_timestamp:
+		return "TIMESTAMP"
+	case table.CT_blob:
+		return "BLOB"
+	case table.CT_json:
+		return "JSON"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// formatTffValue는 열 타입에 맞게 값을 데이터 줄 형식으로 변환합니다.
+// 텍스트 값은 항상 따옴표로 감싸고 이스케이프하여 손실 없이 기록합니다.
+// NULL(nil)은 따옴표 없는 NULL로 기록합니다.
+func formatTffValue(col table.Column, value interface{}) string {
+	if value == nil {
+		return "NULL"
+	}
+	if col.Type == table.CT_text || col.Type == table.CT_json {
+		return parsers.QuoteTffString(fmt.Sprintf("%v", value))
+	}
+	return formatValue(value)
+}
+
+// validateDataTypes는 열 타입에 따라 데이터를 검증하고 열 타입의 값으로 변환합니다.
+// nil은 NULL이며, 빈 문자열은 NULL이 아닌 일반 값으로 취급합니다.
+func validateDataTypes(data []interface{}, columns []table.Column) ([]interface{}, error) {
 	if len(data) != len(columns) {
-		return fmt.Errorf("data structure mismatch: expected %d values, received %d values",
+		return nil, fmt.Errorf("data structure mismatch: expected %d values, received %d values",
 			len(columns), len(data))
 	}
 
+	values := make([]interface{}, len(data))
 	for i, value := range data {
 		col := columns[i]
 
 		// NOT NULL 제약 조건 확인
-		if col.Not_null && value == "" {
-			return fmt.Errorf("column '%s' cannot be NULL", col.Name)
+		if value == nil {
+			if col.Not_null {
+				return nil, fmt.Errorf("column '%s' cannot be NULL", col.Name)
+			}
+			continue
 		}
 
 		// 데이터 타입 확인
-		if value != "" {
-			switch col.Type {
-			case table.CT_number:
-				if _, err := strconv.ParseFloat(value, 64); err != nil {
-					return fmt.Errorf("invalid number format for column '%s': %s",
-						col.Name, value)
-				}
-			case table.CT_text:
-				// 텍스트 유효성 검사는 필요한 경우 여기에 추가할 수 있습니다.
-				break
-			}
+		converted, err := convertValue(col, fmt.Sprintf("%v", value))
+		if err != nil {
+			return nil, err
 		}
+		values[i] = converted
 	}
 
-	return nil
-}
-
-// findKeyColumn은 열 목록에서 키 열을 반환합니다.
-func findKeyColumn(columns []table.Column) *table.Column {
-	for i := range columns {
-		if columns[i].Is_key {
-			return &columns[i]
-		}
-	}
-	return nil
+	return values, nil
 }
 
 // keyExists는 키가 테이블 데이터에 이미 존재하는지 확인합니다.
@@ -305,26 +467,209 @@ func keyExists(key string, tableData *TableData) bool {
 	return false
 }
 
+// checkKeyValues는 행의 키 열 값이 모두 있는지 확인합니다. 키 값은 NULL이나 빈 값일 수 없습니다.
+func checkKeyValues(columns []table.Column, row Row) string {
+	keyCols := keyColumns(columns)
+	if len(keyCols) == 0 {
+		return "error: cannot find key column"
+	}
+	for _, col := range keyCols {
+		value := row.Data[col.Name]
+		if value == nil {
+			return "error: key value cannot be NULL"
+		}
+		if formatValue(value) == "" {
+			return "error: key value cannot be empty"
+		}
+	}
+	return ""
+}
+
+// placeRow는 새로 기록한 행을 메모리의 행 목록에 넣습니다.
+// 복합 키 테이블은 키 튜플 순서를 유지하고, 그 외에는 기록 순서대로 끝에 붙입니다.
+func placeRow(tableData *TableData, row Row) {
+	keyCols := keyColumns(tableData.Columns)
+	if len(keyCols) < 2 {
+		tableData.Rows = append(tableData.Rows, row)
+		return
+	}
+	pos := sort.Search(len(tableData.Rows), func(i int) bool {
+		return compareRowKeys(keyCols, tableData.Rows[i], row) > 0
+	})
+	tableData.Rows = append(tableData.Rows, Row{})
+	copy(tableData.Rows[pos+1:], tableData.Rows[pos:])
+	tableData.Rows[pos] = row
+}
+
+// sortRowsByKey는 복합 키 테이블의 행을 키 튜플 순서로 정렬합니다.
+func sortRowsByKey(columns []table.Column, rows []Row) {
+	keyCols := keyColumns(columns)
+	if len(keyCols) < 2 {
+		return
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return compareRowKeys(keyCols, rows[i], rows[j]) < 0
+	})
+}
+
+// keyFromTokens는 tokens[i]부터 행 키 값을 읽습니다. 복합 키는 (값1, 값2, ...) 튜플로 씁니다.
+// 키 값 목록과 키 다음 토큰 위치를 반환합니다.
+func keyFromTokens(tokens []parsers.SC_token, i int) ([]string, int, string) {
+	if i >= len(tokens) || tokens[i].Token_type == parsers.SC_endCmd {
+		return nil, i, "syntax error: key value is missing"
+	}
+	if tokens[i].Token_type == parsers.SC_null {
+		return nil, i, "error: key value cannot be NULL"
+	}
+	if tokens[i].Token_type != parsers.SC_parenOpen {
+		return []string{fmt.Sprintf("%v", tokens[i].Token)}, i + 1, ""
+	}
+
+	var raw []string
+	for i++; i < len(tokens) && tokens[i].Token_type != parsers.SC_parenClose; i++ {
+		switch tokens[i].Token_type {
+		case parsers.SC_comma:
+		case parsers.SC_null:
+			return nil, i, "error: key value cannot be NULL"
+		case parsers.SC_parenOpen, parsers.SC_endCmd:
+			return nil, i, fmt.Sprintf("syntax error: unexpected '%v' in key", tokens[i].Token)
+		default:
+			raw = append(raw, fmt.Sprintf("%v", tokens[i].Token))
+		}
+	}
+	if i >= len(tokens) {
+		return nil, i, "syntax error: missing ')' after key"
+	}
+	if len(raw) == 0 {
+		return nil, i, "syntax error: key value is missing"
+	}
+	return raw, i + 1, ""
+}
+
 // enhancedErrorChecker는 포괄적인 구문 및 의미 검증을 수행합니다.
 func enhancedErrorChecker(tokens []parsers.SC_token, errBuffer *string) int {
 	// parsers 패키지의 기존 Error_checker를 사용합니다.
 	return parsers.Error_checker(tokens, errBuffer)
 }
 
+// parseColumnType은 tokens[i]부터 열 타입(decimal은 자릿수 포함)을 읽습니다.
+// 타입, 소수 자릿수, 다음 토큰 위치를 반환하며 문법 오류이면 오류 메시지를 반환합니다.
+func parseColumnType(tokens []parsers.SC_token, i int) (table.Column_type, int, int, string) {
+	if i >= len(tokens) {
+		return table.CT_none, 0, i, "syntax error: column type is missing"
+	}
+
+	var colType table.Column_type
+	switch tokens[i].Token_type {
+	case parsers.SC_columnNumber:
+		colType = table.CT_number
+	case parsers.SC_columnText:
+		colType = table.CT_text
+	case parsers.SC_columnInteger:
+		colType = table.CT_integer
+	case parsers.SC_columnFloat:
+		colType = table.CT_float
+	case parsers.SC_columnDecimal:
+		colType = table.CT_decimal
+	case parsers.SC_columnBool:
+		colType = table.CT_bool
+	case parsers.SC_columnDate:
+		colType = table.CT_date
+	case parsers.SC_columnTimestamp:
+		colType = table.CT_timestamp
+	case parsers.SC_columnBlob:
+		colType = table.CT_blob
+	case parsers.SC_columnJson:
+		colType = table.CT_json
+	default:
+		return table.CT_none, 0, i, fmt.Sprintf("syntax error: unknown column type '%v'", tokens[i].Token)
+	}
+	i++
+
+	// decimal(자릿수)
+	scale := 0
+	if colType == table.CT_decimal && i < len(tokens) && tokens[i].Token_type == parsers.SC_parenOpen {
+		if i+2 >= len(tokens) || tokens[i+1].Token_type != parsers.SC_number ||
+			tokens[i+2].Token_type != parsers.SC_parenClose {
+			return table.CT_none, 0, i, "syntax error: invalid decimal scale"
+		}
+		n, err := strconv.Atoi(tokens[i+1].Token.(string))
+		if err != nil || n < 0 {
+			return table.CT_none, 0, i, fmt.Sprintf("syntax error: invalid decimal scale '%v'", tokens[i+1].Token)
+		}
+		scale = n
+		i += 3
+	}
+	return colType, scale, i, ""
+}
+
+// keyClauseColumns는 KEY 다음 tokens[i]부터 (열1, 열2, ...) 열 이름 목록을 읽습니다.
+func keyClauseColumns(tokens []parsers.SC_token, i int) ([]string, int, string) {
+	if i >= len(tokens) || tokens[i].Token_type != parsers.SC_parenOpen {
+		return nil, i, "syntax error: expected '(' after KEY"
+	}
+	var names []string
+	for i++; i < len(tokens) && tokens[i].Token_type != parsers.SC_parenClose; i++ {
+		switch tokens[i].Token_type {
+		case parsers.SC_comma:
+		case parsers.SC_columnName:
+			names = append(names, tokens[i].Token.(string))
+		default:
+			return nil, i, fmt.Sprintf("syntax error: unexpected '%v' in KEY clause", tokens[i].Token)
+		}
+	}
+	if i >= len(tokens) {
+		return nil, i, "syntax error: missing ')' after KEY columns"
+	}
+	if len(names) == 0 {
+		return nil, i, "syntax error: KEY clause needs at least one column"
+	}
+	return names, i + 1, ""
+}
+
+// applyKeyClause는 KEY 절의 열들을 키 열로 지정합니다.
+// 키 튜플의 순서는 열 순서이므로 KEY 절은 열을 선언한 순서대로 나열해야 합니다.
+func applyKeyClause(t *table.Table, names []string) string {
+	last := -1
+	for _, name := range names {
+		idx := findColumnIndex(t.Columns_struct, name)
+		if idx < 0 {
+			return fmt.Sprintf("error: KEY column '%s' does not exist", name)
+		}
+		col := &t.Columns_struct[idx]
+		if col.Is_key {
+			return fmt.Sprintf("error: column '%s' is listed twice in KEY clause", name)
+		}
+		if idx < last {
+			return "error: KEY clause must list columns in the order they are declared"
+		}
+		if col.Unique && len(names) == 1 {
+			return fmt.Sprintf("error: KEY column '%s' is already unique", name)
+		}
+		col.Is_key = true
+		last = idx
+	}
+	return ""
+}
+
 // handleCreateTable은 CREATE TABLE 명령을 처리합니다.
-func handleCreateTable(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
+func handleCreateTable(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
 	if len(tokens) < 2 {
 		return printError("syntax error: table name is missing")
 	}
 
-	tableName := tokens[1].Token.(string)
-	if tableName == "" {
-		return printError("syntax error: invalid table name")
+	// 키워드(열 타입 이름 등)는 테이블 이름으로 쓸 수 없습니다.
+	tableName, ok := tokens[1].Token.(string)
+	if tokens[1].Token_type != parsers.SC_tableName || !ok || tableName == "" {
+		return printError(fmt.Sprintf("syntax error: invalid table name '%v'", tokens[1].Token))
 	}
 
 	if tableExists(tableName, dbInfo) {
 		return printError(fmt.Sprintf("error: table '%s' already exists", tableName))
 	}
+	if msg := checkTableFileFree(tableName, dbInfo); msg != "" {
+		return printError(msg)
+	}
 
 	var newTable table.Table
 	if table.NewTable(tableName, &newTable) != 0 {
@@ -344,6 +689,7 @@ func handleCreateTable(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
 	}
 
 	keyColumnCount := 0
+	var compositeKey []string // KEY (열1, 열2, ...) 절의 열 이름
 	i := columnStartIdx
 
 	for i < len(tokens) && tokens[i].Token_type != parsers.SC_parenClose {
@@ -351,42 +697,111 @@ func handleCreateTable(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
 			return printError("syntax error: unexpected end of statement")
 		}
 
-		var colType table.Column_type
-		typeToken := tokens[i]
+		// 테이블 단위 키 선언: KEY (열1, 열2, ...)
+		if tokens[i].Token_type == parsers.SC_key {
+			if compositeKey != nil {
+				return printError("error: only one KEY clause is allowed per table")
+			}
+			names, next, errMsg := keyClauseColumns(tokens, i+1)
+			if errMsg != "" {
+				return printError(errMsg)
+			}
+			compositeKey = names
+			i = next
+			if i < len(tokens) && tokens[i].Token_type == parsers.SC_comma {
+				i++
+			}
+			continue
+		}
 
-		switch typeToken.Token_type {
-		case parsers.SC_columnNumber:
-			colType = table.CT_number
-		case parsers.SC_columnText:
-			colType = table.CT_text
-		default:
-			return printError(fmt.Sprintf("syntax error: unknown column type '%v'",
-				typeToken.Token))
+		// 테이블 단위 검사 제약: [CONSTRAINT 이름] CHECK (조건식)
+		if tokens[i].Token_type == parsers.SC_check || tokens[i].Token_type == parsers.SC_constraint {
+			name, expr, next, errMsg := parseCheckClause(tokens, i)
+			if errMsg != "" {
+				return printError(errMsg)
+			}
+			if name == "" {
+				name = checkName(newTable.Checks, tableName+"_check")
+			}
+			if table.AddCheck(&newTable, name, parsers.FormatExpr(expr)) != 0 {
+				return printError(fmt.Sprintf("error: constraint '%s' already exists", name))
+			}
+			i = next
+			if i < len(tokens) && tokens[i].Token_type == parsers.SC_comma {
+				i++
+			}
+			continue
 		}
-		i++
+
+		colType, scale, next, errMsg := parseColumnType(tokens, i)
+		if errMsg != "" {
+			return printError(errMsg)
+		}
+		i = next
 
 		if i >= len(tokens) {
 			return printError("syntax error: column name is missing")
 		}
-		colName := tokens[i].Token.(string)
-		if colName == "" {
-			return printError("syntax error: invalid column name")
+		// 키워드(열 타입 이름, UNIQUE, DEFAULT, NULL 등)는 열 이름으로 쓸 수 없습니다.
+		// 만들 수 있게 두면 SELECT, WHERE, ALTER_TABLE에서 그 열을 가리킬 수 없습니다.
+		colName, ok := columnNameAt(tokens, i)
+		if !ok {
+			return printError(fmt.Sprintf("syntax error: invalid column name '%v'", tokens[i].Token))
 		}
 		i++
 
 		var isKey bool = false
 		var notNull bool = false
+		var unique bool = false
+		var generated table.Generated_kind = table.GK_none
+		var hasDefault bool = false
+		var defaultStart int
+		var checks []table.Check // 열에 붙인 CHECK 제약 (이름이 없으면 빈 문자열)
+		var references string
+		var onDelete table.Ref_action
 
 		for i < len(tokens) &&
 			tokens[i].Token_type != parsers.SC_comma &&
 			tokens[i].Token_type != parsers.SC_parenClose {
 
 			switch tokens[i].Token_type {
+			case parsers.SC_check, parsers.SC_constraint:
+				name, expr, next, errMsg := parseCheckClause(tokens, i)
+				if errMsg != "" {
+					return printError(errMsg)
+				}
+				checks = append(checks, table.Check{Name: name, Expr: parsers.FormatExpr(expr)})
+				i = next
+				continue
+			case parsers.SC_references:
+				parent, action, next, errMsg := parseReferencesClause(tokens, i)
+				if errMsg != "" {
+					return printError(errMsg)
+				}
+				references, onDelete = parent, action
+				i = next
+				continue
 			case parsers.SC_notNull:
 				notNull = true
 			case parsers.SC_key:
 				isKey = true
 				keyColumnCount++
+			case parsers.SC_unique:
+				unique = true
+			case parsers.SC_generated:
+				generated = generatedKinds[tokens[i].Token.(string)]
+			case parsers.SC_default:
+				// 기본값은 열의 다른 속성이 모두 정해진 뒤에 확인합니다.
+				hasDefault = true
+				defaultStart = i + 1
+				i++
+				if i >= len(tokens) || tokens[i].Token_type == parsers.SC_comma ||
+					tokens[i].Token_type == parsers.SC_parenClose || tokens[i].Token_type == parsers.SC_endCmd {
+					return printError("syntax error: default value is missing")
+				}
+				if i < len(tokens) && tokens[i].Token_type == parsers.SC_func {
+					i += 2 // 함수 이름 뒤의 ()
+				}
 			default:
 				return printError(fmt.Sprintf("syntax error: unknown attribute '%v'",
 					tokens[i].Token))
@@ -394,20 +809,68 @@ func handleCreateTable(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
 			i++
 		}
 
-		if table.AddColumn(&newTable, colName, colType, isKey, notNull) != 0 {
+		var addErr int
+		if colType == table.CT_decimal {
+			addErr = table.AddDecimal(&newTable, colName, scale, isKey, notNull)
+		} else {
+			addErr = table.AddColumn(&newTable, colName, colType, isKey, notNull)
+		}
+		if addErr != 0 {
 			return printError("error: failed to add column")
 		}
 
+		if unique {
+			if isKey {
+				return printError(fmt.Sprintf("error: KEY column '%s' is already unique", colName))
+			}
+			table.SetUnique(&newTable, colName)
+		}
+
+		if generated != table.GK_none {
+			table.SetGenerated(&newTable, colName, generated)
+		}
+
+		if hasDefault {
+			col := newTable.Columns_struct[len(newTable.Columns_struct)-1]
+			if _, errMsg := parseDefaultClause(&col, tokens, defaultStart); errMsg != "" {
+				return printError(errMsg)
+			}
+			table.SetDefault(&newTable, col.Name, col.Default_kind, col.Default)
+		}
+
+		if references != "" {
+			table.SetReferences(&newTable, colName, references, onDelete)
+		}
+
+		for _, check := range checks {
+			if check.Name == "" {
+				check.Name = checkName(newTable.Checks, colName+"_check")
+			}
+			if table.AddCheck(&newTable, check.Name, check.Expr) != 0 {
+				return printError(fmt.Sprintf("error: constraint '%s' already exists", check.Name))
+			}
+		}
+
 		if i < len(tokens) && tokens[i].Token_type == parsers.SC_comma {
 			i++
 		}
 	}
 
+	if compositeKey != nil {
+		if keyColumnCount != 0 {
+			return printError("error: use either a KEY column attribute or a KEY clause, not both")
+		}
+		if errMsg := applyKeyClause(&newTable, compositeKey); errMsg != "" {
+			return printError(errMsg)
+		}
+		keyColumnCount = 1
+	}
+
 	if keyColumnCount != 1 {
 		if keyColumnCount == 0 {
-			return printError("error: exactly one KEY column is required")
+			return printError("error: a KEY column or a KEY (...) clause is required")
 		} else {
-			return printError("error: only one KEY column is allowed per table")
+			return printError("error: only one KEY column is allowed per table; use KEY (column1, column2) for a composite key")
 		}
 	}
 
@@ -417,26 +880,49 @@ func handleCreateTable(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
 		}
 	}
 
+	if errMsg := checkGenerated(newTable.Columns_struct); errMsg != "" {
+		return printError(errMsg)
+	}
+
 	if len(newTable.Columns_struct) == 0 {
 		return printError("error: table must have at least one column")
 	}
 
+	if _, err := compileChecks(newTable.Checks, newTable.Columns_struct); err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+
+	for _, col := range newTable.Columns_struct {
+		if col.References == "" {
+			continue
+		}
+		if err := checkReferenceTarget(col, tableName, newTable.Columns_struct, dbInfo); err != nil {
+			return printError(fmt.Sprintf("error: %v", err))
+		}
+	}
+
 	tableData := &TableData{
-		Columns: newTable.Columns_struct,
-		Rows:    make([]Row, 0),
+		Columns:  newTable.Columns_struct,
+		Checks:   newTable.Checks,
+		Rows:     make([]Row, 0),
+		nextAuto: 1,
 	}
 
 	if err := saveTableData(tableData, tableName, dbInfo); err != nil {
 		return printError(fmt.Sprintf("error: failed to create table file: %v", err))
 	}
+	if err := catalogPutTable(tableName, tableData, dbInfo); err != nil {
+		return printError(fmt.Sprintf("error: failed to update catalog: %v", err))
+	}
 
 	fmt.Printf("Table '%s' created successfully\n", tableName)
 	return 0
 }
 
 // parseDataFromTokens는 ADD/UPDATE 명령 토큰에서 데이터 값을 추출합니다.
-func parseDataFromTokens(tokens []parsers.SC_token, startIdx int) []string {
-	var dataValues []string
+// NULL 키워드는 nil로, DEFAULT 키워드는 useDefault로, 그 외의 값은 문자열로 반환합니다.
+func parseDataFromTokens(tokens []parsers.SC_token, startIdx int) []interface{} {
+	var dataValues []interface{}
 	inParens := false
 
 	for i := startIdx; i < len(tokens); i++ {
@@ -448,7 +934,13 @@ func parseDataFromTokens(tokens []parsers.SC_token, startIdx int) []string {
 			break
 		}
 		if inParens && tokens[i].Token_type != parsers.SC_comma {
-			dataValues = append(dataValues, fmt.Sprintf("%v", tokens[i].Token))
+			if tokens[i].Token_type == parsers.SC_null {
+				dataValues = append(dataValues, nil)
+			} else if tokens[i].Token_type == parsers.SC_default {
+				dataValues = append(dataValues, useDefault)
+			} else {
+				dataValues = append(dataValues, fmt.Sprintf("%v", tokens[i].Token))
+			}
 		}
 	}
 
@@ -456,7 +948,7 @@ func parseDataFromTokens(tokens []parsers.SC_token, startIdx int) []string {
 }
 
 // handleAdd는 ADD 명령을 처리합니다.
-func handleAdd(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
+func handleAdd(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
 	if len(tokens) < 4 {
 		return printError("syntax error: incomplete ADD statement")
 	}
@@ -466,7 +958,7 @@ func handleAdd(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
 		return printError(fmt.Sprintf("error: table '%s' does not exist", tableName))
 	}
 
-	tableData, err := loadTableData(tableName, dbInfo)
+	tableData, err := takeTableData(tableName, dbInfo)
 	if err != nil {
 		return printError(fmt.Sprintf("error: failed to load table: %v", err))
 	}
@@ -477,72 +969,101 @@ func handleAdd(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
 		return printError("error: no data provided")
 	}
 
-	if err := validateDataTypes(dataTokens, tableData.Columns); err != nil {
+	// 생성 키(AUTO_INCREMENT, AUTO_UUID, AUTO_ULID)는 생략할 수 있으며, 생략한 끝 열과 DEFAULT 자리는 열의 기본값으로 채웁니다.
+	dataTokens = expandGeneratedKeys(dataTokens, tableData.Columns)
+	generated := generatesKey(dataTokens, tableData.Columns)
+	if err := fillGeneratedKeys(dataTokens, tableData); err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+	dataTokens, err = fillDefaults(dataTokens, tableData.Columns, true)
+	if err != nil {
 		return printError(fmt.Sprintf("error: %v", err))
 	}
 
-	// 키 열 찾기 및 중복 확인
-	keyCol := findKeyColumn(tableData.Columns)
-	if keyCol == nil {
-		return printError("error: cannot find key column")
+	values, err := validateDataTypes(dataTokens, tableData.Columns)
+	if err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
 	}
 
-	var keyValue string
+	// 새 행 생성
+	newRow := Row{
+		Data: make(map[string]interface{}),
+	}
 	for i, col := range tableData.Columns {
-		if col.Is_key {
-			keyValue = dataTokens[i]
-			break
-		}
+		newRow.Data[col.Name] = values[i]
 	}
 
-	if keyValue == "" {
-		return printError("error: key value cannot be empty")
+	if err := checkRow(tableData.Checks, tableData.Columns, newRow.Data); err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+
+	// 키 확인 및 중복 확인 (복합 키는 모든 키 열의 값으로 구성)
+	if errMsg := checkKeyValues(tableData.Columns, newRow); errMsg != "" {
+		return printError(errMsg)
 	}
+	keyValue, _ := rowKey(tableData.Columns, newRow.Data)
+	newRow.Key = keyValue
 
 	if keyExists(keyValue, tableData) {
 		return printError(fmt.Sprintf("error: key '%s' already exists", keyValue))
 	}
 
-	// 새 행 생성
-	newRow := Row{
-		Key:  keyValue,
-		Data: make(map[string]interface{}),
+	if err := checkUnique(tableData, values, keyValue); err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
 	}
 
-	for i, col := range tableData.Columns {
-		if i < len(dataTokens) {
-			newRow.Data[col.Name] = dataTokens[i]
-		}
+	if err := checkReferences(tableName, tableData, newRow, dbInfo); err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
 	}
 
-	tableData.Rows = append(tableData.Rows, newRow)
-
-	if err := saveTableData(tableData, tableName, dbInfo); err != nil {
+	records := []tableRecord{newDataRecord(newRow)}
+	if err := appendTableRecords(tableData, tableName, dbInfo, records); err != nil {
 		return printError(fmt.Sprintf("error: failed to save table: %v", err))
 	}
+	placeRow(tableData, newRow)
+	reindexUnique(tableData, nil, &newRow)
+	tableData.nextAuto = trackAutoIncrement(tableData.nextAuto, tableData.Columns, records[0])
+
+	if err := compactTableIfNeeded(tableData, tableName, dbInfo); err != nil {
+		return printError(fmt.Sprintf("error: failed to compact table: %v", err))
+	}
+	keepTableData(tableName, tableData, dbInfo)
 
+	if generated {
+		lastGeneratedKey = keyValue
+		fmt.Printf("Data successfully added to table '%s' with generated key '%s'\n", tableName, keyValue)
+		return 0
+	}
 	fmt.Printf("Data successfully added to table '%s'\n", tableName)
 	return 0
 }
 
 // handleUpdate는 UPDATE 명령을 처리합니다.
-func handleUpdate(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
+func handleUpdate(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
 	if len(tokens) < 5 {
 		return printError("syntax error: incomplete UPDATE statement")
 	}
 
 	tableName := tokens[1].Token.(string)
-	keyValue := fmt.Sprintf("%v", tokens[2].Token)
+	rawKey, dataStart, errMsg := keyFromTokens(tokens, 2)
+	if errMsg != "" {
+		return printError(errMsg)
+	}
 
 	if !tableExists(tableName, dbInfo) {
 		return printError(fmt.Sprintf("error: table '%s' does not exist", tableName))
 	}
 
-	tableData, err := loadTableData(tableName, dbInfo)
+	tableData, err := takeTableData(tableName, dbInfo)
 	if err != nil {
 		return printError(fmt.Sprintf("error: failed to load table: %v", err))
 	}
 
+	keyValue, err := canonicalKey(rawKey, tableData.Columns)
+	if err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+
 	// 업데이트할 행 찾기
 	var targetRowIndex int = -1
 	for i, row := range tableData.Rows {
@@ -556,56 +1077,103 @@ func handleUpdate(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
 		return printError(fmt.Sprintf("error: key '%s' not found", keyValue))
 	}
 
-	dataTokens := parseDataFromTokens(tokens, 3)
+	dataTokens := parseDataFromTokens(tokens, dataStart)
 
 	if len(dataTokens) == 0 {
 		return printError("error: no update data provided")
 	}
 
-	if err := validateDataTypes(dataTokens, tableData.Columns); err != nil {
+	if err := fillGeneratedKeys(dataTokens, tableData); err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+	dataTokens, err = fillDefaults(dataTokens, tableData.Columns, false)
+	if err != nil {
 		return printError(fmt.Sprintf("error: %v", err))
 	}
 
-	// 행 데이터 업데이트
+	values, err := validateDataTypes(dataTokens, tableData.Columns)
+	if err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+
+	// 새 버전의 행 구성
+	newRow := Row{
+		Data: make(map[string]interface{}),
+	}
 	for i, col := range tableData.Columns {
-		if i < len(dataTokens) {
-			tableData.Rows[targetRowIndex].Data[col.Name] = dataTokens[i]
+		newRow.Data[col.Name] = values[i]
+	}
+	if err := checkRow(tableData.Checks, tableData.Columns, newRow.Data); err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+	if errMsg := checkKeyValues(tableData.Columns, newRow); errMsg != "" {
+		return printError(errMsg)
+	}
+	newRow.Key, _ = rowKey(tableData.Columns, newRow.Data)
+
+	// 같은 행(이전 키)이 가진 값은 충돌로 보지 않습니다.
+	if err := checkUnique(tableData, values, keyValue); err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+
+	if err := checkReferences(tableName, tableData, newRow, dbInfo); err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+
+	// 새 버전은 같은 키의 이전 버전을 대체합니다.
+	// 키가 바뀌면 이전 키에 삭제 표시를 남깁니다.
+	var records []tableRecord
+	if newRow.Key != keyValue {
+		if keyExists(newRow.Key, tableData) {
+			return printError(fmt.Sprintf("error: key '%s' already exists", newRow.Key))
 		}
+		// 다른 행이 참조하는 키는 바꿀 수 없습니다.
+		if err := checkNotReferenced(tableName, tableData, keyValue, dbInfo); err != nil {
+			return printError(fmt.Sprintf("error: cannot change key: %v", err))
+		}
+		records = append(records, newTombstoneRecord(tableData.Columns, tableData.Rows[targetRowIndex]))
 	}
+	records = append(records, newDataRecord(newRow))
 
-	if err := saveTableData(tableData, tableName, dbInfo); err != nil {
+	if err := appendTableRecords(tableData, tableName, dbInfo, records); err != nil {
 		return printError(fmt.Sprintf("error: failed to save table: %v", err))
 	}
+	// 새 버전은 파일 끝에 기록되었으므로 메모리에서도 끝으로 옮깁니다. (복합 키는 키 순서 자리)
+	oldRow := tableData.Rows[targetRowIndex]
+	tableData.Rows = append(tableData.Rows[:targetRowIndex], tableData.Rows[targetRowIndex+1:]...)
+	placeRow(tableData, newRow)
+	reindexUnique(tableData, &oldRow, &newRow)
+	tableData.nextAuto = trackAutoIncrement(tableData.nextAuto, tableData.Columns, newDataRecord(newRow))
+
+	if err := compactTableIfNeeded(tableData, tableName, dbInfo); err != nil {
+		return printError(fmt.Sprintf("error: failed to compact table: %v", err))
+	}
+	keepTableData(tableName, tableData, dbInfo)
 
 	fmt.Printf("Table '%s' updated successfully\n", tableName)
 	return 0
 }
 
 // handleGet는 GET 명령을 처리합니다.
-func handleGet(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
+func handleGet(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
 	if len(tokens) < 3 {
 		return printError("syntax error: incomplete GET statement")
 	}
 
 	tableName := tokens[1].Token.(string)
-	keyValue := fmt.Sprintf("%v", tokens[2].Token)
+	rawKey, _, errMsg := keyFromTokens(tokens, 2)
+	if errMsg != "" {
+		return printError(errMsg)
+	}
 
 	if !tableExists(tableName, dbInfo) {
 		return printError(fmt.Sprintf("error: table '%s' does not exist", tableName))
 	}
 
-	tableData, err := loadTableData(tableName, dbInfo)
+	// 테이블 전체를 불러오지 않고 키의 마지막 버전만 찾습니다.
+	columns, targetRow, keyValue, err := findRow(tableName, rawKey, dbInfo)
 	if err != nil {
-		return printError(fmt.Sprintf("error: failed to load table: %v", err))
-	}
-
-	// 행 찾기
-	var targetRow *Row
-	for i := range tableData.Rows {
-		if tableData.Rows[i].Key == keyValue {
-			targetRow = &tableData.Rows[i]
-			break
-		}
+		return printError(fmt.Sprintf("error: %v", err))
 	}
 
 	if targetRow == nil {
@@ -614,31 +1182,39 @@ func handleGet(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
 
 	// 결과 표시
 	fmt.Printf("Data for key '%s' in table '%s':\n", keyValue, tableName)
-	for _, col := range tableData.Columns {
-		fmt.Printf("  %s: %v\n", col.Name, targetRow.Data[col.Name])
+	for _, col := range columns {
+		fmt.Printf("  %s: %s\n", col.Name, formatValue(targetRow.Data[col.Name]))
 	}
 
 	return 0
 }
 
 // handleDelete는 DELETE 명령을 처리합니다.
-func handleDelete(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
+func handleDelete(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
 	if len(tokens) < 3 {
 		return printError("syntax error: incomplete DELETE statement")
 	}
 
 	tableName := tokens[1].Token.(string)
-	keyValue := fmt.Sprintf("%v", tokens[2].Token)
+	rawKey, _, errMsg := keyFromTokens(tokens, 2)
+	if errMsg != "" {
+		return printError(errMsg)
+	}
 
 	if !tableExists(tableName, dbInfo) {
 		return printError(fmt.Sprintf("error: table '%s' does not exist", tableName))
 	}
 
-	tableData, err := loadTableData(tableName, dbInfo)
+	tableData, err := takeTableData(tableName, dbInfo)
 	if err != nil {
 		return printError(fmt.Sprintf("error: failed to load table: %v", err))
 	}
 
+	keyValue, err := canonicalKey(rawKey, tableData.Columns)
+	if err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+
 	// 행 찾기 및 제거
 	var targetRowIndex int = -1
 	for i, row := range tableData.Rows {
@@ -652,21 +1228,271 @@ func handleDelete(tokens []parsers.SC_token, dbInfo dbinfo.DB_info) int {
 		return printError(fmt.Sprintf("error: key '%s' not found", keyValue))
 	}
 
-	// 행 제거
-	tableData.Rows = append(tableData.Rows[:targetRowIndex],
-		tableData.Rows[targetRowIndex+1:]...)
+	// 이 행을 참조하는 행에 외래 키의 삭제 동작(RESTRICT, CASCADE, SET NULL)을 적용한 뒤
+	// 바뀌는 모든 테이블에 삭제 표시와 새 버전을 기록합니다.
+	plan := newDeletePlan(tableName, tableData, dbInfo)
+	if err := plan.deleteRow(tableName, tableData.Rows[targetRowIndex]); err != nil {
+		return printError(fmt.Sprintf("error: cannot delete key '%s': %v", keyValue, err))
+	}
+	summary, err := plan.apply()
+	if err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
 
+	fmt.Printf("Row with key '%s' successfully deleted from table '%s'\n",
+		keyValue, tableName)
+	for _, line := range summary {
+		fmt.Printf("  %s\n", line)
+	}
+	return 0
+}
+
+// handleConvertTable은 CONVERT_TABLE 명령을 처리합니다.
+// 테이블 파일을 지정한 형식(text 또는 binary)으로 다시 씁니다.
+func handleConvertTable(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
+	if len(tokens) < 3 {
+		return printError("syntax error: incomplete CONVERT_TABLE statement")
+	}
+
+	if tokens[1].Token_type != parsers.SC_tableName {
+		return printError("syntax error: expected table name")
+	}
+	tableName := tokens[1].Token.(string)
+
+	var format tableFormat
+	switch tokens[2].Token_type {
+	case parsers.SC_columnText:
+		format = formatText
+	case parsers.SC_binary:
+		format = formatBinary
+	default:
+		return printError(fmt.Sprintf("syntax error: unknown table format '%v'", tokens[2].Token))
+	}
+
+	if !tableExists(tableName, dbInfo) {
+		return printError(fmt.Sprintf("error: table '%s' does not exist", tableName))
+	}
+
+	tableData, err := loadTableData(tableName, dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: failed to load table: %v", err))
+	}
+
+	// 전체 다시 쓰기는 임시 파일을 거치므로 변환 중 중단되어도 원래 파일이 남습니다.
+	tableData.storage.format = format
 	if err := saveTableData(tableData, tableName, dbInfo); err != nil {
 		return printError(fmt.Sprintf("error: failed to save table: %v", err))
 	}
+	if err := catalogPutTable(tableName, tableData, dbInfo); err != nil {
+		return printError(fmt.Sprintf("error: failed to update catalog: %v", err))
+	}
 
-	fmt.Printf("Row with key '%s' successfully deleted from table '%s'\n",
-		keyValue, tableName)
+	fmt.Printf("Table '%s' successfully converted to %s format\n", tableName, format)
+	return 0
+}
+
+// handleDropTable은 DROP_TABLE 명령을 처리합니다.
+// 테이블 파일을 지우지 않고 archive 디렉토리로 옮깁니다.
+// 예) archive/users.20250101-120000.tff
+func handleDropTable(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
+	if len(tokens) < 2 || tokens[1].Token_type != parsers.SC_tableName {
+		return printError("syntax error: expected table name")
+	}
+	tableName := tokens[1].Token.(string)
+	if len(tokens) > 2 && tokens[2].Token_type != parsers.SC_endCmd {
+		return printError(fmt.Sprintf("syntax error: unexpected '%v' in DROP_TABLE statement", tokens[2].Token))
+	}
+
+	if !tableExists(tableName, dbInfo) {
+		return printError(fmt.Sprintf("error: table '%s' does not exist", tableName))
+	}
+
+	// 다른 테이블이 참조하는 테이블은 삭제할 수 없습니다.
+	refs, err := referencingColumns(tableName, dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+	for _, ref := range refs {
+		if ref.table != tableName {
+			return printError(fmt.Sprintf("error: table '%s' is referenced by column '%s' of table '%s'",
+				tableName, ref.column.Name, ref.table))
+		}
+	}
+
+	archived, err := newArchivePath(tableName, ".tff", dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: failed to prepare archive: %v", err))
+	}
+	if err := os.Rename(tableFilePath(tableName, dbInfo), archived); err != nil {
+		return printError(fmt.Sprintf("error: failed to move table to archive: %v", err))
+	}
+	// 두 디렉토리의 엔트리 변경을 모두 동기화해야 이동이 유실되지 않습니다.
+	if err := syncDir(tablesDirPath(dbInfo)); err != nil {
+		return printError(fmt.Sprintf("error: failed to sync tables directory: %v", err))
+	}
+	if err := syncDir(archiveDirPath(dbInfo)); err != nil {
+		return printError(fmt.Sprintf("error: failed to sync archive directory: %v", err))
+	}
+	err = updateCatalog(dbInfo, func(cat *catalog) {
+		cat.remove(tableName)
+	})
+	if err != nil {
+		return printError(fmt.Sprintf("error: failed to update catalog: %v", err))
+	}
+
+	fmt.Printf("Table '%s' dropped (archived to '%s')\n", tableName, archived)
+	return 0
+}
+
+// handleRenameTable은 RENAME_TABLE 명령을 처리합니다.
+// 참조하는 테이블의 외래 키를 바꾸고, 파일을 새 이름으로 옮긴 뒤 헤더의 제목을 새 이름으로 다시 씁니다.
+// 각 단계는 다시 실행해도 결과가 같으므로, 파일을 옮긴 뒤 중단된 이름 변경은 WAL 재실행이 나머지 단계를 마칩니다.
+func handleRenameTable(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
+	if len(tokens) < 4 || tokens[1].Token_type != parsers.SC_tableName ||
+		tokens[2].Token_type != parsers.SC_to || tokens[3].Token_type != parsers.SC_tableName {
+		return printError("syntax error: expected RENAME_TABLE [table] TO [new name]")
+	}
+	tableName := tokens[1].Token.(string)
+	newName := tokens[3].Token.(string)
+	if len(tokens) > 4 && tokens[4].Token_type != parsers.SC_endCmd {
+		return printError(fmt.Sprintf("syntax error: unexpected '%v' in RENAME_TABLE statement", tokens[4].Token))
+	}
+
+	// WAL 재실행에서 이전 이름의 테이블이 없고 새 이름의 테이블이 있으면 파일을 옮긴 뒤 중단된 것입니다.
+	// (시작 시 카탈로그 정합이 옮겨진 파일을 새 이름으로 등록합니다.)
+	resume := currentLsn != 0 && !tableExists(tableName, dbInfo) && tableExists(newName, dbInfo)
+	if !resume {
+		if !tableExists(tableName, dbInfo) {
+			return printError(fmt.Sprintf("error: table '%s' does not exist", tableName))
+		}
+		if tableExists(newName, dbInfo) {
+			return printError(fmt.Sprintf("error: table '%s' already exists", newName))
+		}
+		if msg := checkTableFileFree(newName, dbInfo); msg != "" {
+			return printError(msg)
+		}
+	}
+
+	// 이 테이블을 참조하는 다른 테이블의 외래 키를 먼저 새 이름으로 바꿉니다.
+	refs, err := referencingColumns(tableName, dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+	children := make(map[string]*TableData)
+	for _, ref := range refs {
+		if ref.table == tableName || children[ref.table] != nil {
+			continue
+		}
+		child, err := loadTableData(ref.table, dbInfo)
+		if err != nil {
+			return printError(fmt.Sprintf("error: failed to load table '%s': %v", ref.table, err))
+		}
+		renameReferences(child.Columns, tableName, newName)
+		if err := saveTableData(child, ref.table, dbInfo); err != nil {
+			return printError(fmt.Sprintf("error: failed to save table '%s': %v", ref.table, err))
+		}
+		children[ref.table] = child
+	}
+
+	// 이름 변경은 rename 한 번으로 원자적입니다. 제목은 그 뒤에 다시 씁니다.
+	if !resume {
+		if err := os.Rename(tableFilePath(tableName, dbInfo), tableFilePath(newName, dbInfo)); err != nil {
+			return printError(fmt.Sprintf("error: failed to rename table file: %v", err))
+		}
+		if err := syncDir(tablesDirPath(dbInfo)); err != nil {
+			return printError(fmt.Sprintf("error: failed to sync tables directory: %v", err))
+		}
+	}
+
+	tableData, err := loadTableData(newName, dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: failed to load table: %v", err))
+	}
+	renameReferences(tableData.Columns, tableName, newName) // 자기 참조
+	if err := saveTableData(tableData, newName, dbInfo); err != nil {
+		return printError(fmt.Sprintf("error: failed to save table: %v", err))
+	}
+
+	// 생성 시각과 구조 버전은 새 이름으로 이어집니다.
+	err = updateCatalog(dbInfo, func(cat *catalog) {
+		now := time.Now()
+		for name, child := range children {
+			cat.put(name, child, now)
+		}
+		cat.rename(tableName, newName, now)
+		cat.put(newName, tableData, now)
+	})
+	if err != nil {
+		return printError(fmt.Sprintf("error: failed to update catalog: %v", err))
+	}
+
+	fmt.Printf("Table '%s' renamed to '%s'\n", tableName, newName)
+	return 0
+}
+
+// handleTruncate는 TRUNCATE 명령을 처리합니다.
+// 테이블 구조와 파일 형식은 유지하고 모든 행을 지운 파일로 다시 씁니다.
+func handleTruncate(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
+	if len(tokens) < 2 || tokens[1].Token_type != parsers.SC_tableName {
+		return printError("syntax error: expected table name")
+	}
+	tableName := tokens[1].Token.(string)
+	if len(tokens) > 2 && tokens[2].Token_type != parsers.SC_endCmd {
+		return printError(fmt.Sprintf("syntax error: unexpected '%v' in TRUNCATE statement", tokens[2].Token))
+	}
+
+	if !tableExists(tableName, dbInfo) {
+		return printError(fmt.Sprintf("error: table '%s' does not exist", tableName))
+	}
+
+	tableData, err := loadTableData(tableName, dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: failed to load table: %v", err))
+	}
+
+	// 다른 테이블의 행이 참조하는 테이블은 비울 수 없습니다.
+	refs, err := referencingColumns(tableName, dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+	for _, ref := range refs {
+		if ref.table == tableName {
+			continue
+		}
+		child, err := loadTableData(ref.table, dbInfo)
+		if err != nil {
+			return printError(fmt.Sprintf("error: failed to load table '%s': %v", ref.table, err))
+		}
+		for _, row := range child.Rows {
+			if row.Data[ref.column.Name] != nil {
+				return printError(fmt.Sprintf("error: table '%s' is referenced by row '%s' of table '%s' (column '%s')",
+					tableName, row.Key, ref.table, ref.column.Name))
+			}
+		}
+	}
+
+	removed := len(tableData.Rows)
+	tableData.Rows = make([]Row, 0)
+	if err := saveTableData(tableData, tableName, dbInfo); err != nil {
+		return printError(fmt.Sprintf("error: failed to save table: %v", err))
+	}
+
+	fmt.Printf("Table '%s' truncated (%d rows removed)\n", tableName, removed)
 	return 0
 }
 
 // CmdExec은 데이터베이스 명령을 실행합니다.
-func CmdExec(script string, dbInfo dbinfo.DB_info) int {
+// 데이터를 변경하는 명령은 실행 전에 WAL에 먼저 기록됩니다.
+func CmdExec(script string, dbInfo dbinfo.DBInfo) int {
+	execMu.Lock()
+	defer execMu.Unlock()
+	lastGeneratedKey = ""
+	return execScript(script, dbInfo)
+}
+
+// execScript는 execMu를 잡은 상태에서 명령 하나를 실행합니다.
+// 데이터를 변경하는 명령은 명령마다 WAL 레코드를 기록합니다. (MIGRATE가 파일의 명령을 하나씩 실행할 때도 사용)
+func execScript(script string, dbInfo dbinfo.DBInfo) int {
 	// 스크립트를 토큰으로 파싱
 	var scriptTokens []parsers.SC_token
 	if parsers.Parsing_script(script, &scriptTokens) != 0 {
@@ -685,6 +1511,39 @@ func CmdExec(script string, dbInfo dbinfo.DB_info) int {
 		return printError("error: empty script")
 	}
 
+	if !isMutatingCommand(scriptTokens) {
+		return execTokens(scriptTokens, dbInfo)
+	}
+
+	wal, walErr := openWal(dbInfo)
+	if walErr != nil {
+		return printError(fmt.Sprintf("error: failed to open write-ahead log: %v", walErr))
+	}
+
+	lsn, walErr := wal.begin(script)
+	if walErr != nil {
+		return printError(fmt.Sprintf("error: failed to write write-ahead log: %v", walErr))
+	}
+
+	currentLsn = lsn
+	result := execTokens(scriptTokens, dbInfo)
+	currentLsn = 0
+
+	if walErr := wal.finish(lsn, result == 0); walErr != nil {
+		return printError(fmt.Sprintf("error: failed to write write-ahead log: %v", walErr))
+	}
+
+	if wal.needsCheckpoint() {
+		if walErr := wal.checkpoint(); walErr != nil {
+			return printError(fmt.Sprintf("error: failed to checkpoint write-ahead log: %v", walErr))
+		}
+	}
+
+	return result
+}
+
+// execTokens는 파싱된 명령을 첫 번째 토큰에 따라 실행합니다.
+func execTokens(scriptTokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
 	switch scriptTokens[0].Token_type {
 	case parsers.SC_createTable:
 		return handleCreateTable(scriptTokens, dbInfo)
@@ -696,7 +1555,29 @@ func CmdExec(script string, dbInfo dbinfo.DB_info) int {
 		return handleDelete(scriptTokens, dbInfo)
 	case parsers.SC_add:
 		return handleAdd(scriptTokens, dbInfo)
+	case parsers.SC_convertTable:
+		return handleConvertTable(scriptTokens, dbInfo)
+	case parsers.SC_verify:
+		return handleVerify(scriptTokens, dbInfo)
+	case parsers.SC_alterTable:
+		return handleAlterTable(scriptTokens, dbInfo)
+	case parsers.SC_dropTable:
+		return handleDropTable(scriptTokens, dbInfo)
+	case parsers.SC_renameTable:
+		return handleRenameTable(scriptTokens, dbInfo)
+	case parsers.SC_truncate:
+		return handleTruncate(scriptTokens, dbInfo)
+	case parsers.SC_describe:
+		return handleDescribe(scriptTokens, dbInfo)
+	case parsers.SC_showTables:
+		return handleShowTables(scriptTokens, dbInfo)
+	case parsers.SC_showIndexes:
+		return handleShowIndexes(scriptTokens, dbInfo)
+	case parsers.SC_migrate:
+		return handleMigrate(scriptTokens, dbInfo)
+	case parsers.SC_select:
+		return handleSelect(scriptTokens, dbInfo)
 	default:
 		return printError("error: unknown command")
 	}
-}
\ No newline at end of file
+}
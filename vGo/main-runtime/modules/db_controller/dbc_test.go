@@ -0,0 +1,265 @@
+package dbcontroller
+
+import (
+	"os"
+	"path/filepath"
+	dbinfo "sedb/modules/db_info"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newTestDB는 임시 디렉토리에 빈 데이터베이스를 만들고 시작합니다.
+// fixtures는 시작 전에 tables 디렉토리로 복사할 testdata의 테이블 파일 이름입니다.
+func newTestDB(t *testing.T, fixtures ...string) dbinfo.DBInfo {
+	t.Helper()
+
+	contents := make(map[string][]byte, len(fixtures))
+	for _, name := range fixtures {
+		content, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[name] = content
+	}
+
+	t.Chdir(t.TempDir())
+	info := dbinfo.DBInfo{DbName: "testdb"}
+	if err := os.MkdirAll(tablesDirPath(info), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range contents {
+		if err := os.WriteFile(filepath.Join(tablesDirPath(info), name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if Startup(info) != 0 {
+		t.Fatal("startup failed")
+	}
+	return info
+}
+
+// mustExec는 스크립트를 실행하고 성공하지 않으면 테스트를 중단합니다.
+func mustExec(t *testing.T, info dbinfo.DBInfo, script string) {
+	t.Helper()
+	if CmdExec(script, info) != 0 {
+		t.Fatalf("%s: failed", script)
+	}
+}
+
+func TestTextValuesRoundTrip(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (number id NOTNULL KEY, text s);`)
+
+	values := []string{"a, [b]", `say "hi" ->End`, "line\nnext\ttab", `C:\dir\`, "] ->End\nData-> [2, x] ->End"}
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range values {
+		key := strconv.Itoa(i + 1)
+		tableData.Rows = append(tableData.Rows, Row{Key: key, Data: map[string]interface{}{"id": key, "s": v}})
+	}
+	if err := saveTableData(tableData, "t", info); err != nil {
+		t.Fatal(err)
+	}
+
+	tableData, err = loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != len(values) {
+		t.Fatalf("got %d rows, want %d", len(tableData.Rows), len(values))
+	}
+	for i, row := range tableData.Rows {
+		if row.Data["s"] != values[i] {
+			t.Errorf("row %s: got %q, want %q", row.Key, row.Data["s"], values[i])
+		}
+	}
+}
+
+func TestNullAndEmptyString(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (number id NOTNULL KEY, text s, text r NOTNULL);`)
+	mustExec(t, info, `add t (1, NULL, "");`)
+	mustExec(t, info, `add t (2, "", "NULL");`)
+
+	// NOTNULL 열은 NULL만 거부하고 빈 문자열은 받습니다.
+	for _, script := range []string{
+		`add t (3, "a", NULL);`,
+		`add t (NULL, "a", "b");`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(tableData.Rows))
+	}
+	want := []map[string]interface{}{
+		{"s": nil, "r": ""},
+		{"s": "", "r": "NULL"},
+	}
+	for i, row := range tableData.Rows {
+		for name, value := range want[i] {
+			if row.Data[name] != value {
+				t.Errorf("row %s column %s: got %#v, want %#v", row.Key, name, row.Data[name], value)
+			}
+		}
+	}
+}
+
+func TestDropRenameTruncate(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, text name);`)
+	mustExec(t, info, `add t (1, "kim");`)
+	mustExec(t, info, `add t (2, "lee");`)
+
+	mustExec(t, info, `rename_table t to users;`)
+	if tableExists("t", info) {
+		t.Error("old table file still exists after rename")
+	}
+	tableData, err := loadTableData("users", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 2 {
+		t.Errorf("renamed table has %d rows, want 2", len(tableData.Rows))
+	}
+	if !strings.Contains(readTableFile(t, "users", info), `Title : "users"`) {
+		t.Error("renamed table file still has the old title")
+	}
+
+	mustExec(t, info, `truncate users;`)
+	tableData, err = loadTableData("users", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 0 || len(tableData.Columns) != 2 {
+		t.Errorf("truncated table: %d rows, %d columns", len(tableData.Rows), len(tableData.Columns))
+	}
+	mustExec(t, info, `add users (1, "park");`)
+
+	// 삭제한 테이블은 archive로 옮겨지고, 같은 이름으로 다시 만들 수 있습니다.
+	mustExec(t, info, `drop_table users;`)
+	if tableExists("users", info) {
+		t.Error("table file still exists after drop")
+	}
+	archived, _ := filepath.Glob(filepath.Join(info.DbName, "archive", "users.*.tff"))
+	if len(archived) != 1 {
+		t.Fatalf("got %d archived files, want 1", len(archived))
+	}
+	mustExec(t, info, `create_table users (integer id NOTNULL KEY);`)
+
+	for _, script := range []string{
+		`drop_table missing;`,
+		`truncate missing;`,
+		`rename_table missing to other;`,
+		`rename_table users to users;`,
+		`drop_table users extra;`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+}
+
+func TestRenameResumesAfterCrash(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table users (integer id NOTNULL KEY, integer manager REFERENCES users);`)
+	mustExec(t, info, `create_table orders (integer id NOTNULL KEY, integer user_id REFERENCES users);`)
+	mustExec(t, info, `add users (1, NULL);`)
+	mustExec(t, info, `add users (2, 1);`)
+	mustExec(t, info, `add orders (1, 2);`)
+
+	// 참조하는 테이블을 고치고 파일을 옮긴 뒤, 제목과 카탈로그를 고치기 전에 중단된 경우
+	wal, err := openWal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lsn, err := wal.begin(`rename_table users to members;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders, err := loadTableData("orders", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renameReferences(orders.Columns, "users", "members")
+	currentLsn = lsn
+	err = saveTableData(orders, "orders", info)
+	currentLsn = 0
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tableFilePath("users", info), tableFilePath("members", info)); err != nil {
+		t.Fatal(err)
+	}
+
+	if Startup(info) != 0 {
+		t.Fatal("startup failed")
+	}
+	if tableExists("users", info) || !tableExists("members", info) {
+		t.Fatal("catalog does not have the renamed table")
+	}
+	content := readTableFile(t, "members", info)
+	if !strings.Contains(content, `Title : "members"`) || strings.Contains(content, "users") {
+		t.Errorf("renamed table file was not finished:\n%s", content)
+	}
+	mustExec(t, info, `add members (3, 2);`)
+	mustExec(t, info, `add orders (2, 3);`)
+	if CmdExec(`add orders (3, 9);`, info) == 0 {
+		t.Error("foreign key to the renamed table is not checked")
+	}
+}
+
+func TestCompositeKey(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table members (text tenant_id NOTNULL, integer user_id NOTNULL, text name, KEY (tenant_id, user_id));`)
+	mustExec(t, info, `add members ("acme", 42, "kim");`)
+	mustExec(t, info, `add members ("acme", 7, "lee");`)
+	mustExec(t, info, `add members ("beta", 7, "park");`)
+	if CmdExec(`add members ("acme", 42, "choi");`, info) == 0 {
+		t.Error("duplicate key tuple was accepted")
+	}
+
+	mustExec(t, info, `update members ("acme", 42) ("acme", 42, "choi");`)
+	mustExec(t, info, `delete members ("beta", 7);`)
+	mustExec(t, info, `get members ("acme", 7);`)
+
+	// 행은 열 타입에 따른 키 튜플 순서로 놓입니다.
+	tableData, err := loadTableData("members", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantKeys := []string{`("acme", 7)`, `("acme", 42)`}
+	if len(tableData.Rows) != len(wantKeys) {
+		t.Fatalf("got %d rows, want %d", len(tableData.Rows), len(wantKeys))
+	}
+	for i, want := range wantKeys {
+		if tableData.Rows[i].Key != want {
+			t.Errorf("row %d key = %s, want %s", i, tableData.Rows[i].Key, want)
+		}
+	}
+	if tableData.Rows[1].Data["name"] != "choi" {
+		t.Errorf("updated row = %v", tableData.Rows[1].Data)
+	}
+
+	for _, script := range []string{
+		`get members "acme";`,
+		`delete members ("acme", 7, 1);`,
+		`create_table t (text a NOTNULL, integer b, KEY (a, b));`,
+		`create_table t (text a NOTNULL, integer b NOTNULL, KEY (b, a));`,
+		`create_table t (text a NOTNULL KEY, integer b NOTNULL, KEY (a, b));`,
+		`create_table t (text a NOTNULL, integer b NOTNULL, KEY (a, c));`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package dbcontroller
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sedb/modules/parsers"
+	"sedb/modules/table"
+	"strings"
+	"time"
+)
+
+// defaultMarker는 ADD/UPDATE 값 자리의 DEFAULT 키워드입니다.
+type defaultMarker struct{}
+
+// useDefault는 parseDataFromTokens가 DEFAULT 키워드 자리에 넣는 값입니다.
+var useDefault = defaultMarker{}
+
+// defaultFuncs는 DEFAULT에 쓸 수 있는 함수와 그 결과를 받을 수 있는 열 타입입니다.
+var defaultFuncs = map[string]struct {
+	kind  table.Default_kind
+	types []table.Column_type
+}{
+	"now":  {table.DK_now, []table.Column_type{table.CT_timestamp, table.CT_date}},
+	"uuid": {table.DK_uuid, []table.Column_type{table.CT_text}},
+	"ulid": {table.DK_ulid, []table.Column_type{table.CT_text}},
+}
+
+// defaultFuncName은 함수 기본값의 표기 이름을 반환합니다. 함수가 아니면 빈 문자열입니다.
+func defaultFuncName(kind table.Default_kind) string {
+	for name, fn := range defaultFuncs {
+		if fn.kind == kind {
+			return name
+		}
+	}
+	return ""
+}
+
+// checkDefault는 열의 기본값이 열 타입과 제약 조건에 맞는지 확인합니다.
+func checkDefault(col table.Column) error {
+	switch col.Default_kind {
+	case table.DK_none:
+		return nil
+	case table.DK_null:
+		if col.Not_null {
+			return fmt.Errorf("column '%s' is NOTNULL and cannot default to NULL", col.Name)
+		}
+		return nil
+	case table.DK_value:
+		_, err := convertValue(col, col.Default)
+		return err
+	}
+
+	name := defaultFuncName(col.Default_kind)
+	for _, t := range defaultFuncs[name].types {
+		if col.Type == t {
+			return nil
+		}
+	}
+	return fmt.Errorf("default %s() is not allowed for %s column '%s'",
+		strings.ToUpper(name), columnTypeName(col), col.Name)
+}
+
+// parseDefaultClause는 DEFAULT 키워드 다음 tokens[i]부터 기본값을 읽어 col에 설정합니다.
+// 값은 NULL, 리터럴, 또는 NOW(), UUID() 같은 함수입니다. 다음 토큰 위치를 반환합니다.
+func parseDefaultClause(col *table.Column, tokens []parsers.SC_token, i int) (int, string) {
+	if i >= len(tokens) {
+		return i, "syntax error: default value is missing"
+	}
+
+	tok := tokens[i]
+	switch tok.Token_type {
+	case parsers.SC_null:
+		col.Default_kind, col.Default = table.DK_null, ""
+		i++
+	case parsers.SC_func:
+		name := tok.Token.(string)
+		fn, ok := defaultFuncs[name]
+		if !ok {
+			return i, fmt.Sprintf("error: unknown default function '%s'", name)
+		}
+		if i+2 >= len(tokens) || tokens[i+1].Token_type != parsers.SC_parenOpen ||
+			tokens[i+2].Token_type != parsers.SC_parenClose {
+			return i, fmt.Sprintf("syntax error: expected '()' after '%s'", name)
+		}
+		col.Default_kind, col.Default = fn.kind, ""
+		i += 3
+	case parsers.SC_number, parsers.SC_string, parsers.SC_bool:
+		value, err := convertValue(*col, fmt.Sprintf("%v", tok.Token))
+		if err != nil {
+			return i, fmt.Sprintf("error: invalid default value: %v", err)
+		}
+		col.Default_kind, col.Default = table.DK_value, formatValue(value)
+		i++
+	default:
+		return i, fmt.Sprintf("syntax error: invalid default value '%v'", tok.Token)
+	}
+
+	if err := checkDefault(*col); err != nil {
+		return i, fmt.Sprintf("error: %v", err)
+	}
+	return i, ""
+}
+
+// evalDefault는 열의 기본값을 계산합니다. 기본값이 없거나 NULL이면 nil입니다.
+// NOW()는 UTC 기준 현재 시각(DATE 열은 오늘 날짜), UUID()는 새 무작위 UUID, ULID()는 새 ULID입니다.
+func evalDefault(col table.Column) (interface{}, error) {
+	switch col.Default_kind {
+	case table.DK_value:
+		return convertValue(col, col.Default)
+	case table.DK_now:
+		now := time.Now().UTC()
+		if col.Type == table.CT_date {
+			return Date{Year: now.Year(), Month: now.Month(), Day: now.Day()}, nil
+		}
+		return now, nil
+	case table.DK_uuid:
+		return newUUID()
+	case table.DK_ulid:
+		return newULID(time.Now())
+	}
+	return nil, nil
+}
+
+// newUUID는 무작위 UUID(버전 4)를 만듭니다.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // 버전 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 변형
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// crockford는 ULID 표기에 쓰는 Crockford Base32 문자입니다.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID는 ULID를 만듭니다. 앞 48비트는 밀리초 단위 시각, 뒤 80비트는 무작위 값이며
+// 26자의 Crockford Base32로 표기하므로 문자열 순서가 생성 시각 순서와 같습니다.
+func newULID(now time.Time) (string, error) {
+	var b [16]byte
+	ms := uint64(now.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+
+	// 128비트를 앞에서부터 5비트씩 읽습니다. 첫 글자는 남는 상위 3비트입니다.
+	var out [26]byte
+	hi := uint64(b[0])<<56 | uint64(b[1])<<48 | uint64(b[2])<<40 | uint64(b[3])<<32 |
+		uint64(b[4])<<24 | uint64(b[5])<<16 | uint64(b[6])<<8 | uint64(b[7])
+	lo := uint64(b[8])<<56 | uint64(b[9])<<48 | uint64(b[10])<<40 | uint64(b[11])<<32 |
+		uint64(b[12])<<24 | uint64(b[13])<<16 | uint64(b[14])<<8 | uint64(b[15])
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:]), nil
+}
+
+// formatColumnDefault는 TFF 헤더의 DEFAULT 뒤에 기록할 기본값 표기를 반환합니다.
+// 텍스트 값은 데이터 줄과 같이 따옴표로 감쌉니다.
+func formatColumnDefault(col table.Column) string {
+	switch col.Default_kind {
+	case table.DK_null:
+		return "NULL"
+	case table.DK_value:
+		if col.Type == table.CT_text || col.Type == table.CT_json {
+			return parsers.QuoteTffString(col.Default)
+		}
+		return col.Default
+	}
+	return strings.ToUpper(defaultFuncName(col.Default_kind)) + "()"
+}
+
+// defaultFromHeader는 헤더의 DEFAULT 값 토큰을 열에 설정합니다.
+func defaultFromHeader(col *table.Column, token parsers.Tff_token) {
+	switch token.Token_type {
+	case parsers.Tff_null:
+		col.Default_kind = table.DK_null
+	case parsers.Tff_string, parsers.Tff_numeric:
+		col.Default_kind, col.Default = table.DK_value, token.Token.(string)
+	case parsers.Tff_function:
+		if fn, ok := defaultFuncs[token.Token.(string)]; ok {
+			col.Default_kind = fn.kind
+		}
+	}
+}
+
+// fillDefaults는 ADD/UPDATE 값 목록의 DEFAULT 자리를 열의 기본값으로 채웁니다.
+// fillMissing이면 목록 끝에서 생략된 열도 기본값으로 채우며, 기본값이 없는 NOTNULL 열은 생략할 수 없습니다.
+// 기본값이 없는 열의 DEFAULT와 생략은 NULL입니다.
+func fillDefaults(data []interface{}, columns []table.Column, fillMissing bool) ([]interface{}, error) {
+	filled := make([]interface{}, 0, len(columns))
+	for i, value := range data {
+		if value != useDefault {
+			filled = append(filled, value)
+			continue
+		}
+		if i >= len(columns) {
+			return nil, fmt.Errorf("data structure mismatch: expected %d values, received %d values",
+				len(columns), len(data))
+		}
+		value, err := evalDefault(columns[i])
+		if err != nil {
+			return nil, err
+		}
+		filled = append(filled, defaultText(value))
+	}
+
+	if !fillMissing {
+		return filled, nil
+	}
+	for i := len(filled); i < len(columns); i++ {
+		if columns[i].Default_kind == table.DK_none && columns[i].Not_null {
+			return nil, fmt.Errorf("no value for NOTNULL column '%s' and it has no default", columns[i].Name)
+		}
+		value, err := evalDefault(columns[i])
+		if err != nil {
+			return nil, err
+		}
+		filled = append(filled, defaultText(value))
+	}
+	return filled, nil
+}
+
+// defaultText는 계산된 기본값을 validateDataTypes가 받는 값 표기로 바꿉니다.
+func defaultText(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return formatValue(value)
+}
@@ -0,0 +1,101 @@
+package dbcontroller
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestDefaultValues(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table users (
+		text id NOTNULL KEY DEFAULT UUID(),
+		text name NOTNULL DEFAULT "a, b",
+		integer visits NOTNULL DEFAULT 0,
+		timestamp created DEFAULT NOW()
+	);`)
+	before := time.Now().UTC().Add(-time.Second)
+
+	mustExec(t, info, `add users ("u1");`)
+	mustExec(t, info, `add users (DEFAULT, "lee", 3, NULL);`)
+	mustExec(t, info, `update users "u1" ("u1", "kim", DEFAULT, DEFAULT);`)
+
+	// 헤더를 다시 읽어도 기본값이 그대로 남아 있습니다.
+	tableData, err := loadTableData("users", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(tableData.Rows))
+	}
+	if col := tableData.Columns[1]; formatColumnDefault(col) != `"a, b"` {
+		t.Errorf("name default = %s, want \"a, b\"", formatColumnDefault(col))
+	}
+
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for _, row := range tableData.Rows {
+		if row.Key == "u1" {
+			if row.Data["name"] != "kim" || row.Data["visits"] != int64(0) {
+				t.Errorf("u1 = %v", row.Data)
+			}
+			created, ok := row.Data["created"].(time.Time)
+			if !ok || created.Before(before) {
+				t.Errorf("u1 created = %v, want the current time", row.Data["created"])
+			}
+			continue
+		}
+		if !uuidPattern.MatchString(row.Key) {
+			t.Errorf("generated key %q is not a UUID", row.Key)
+		}
+		if row.Data["visits"] != int64(3) || row.Data["created"] != nil {
+			t.Errorf("%s = %v", row.Key, row.Data)
+		}
+	}
+}
+
+func TestDefaultValueErrors(t *testing.T) {
+	info := newTestDB(t)
+	for _, script := range []string{
+		`create_table t (integer id NOTNULL KEY, integer n DEFAULT "a");`,
+		`create_table t (integer id NOTNULL KEY, integer n NOTNULL DEFAULT NULL);`,
+		`create_table t (integer id NOTNULL KEY, integer n DEFAULT NOW());`,
+		`create_table t (integer id NOTNULL KEY, text n DEFAULT RANDOM());`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, text name NOTNULL, integer n DEFAULT 1);`)
+	mustExec(t, info, `add t (1, "kim");`)
+	for _, script := range []string{
+		`add t (2);`, // name은 기본값이 없는 NOTNULL 열
+		`add t (DEFAULT, "lee");`,
+		`update t 1 (1, "kim");`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+}
+
+func TestOmittedNullableColumnIsNull(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, text memo, integer n DEFAULT 1);`)
+	mustExec(t, info, `add t (1);`)
+	mustExec(t, info, `add t (2, "note");`)
+
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(tableData.Rows))
+	}
+	for _, row := range tableData.Rows {
+		want := map[string]interface{}{"1": nil, "2": "note"}[row.Key]
+		if row.Data["memo"] != want || row.Data["n"] != int64(1) {
+			t.Errorf("row %s = %v", row.Key, row.Data)
+		}
+	}
+}
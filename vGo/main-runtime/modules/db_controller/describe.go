@@ -0,0 +1,315 @@
+package dbcontroller
+
+import (
+	"fmt"
+	"os"
+	dbinfo "sedb/modules/db_info"
+	"sedb/modules/parsers"
+	"sedb/modules/table"
+	"strings"
+	"text/tabwriter"
+)
+
+// TableInfo는 SHOW_TABLES가 반환하는 테이블 하나의 정보입니다.
+type TableInfo struct {
+	Name     string `json:"name"`
+	Version  int    `json:"version"` // 구조 버전 (카탈로그)
+	Rows     int    `json:"rows"`    // 살아 있는 행 수
+	Format   string `json:"format"`  // 파일 형식: text 또는 binary
+	FileSize int64  `json:"file_size"`
+	Created  string `json:"created"`
+	Altered  string `json:"altered"`
+
+	Unreadable string `json:"unreadable,omitempty"` // 테이블 파일을 읽을 수 없을 때의 오류
+}
+
+// ColumnInfo는 DESCRIBE가 반환하는 열 하나의 정의입니다.
+type ColumnInfo struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"` // 예) INTEGER, DECIMAL(2)
+	Key        bool   `json:"key"`
+	NotNull    bool   `json:"not_null"`
+	Unique     bool   `json:"unique"`
+	Generated  string `json:"generated,omitempty"`  // AUTO_INCREMENT, AUTO_UUID, AUTO_ULID
+	Default    string `json:"default,omitempty"`    // 기본값 표기, 예) "guest", 0, NULL, NOW()
+	References string `json:"references,omitempty"` // 외래 키가 참조하는 테이블
+	OnDelete   string `json:"on_delete,omitempty"`  // 외래 키의 삭제 동작: RESTRICT, CASCADE, SET_NULL
+}
+
+// CheckInfo는 DESCRIBE가 반환하는 CHECK 제약입니다.
+type CheckInfo struct {
+	Name string `json:"name"`
+	Expr string `json:"expr"`
+}
+
+// TableDescription은 DESCRIBE의 결과입니다.
+type TableDescription struct {
+	TableInfo
+	Columns []ColumnInfo `json:"columns"`
+	Checks  []CheckInfo  `json:"checks"`
+}
+
+// IndexInfo는 SHOW_INDEXES가 반환하는 인덱스 하나의 정보입니다.
+// 테이블마다 행 키의 KEY 인덱스가 있고, UNIQUE 열마다 UNIQUE 인덱스가 있습니다.
+type IndexInfo struct {
+	Table   string   `json:"table"`
+	Name    string   `json:"name"`
+	Kind    string   `json:"kind"` // KEY 또는 UNIQUE
+	Columns []string `json:"columns"`
+}
+
+// ShowTables는 데이터베이스의 테이블 목록을 이름 순서로 반환합니다.
+func ShowTables(dbInfo dbinfo.DBInfo) ([]TableInfo, error) {
+	execMu.Lock()
+	defer execMu.Unlock()
+	return showTables(dbInfo)
+}
+
+// DescribeTable은 테이블의 열 정의, CHECK 제약, 행 수, 파일 크기를 반환합니다.
+func DescribeTable(tableName string, dbInfo dbinfo.DBInfo) (*TableDescription, error) {
+	execMu.Lock()
+	defer execMu.Unlock()
+	return describeTable(tableName, dbInfo)
+}
+
+// ShowIndexes는 테이블의 인덱스를 반환합니다. tableName이 빈 문자열이면 모든 테이블의 인덱스입니다.
+func ShowIndexes(tableName string, dbInfo dbinfo.DBInfo) ([]IndexInfo, error) {
+	execMu.Lock()
+	defer execMu.Unlock()
+	return showIndexes(tableName, dbInfo)
+}
+
+// tableInfo는 카탈로그 항목과 테이블 파일로 TableInfo를 만듭니다.
+// 행 수는 카탈로그에 기록하지 않으므로 보관한 테이블 데이터에서, 없으면 테이블 파일을 읽어 셉니다.
+func tableInfo(entry catalogEntry, dbInfo dbinfo.DBInfo) TableInfo {
+	info := TableInfo{
+		Name:    entry.Name,
+		Version: entry.Version,
+		Format:  entry.Options.Format,
+		Created: entry.Created,
+		Altered: entry.Altered,
+
+		Unreadable: entry.Unreadable,
+	}
+	if stat, err := os.Stat(tableFilePath(entry.Name, dbInfo)); err == nil {
+		info.FileSize = stat.Size()
+	}
+	if entry.Unreadable == "" {
+		if tableData, err := takeTableData(entry.Name, dbInfo); err == nil {
+			info.Rows = len(tableData.Rows)
+			keepTableData(entry.Name, tableData, dbInfo)
+		}
+	}
+	return info
+}
+
+func showTables(dbInfo dbinfo.DBInfo) ([]TableInfo, error) {
+	cat, err := loadCatalog(dbInfo)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]TableInfo, 0, len(cat.Tables))
+	for _, entry := range cat.Tables {
+		tables = append(tables, tableInfo(entry, dbInfo))
+	}
+	return tables, nil
+}
+
+func describeTable(tableName string, dbInfo dbinfo.DBInfo) (*TableDescription, error) {
+	cat, err := loadCatalog(dbInfo)
+	if err != nil {
+		return nil, err
+	}
+	entry := cat.find(tableName)
+	if entry == nil {
+		return nil, fmt.Errorf("table '%s' does not exist", tableName)
+	}
+
+	// 열 정의는 테이블 파일의 헤더만 읽습니다.
+	reader, file, err := openTableReader(tableName, dbInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	desc := &TableDescription{
+		TableInfo: tableInfo(*entry, dbInfo),
+		Columns:   make([]ColumnInfo, 0, len(reader.columns())),
+		Checks:    make([]CheckInfo, 0, len(reader.checks())),
+	}
+	for _, col := range reader.columns() {
+		desc.Columns = append(desc.Columns, columnInfo(col))
+	}
+	for _, check := range reader.checks() {
+		desc.Checks = append(desc.Checks, CheckInfo{Name: check.Name, Expr: check.Expr})
+	}
+	return desc, nil
+}
+
+// columnInfo는 열 정의를 DESCRIBE의 결과 형태로 바꿉니다.
+func columnInfo(col table.Column) ColumnInfo {
+	info := ColumnInfo{
+		Name:       col.Name,
+		Type:       columnTypeName(col),
+		Key:        col.Is_key,
+		NotNull:    col.Not_null,
+		Unique:     col.Unique,
+		Generated:  strings.ToUpper(generatedName(col.Generated)),
+		References: col.References,
+	}
+	if col.Default_kind != table.DK_none {
+		info.Default = formatColumnDefault(col)
+	}
+	if col.References != "" {
+		info.OnDelete = refActionName(col.On_delete)
+	}
+	return info
+}
+
+func showIndexes(tableName string, dbInfo dbinfo.DBInfo) ([]IndexInfo, error) {
+	cat, err := loadCatalog(dbInfo)
+	if err != nil {
+		return nil, err
+	}
+	names := cat.names()
+	if tableName != "" {
+		if cat.find(tableName) == nil {
+			return nil, fmt.Errorf("table '%s' does not exist", tableName)
+		}
+		names = []string{tableName}
+	}
+
+	indexes := make([]IndexInfo, 0)
+	for _, name := range names {
+		columns, err := tableColumns(name, dbInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read table '%s': %v", name, err)
+		}
+
+		key := IndexInfo{Table: name, Name: name + "_key", Kind: "KEY"}
+		for _, col := range columns {
+			if col.Is_key {
+				key.Columns = append(key.Columns, col.Name)
+			}
+		}
+		if len(key.Columns) > 0 {
+			indexes = append(indexes, key)
+		}
+		for _, col := range columns {
+			if col.Unique {
+				indexes = append(indexes, IndexInfo{
+					Table:   name,
+					Name:    name + "_" + col.Name + "_unique",
+					Kind:    "UNIQUE",
+					Columns: []string{col.Name},
+				})
+			}
+		}
+	}
+	return indexes, nil
+}
+
+// handleDescribe는 DESCRIBE 명령을 처리합니다.
+// 문법: describe [테이블 이름];
+func handleDescribe(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
+	if len(tokens) < 2 || tokens[1].Token_type != parsers.SC_tableName {
+		return printError("syntax error: expected table name")
+	}
+	tableName := tokens[1].Token.(string)
+	if len(tokens) > 2 && tokens[2].Token_type != parsers.SC_endCmd {
+		return printError(fmt.Sprintf("syntax error: unexpected '%v' in DESCRIBE statement", tokens[2].Token))
+	}
+
+	desc, err := describeTable(tableName, dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+
+	fmt.Printf("Table '%s' (version %d, %d rows, %s format, %d bytes)\n",
+		desc.Name, desc.Version, desc.Rows, desc.Format, desc.FileSize)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  COLUMN\tTYPE\tKEY\tNULL\tDEFAULT\tEXTRA")
+	for _, col := range desc.Columns {
+		key, null := "", "YES"
+		if col.Key {
+			key = "KEY"
+		}
+		if col.NotNull {
+			null = "NO"
+		}
+		var extra []string
+		if col.Unique {
+			extra = append(extra, "UNIQUE")
+		}
+		if col.Generated != "" {
+			extra = append(extra, col.Generated)
+		}
+		if col.References != "" {
+			extra = append(extra, "REFERENCES "+col.References+" ON_DELETE "+col.OnDelete)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\n", col.Name, col.Type, key, null, col.Default, strings.Join(extra, " "))
+	}
+	w.Flush()
+	for _, check := range desc.Checks {
+		fmt.Printf("  CHECK %s: %s\n", check.Name, check.Expr)
+	}
+	return 0
+}
+
+// handleShowTables는 SHOW_TABLES 명령을 처리합니다.
+// 문법: show_tables;
+func handleShowTables(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
+	if len(tokens) > 1 && tokens[1].Token_type != parsers.SC_endCmd {
+		return printError(fmt.Sprintf("syntax error: unexpected '%v' in SHOW_TABLES statement", tokens[1].Token))
+	}
+
+	tables, err := showTables(dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+
+	fmt.Printf("Tables in database '%s' (%d):\n", dbInfo.DbName, len(tables))
+	if len(tables) == 0 {
+		return 0
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  TABLE\tROWS\tFORMAT\tSIZE\tVERSION\tCREATED\tALTERED")
+	for _, t := range tables {
+		format := t.Format
+		if t.Unreadable != "" {
+			format = "unreadable"
+		}
+		fmt.Fprintf(w, "  %s\t%d\t%s\t%d\t%d\t%s\t%s\n", t.Name, t.Rows, format, t.FileSize, t.Version, t.Created, t.Altered)
+	}
+	w.Flush()
+	return 0
+}
+
+// handleShowIndexes는 SHOW_INDEXES 명령을 처리합니다. 테이블 이름을 생략하면 모든 테이블의 인덱스를 보여줍니다.
+// 문법: show_indexes [테이블 이름](선택);
+func handleShowIndexes(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
+	tableName, next := "", 1
+	if len(tokens) > 1 && tokens[1].Token_type == parsers.SC_tableName {
+		tableName, next = tokens[1].Token.(string), 2
+	}
+	if len(tokens) > next && tokens[next].Token_type != parsers.SC_endCmd {
+		return printError(fmt.Sprintf("syntax error: unexpected '%v' in SHOW_INDEXES statement", tokens[next].Token))
+	}
+
+	indexes, err := showIndexes(tableName, dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+
+	fmt.Printf("Indexes (%d):\n", len(indexes))
+	if len(indexes) == 0 {
+		return 0
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  TABLE\tINDEX\tKIND\tCOLUMNS")
+	for _, idx := range indexes {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", idx.Table, idx.Name, idx.Kind, strings.Join(idx.Columns, ", "))
+	}
+	w.Flush()
+	return 0
+}
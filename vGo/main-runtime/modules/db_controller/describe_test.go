@@ -0,0 +1,93 @@
+package dbcontroller
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDescribeTable(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table users (
+		integer id NOTNULL KEY AUTO_INCREMENT,
+		text email NOTNULL UNIQUE,
+		text name DEFAULT "guest",
+		CHECK (email != "")
+	);`)
+	mustExec(t, info, `create_table orders (integer id NOTNULL KEY, integer user_id REFERENCES users ON DELETE CASCADE);`)
+	mustExec(t, info, `add users ("kim@example.com");`)
+
+	desc, err := DescribeTable("users", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Name != "users" || desc.Rows != 1 || desc.Version != 1 || desc.Format != "text" || desc.FileSize == 0 {
+		t.Errorf("table info = %+v", desc.TableInfo)
+	}
+	want := []ColumnInfo{
+		{Name: "id", Type: "INTEGER", Key: true, NotNull: true, Generated: "AUTO_INCREMENT"},
+		{Name: "email", Type: "TEXT", NotNull: true, Unique: true},
+		{Name: "name", Type: "TEXT", Default: `"guest"`},
+	}
+	if !slices.Equal(desc.Columns, want) {
+		t.Errorf("columns = %+v, want %+v", desc.Columns, want)
+	}
+	if len(desc.Checks) != 1 || desc.Checks[0] != (CheckInfo{Name: "users_check", Expr: `email != ""`}) {
+		t.Errorf("checks = %+v", desc.Checks)
+	}
+
+	orders, err := DescribeTable("orders", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if col := orders.Columns[1]; col.References != "users" || col.OnDelete != "CASCADE" {
+		t.Errorf("user_id = %+v", col)
+	}
+
+	if _, err := DescribeTable("missing", info); err == nil {
+		t.Error("described a missing table")
+	}
+	for _, script := range []string{`describe users;`, `show_tables;`, `show_indexes;`, `show_indexes users;`} {
+		mustExec(t, info, script)
+	}
+	for _, script := range []string{`describe missing;`, `show_indexes missing;`, `describe;`} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+}
+
+func TestShowTablesAndIndexes(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table members (text tenant NOTNULL, integer id NOTNULL, text email UNIQUE, KEY (tenant, id));`)
+	mustExec(t, info, `create_table audit (integer id NOTNULL KEY);`)
+
+	tables, err := ShowTables(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 || tables[0].Name != "audit" || tables[1].Name != "members" {
+		t.Fatalf("tables = %+v, want audit and members", tables)
+	}
+
+	indexes, err := ShowIndexes("members", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indexes) != 2 {
+		t.Fatalf("indexes = %+v", indexes)
+	}
+	if indexes[0].Name != "members_key" || indexes[0].Kind != "KEY" || !slices.Equal(indexes[0].Columns, []string{"tenant", "id"}) {
+		t.Errorf("key index = %+v", indexes[0])
+	}
+	if indexes[1].Name != "members_email_unique" || indexes[1].Kind != "UNIQUE" || !slices.Equal(indexes[1].Columns, []string{"email"}) {
+		t.Errorf("unique index = %+v", indexes[1])
+	}
+
+	all, err := ShowIndexes("", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("all indexes = %+v", all)
+	}
+}
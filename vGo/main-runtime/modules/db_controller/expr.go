@@ -0,0 +1,332 @@
+package dbcontroller
+
+import (
+	"fmt"
+	"math/big"
+	"sedb/modules/parsers"
+	"sedb/modules/table"
+	"strconv"
+)
+
+// truth는 조건식의 3값 논리 결과입니다. NULL이 섞인 비교는 unknown입니다.
+type truth int
+
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown
+)
+
+func truthOf(b bool) truth {
+	if b {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+// valueClass는 서로 비교할 수 있는 열 타입의 묶음입니다.
+// 정수, 실수, 고정 소수점은 모두 숫자로 비교하며, JSON은 문자열로 비교합니다.
+func valueClass(t table.Column_type) string {
+	switch t {
+	case table.CT_integer, table.CT_float, table.CT_number, table.CT_decimal:
+		return "number"
+	case table.CT_text, table.CT_json:
+		return "text"
+	case table.CT_bool:
+		return "bool"
+	case table.CT_date:
+		return "date"
+	case table.CT_timestamp:
+		return "timestamp"
+	case table.CT_blob:
+		return "blob"
+	}
+	return ""
+}
+
+// exprColumn은 조건식의 피연산자가 열 참조이면 그 열을 반환합니다.
+func exprColumn(e *parsers.Expr, columns []table.Column) (table.Column, bool) {
+	if e.Kind != parsers.EX_column {
+		return table.Column{}, false
+	}
+	idx := findColumnIndex(columns, e.Name)
+	if idx < 0 {
+		return table.Column{}, false
+	}
+	return columns[idx], true
+}
+
+// isPredicate는 조건식 e가 참/거짓을 내는 식인지 확인합니다. BOOL 열과 true/false 값도 조건이 됩니다.
+func isPredicate(e *parsers.Expr, columns []table.Column) bool {
+	switch e.Kind {
+	case parsers.EX_compare, parsers.EX_and, parsers.EX_or, parsers.EX_not, parsers.EX_in, parsers.EX_isNull:
+		return true
+	case parsers.EX_column:
+		col, ok := exprColumn(e, columns)
+		return ok && col.Type == table.CT_bool
+	case parsers.EX_literal:
+		return e.Value.Token_type == parsers.SC_bool || e.Value.Token_type == parsers.SC_null
+	}
+	return false
+}
+
+// bindCondition은 조건식을 테이블의 열에 맞춰 검사합니다.
+// 없는 열, 조건이 아닌 식, 열 타입으로 변환할 수 없는 값, 비교할 수 없는 타입의 비교를 오류로 반환합니다.
+func bindCondition(e *parsers.Expr, columns []table.Column) error {
+	if !isPredicate(e, columns) {
+		return fmt.Errorf("'%s' is not a condition", parsers.FormatExpr(e))
+	}
+	return bindExpr(e, columns)
+}
+
+func bindExpr(e *parsers.Expr, columns []table.Column) error {
+	switch e.Kind {
+	case parsers.EX_column:
+		if findColumnIndex(columns, e.Name) < 0 {
+			return fmt.Errorf("column '%s' does not exist", e.Name)
+		}
+	case parsers.EX_and, parsers.EX_or:
+		if err := bindCondition(e.Left, columns); err != nil {
+			return err
+		}
+		return bindCondition(e.Right, columns)
+	case parsers.EX_not:
+		return bindCondition(e.Left, columns)
+	case parsers.EX_compare:
+		return bindComparison(e.Left, e.Right, columns)
+	case parsers.EX_in:
+		for _, item := range e.List {
+			if err := bindComparison(e.Left, item, columns); err != nil {
+				return err
+			}
+		}
+	case parsers.EX_isNull:
+		return bindExpr(e.Left, columns)
+	case parsers.EX_aggregate:
+		return fmt.Errorf("aggregate %s is not allowed here", parsers.FormatExpr(e))
+	}
+	return nil
+}
+
+// bindComparison은 두 피연산자를 비교할 수 있는지 확인합니다.
+func bindComparison(left, right *parsers.Expr, columns []table.Column) error {
+	if err := bindExpr(left, columns); err != nil {
+		return err
+	}
+	if err := bindExpr(right, columns); err != nil {
+		return err
+	}
+
+	lcol, lok := exprColumn(left, columns)
+	rcol, rok := exprColumn(right, columns)
+	switch {
+	case lok && rok:
+		if valueClass(lcol.Type) != valueClass(rcol.Type) {
+			return fmt.Errorf("cannot compare column '%s' (%s) with column '%s' (%s)",
+				lcol.Name, columnTypeName(lcol), rcol.Name, columnTypeName(rcol))
+		}
+	case lok:
+		_, err := operandValue(right, &lcol, nil)
+		return err
+	case rok:
+		_, err := operandValue(left, &rcol, nil)
+		return err
+	}
+	return nil
+}
+
+// operandValue는 피연산자의 값을 구합니다. 열 참조는 data의 값이고,
+// 값은 비교 상대인 열 col의 타입으로 변환합니다. 상대가 열이 아니면 값의 표기대로 변환합니다.
+func operandValue(e *parsers.Expr, col *table.Column, data map[string]interface{}) (interface{}, error) {
+	if e.Kind == parsers.EX_column {
+		return data[e.Name], nil
+	}
+
+	tok := e.Value
+	switch tok.Token_type {
+	case parsers.SC_null:
+		return nil, nil
+	case parsers.SC_bool:
+		if col != nil && col.Type != table.CT_bool {
+			return nil, fmt.Errorf("cannot compare %s with column '%s' (%s)", tok.Token, col.Name, columnTypeName(*col))
+		}
+		return tok.Token.(string) == "true", nil
+	}
+	raw := fmt.Sprintf("%v", tok.Token)
+	if col != nil {
+		return convertValue(*col, raw)
+	}
+	if tok.Token_type == parsers.SC_number {
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s' in condition", raw)
+		}
+		return f, nil
+	}
+	return raw, nil
+}
+
+// compareOperands는 두 값을 비교합니다. 타입이 다른 숫자(정수, 실수, 고정 소수점)는 값으로 비교합니다.
+func compareOperands(a, b interface{}) int {
+	if x, ok := numericRat(a); ok {
+		if y, ok := numericRat(b); ok {
+			return x.Cmp(y)
+		}
+	}
+	return compareValues(a, b)
+}
+
+// numericRat은 숫자 값을 정확한 유리수로 변환합니다.
+func numericRat(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	case float64:
+		r := new(big.Rat)
+		if r.SetFloat64(n) == nil {
+			return nil, false
+		}
+		return r, true
+	case Decimal:
+		return new(big.Rat).SetString(n.String())
+	}
+	return nil, false
+}
+
+// evalCondition은 행 데이터 data에 대해 조건식을 계산합니다.
+// NULL과의 비교는 unknown이며 AND, OR, NOT은 SQL의 3값 논리를 따릅니다.
+func evalCondition(e *parsers.Expr, columns []table.Column, data map[string]interface{}) (truth, error) {
+	switch e.Kind {
+	case parsers.EX_and:
+		left, err := evalCondition(e.Left, columns, data)
+		if err != nil || left == truthFalse {
+			return left, err
+		}
+		right, err := evalCondition(e.Right, columns, data)
+		if err != nil || right == truthFalse {
+			return right, err
+		}
+		if left == truthUnknown || right == truthUnknown {
+			return truthUnknown, nil
+		}
+		return truthTrue, nil
+	case parsers.EX_or:
+		left, err := evalCondition(e.Left, columns, data)
+		if err != nil || left == truthTrue {
+			return left, err
+		}
+		right, err := evalCondition(e.Right, columns, data)
+		if err != nil || right == truthTrue {
+			return right, err
+		}
+		if left == truthUnknown || right == truthUnknown {
+			return truthUnknown, nil
+		}
+		return truthFalse, nil
+	case parsers.EX_not:
+		inner, err := evalCondition(e.Left, columns, data)
+		if err != nil || inner == truthUnknown {
+			return inner, err
+		}
+		return truthOf(inner == truthFalse), nil
+	case parsers.EX_compare:
+		c, known, err := compareExpr(e.Left, e.Right, columns, data)
+		if err != nil || !known {
+			return truthUnknown, err
+		}
+		switch e.Op {
+		case "=":
+			return truthOf(c == 0), nil
+		case "!=":
+			return truthOf(c != 0), nil
+		case "<":
+			return truthOf(c < 0), nil
+		case "<=":
+			return truthOf(c <= 0), nil
+		case ">":
+			return truthOf(c > 0), nil
+		case ">=":
+			return truthOf(c >= 0), nil
+		}
+		return truthUnknown, fmt.Errorf("unknown operator '%s'", e.Op)
+	case parsers.EX_in:
+		result := truthFalse
+		for _, item := range e.List {
+			c, known, err := compareExpr(e.Left, item, columns, data)
+			if err != nil {
+				return truthUnknown, err
+			}
+			if !known {
+				result = truthUnknown
+				continue
+			}
+			if c == 0 {
+				result = truthTrue
+				break
+			}
+		}
+		if e.Negated && result != truthUnknown {
+			return truthOf(result == truthFalse), nil
+		}
+		return result, nil
+	case parsers.EX_isNull:
+		value, err := operandValue(e.Left, nil, data)
+		if err != nil {
+			return truthUnknown, err
+		}
+		return truthOf((value == nil) != e.Negated), nil
+	}
+
+	// BOOL 열 또는 true/false 값
+	value, err := operandValue(e, nil, data)
+	if err != nil {
+		return truthUnknown, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return truthUnknown, nil
+	}
+	return truthOf(b), nil
+}
+
+// compareExpr는 두 피연산자의 값을 비교합니다. 어느 한쪽이 NULL이면 known은 false입니다.
+func compareExpr(left, right *parsers.Expr, columns []table.Column, data map[string]interface{}) (int, bool, error) {
+	var lcol, rcol *table.Column
+	if col, ok := exprColumn(left, columns); ok {
+		lcol = &col
+	}
+	if col, ok := exprColumn(right, columns); ok {
+		rcol = &col
+	}
+
+	a, err := operandValue(left, rcol, data)
+	if err != nil {
+		return 0, false, err
+	}
+	b, err := operandValue(right, lcol, data)
+	if err != nil {
+		return 0, false, err
+	}
+	if a == nil || b == nil {
+		return 0, false, nil
+	}
+	return compareOperands(a, b), true, nil
+}
+
+// renameExprColumn은 조건식의 열 참조 from을 to로 바꿉니다.
+func renameExprColumn(e *parsers.Expr, from, to string) {
+	if e == nil {
+		return
+	}
+	if e.Kind == parsers.EX_column && e.Name == from {
+		e.Name = to
+	}
+	renameExprColumn(e.Left, from, to)
+	renameExprColumn(e.Right, from, to)
+	for _, item := range e.List {
+		renameExprColumn(item, from, to)
+	}
+}
@@ -0,0 +1,364 @@
+package dbcontroller
+
+import (
+	"fmt"
+	dbinfo "sedb/modules/db_info"
+	"sedb/modules/parsers"
+	"sedb/modules/table"
+	"strings"
+)
+
+// refActions는 헤더의 ON_DELETE 표기 -> 삭제 동작입니다.
+var refActions = map[string]table.Ref_action{
+	"RESTRICT": table.RA_restrict,
+	"CASCADE":  table.RA_cascade,
+	"SET_NULL": table.RA_setNull,
+}
+
+// refActionName은 삭제 동작의 헤더 표기를 반환합니다.
+func refActionName(action table.Ref_action) string {
+	for name, a := range refActions {
+		if a == action {
+			return name
+		}
+	}
+	return ""
+}
+
+// parseReferencesClause는 tokens[i]의 REFERENCES부터 외래 키 절을 읽습니다.
+// 문법: REFERENCES [테이블 이름] ON DELETE RESTRICT|CASCADE|SET NULL(선택, 기본값 RESTRICT)
+func parseReferencesClause(tokens []parsers.SC_token, i int) (string, table.Ref_action, int, string) {
+	i++
+	if i >= len(tokens) || tokens[i].Token_type != parsers.SC_tableName {
+		return "", table.RA_restrict, i, "syntax error: referenced table name is missing"
+	}
+	parent := tokens[i].Token.(string)
+	i++
+
+	if i >= len(tokens) || tokens[i].Token_type != parsers.SC_on {
+		return parent, table.RA_restrict, i, ""
+	}
+	if i+1 >= len(tokens) || tokens[i+1].Token_type != parsers.SC_delete {
+		return "", table.RA_restrict, i, "syntax error: expected DELETE after ON"
+	}
+	i += 2
+
+	switch {
+	case i < len(tokens) && tokens[i].Token_type == parsers.SC_refAction:
+		return parent, refActions[strings.ToUpper(tokens[i].Token.(string))], i + 1, ""
+	case i+1 < len(tokens) && tokens[i].Token_type == parsers.SC_set && tokens[i+1].Token_type == parsers.SC_null:
+		return parent, table.RA_setNull, i + 2, ""
+	}
+	return "", table.RA_restrict, i, "syntax error: expected RESTRICT, CASCADE or SET NULL after ON DELETE"
+}
+
+// tableColumns는 테이블 파일의 헤더에서 열 정의만 읽습니다.
+func tableColumns(tableName string, dbInfo dbinfo.DBInfo) ([]table.Column, error) {
+	reader, file, err := openTableReader(tableName, dbInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return reader.columns(), nil
+}
+
+// checkReferenceTarget은 외래 키 열 col이 참조할 수 있는 테이블을 가리키는지 확인합니다.
+// 참조하는 테이블은 단일 KEY 열을 가져야 하며, 그 키 열과 col의 타입이 같아야 합니다.
+// tableName이 자신이면(자기 참조) columns에서 키 열을 찾습니다.
+func checkReferenceTarget(col table.Column, tableName string, columns []table.Column, dbInfo dbinfo.DBInfo) error {
+	parentColumns := columns
+	if col.References != tableName {
+		if !tableExists(col.References, dbInfo) {
+			return fmt.Errorf("referenced table '%s' does not exist", col.References)
+		}
+		var err error
+		parentColumns, err = tableColumns(col.References, dbInfo)
+		if err != nil {
+			return fmt.Errorf("failed to read referenced table '%s': %v", col.References, err)
+		}
+	}
+
+	keys := keyColumns(parentColumns)
+	if len(keys) != 1 {
+		return fmt.Errorf("table '%s' has a composite key; REFERENCES needs a single KEY column", col.References)
+	}
+	if columnTypeName(keys[0]) != columnTypeName(col) {
+		return fmt.Errorf("column '%s' (%s) cannot reference key '%s' of table '%s' (%s)",
+			col.Name, columnTypeName(col), keys[0].Name, col.References, columnTypeName(keys[0]))
+	}
+	if col.On_delete == table.RA_setNull && col.Not_null {
+		return fmt.Errorf("column '%s' is NOTNULL and cannot use ON DELETE SET NULL", col.Name)
+	}
+	return nil
+}
+
+// referenceExists는 외래 키 열의 값 value를 키로 가진 행이 참조하는 테이블에 있는지 확인합니다.
+// 자기 참조이면 tableData의 행과 기록할 행 자신(rowKey)에서 찾습니다.
+func referenceExists(col table.Column, value interface{}, tableName string, tableData *TableData, rowKey string, dbInfo dbinfo.DBInfo) (bool, error) {
+	key := formatValue(value)
+	if col.References == tableName {
+		return key == rowKey || keyExists(key, tableData), nil
+	}
+	parentData, err := takeTableData(col.References, dbInfo)
+	if err != nil {
+		return false, fmt.Errorf("failed to read referenced table '%s': %v", col.References, err)
+	}
+	keepTableData(col.References, parentData, dbInfo)
+	return keyExists(key, parentData), nil
+}
+
+// checkReferences는 ADD와 UPDATE로 기록할 행의 외래 키 값이 참조하는 테이블에 있는지 확인합니다. NULL은 확인하지 않습니다.
+func checkReferences(tableName string, tableData *TableData, row Row, dbInfo dbinfo.DBInfo) error {
+	for _, col := range tableData.Columns {
+		value := row.Data[col.Name]
+		if col.References == "" || value == nil {
+			continue
+		}
+		ok, err := referenceExists(col, value, tableName, tableData, row.Key, dbInfo)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("foreign key violated: value '%s' in column '%s' does not exist in table '%s'",
+				formatValue(value), col.Name, col.References)
+		}
+	}
+	return nil
+}
+
+// childRef는 다른 테이블(또는 자신)을 참조하는 외래 키 열입니다.
+type childRef struct {
+	table  string
+	column table.Column
+}
+
+// referencingColumns는 parent 테이블을 참조하는 모든 외래 키 열을 찾습니다.
+func referencingColumns(parent string, dbInfo dbinfo.DBInfo) ([]childRef, error) {
+	cat, err := loadCatalog(dbInfo)
+	if err != nil {
+		return nil, err
+	}
+	names := cat.names()
+
+	var refs []childRef
+	for _, name := range names {
+		columns, err := tableColumns(name, dbInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read table '%s': %v", name, err)
+		}
+		for _, col := range columns {
+			if col.References == parent {
+				refs = append(refs, childRef{table: name, column: col})
+			}
+		}
+	}
+	return refs, nil
+}
+
+// findReferencingRow는 child 테이블에서 ref 열의 값이 key인 첫 행을 찾습니다. skip 키의 행은 제외합니다.
+func findReferencingRow(childData *TableData, ref childRef, key string, skip string) (Row, bool) {
+	for _, row := range childData.Rows {
+		if row.Key == skip {
+			continue
+		}
+		if value := row.Data[ref.column.Name]; value != nil && formatValue(value) == key {
+			return row, true
+		}
+	}
+	return Row{}, false
+}
+
+// checkNotReferenced는 다른 행이 parent 테이블의 key 행을 참조하지 않는지 확인합니다.
+// UPDATE로 키를 바꾸거나 다른 테이블에서 참조하는 키 열을 바꿀 때 사용합니다.
+func checkNotReferenced(parent string, parentData *TableData, key string, dbInfo dbinfo.DBInfo) error {
+	refs, err := referencingColumns(parent, dbInfo)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		childData := parentData
+		if ref.table != parent {
+			childData, err = loadTableData(ref.table, dbInfo)
+			if err != nil {
+				return fmt.Errorf("failed to load table '%s': %v", ref.table, err)
+			}
+		}
+		if row, ok := findReferencingRow(childData, ref, key, key); ok {
+			return fmt.Errorf("key '%s' is referenced by row '%s' of table '%s' (column '%s')",
+				key, row.Key, ref.table, ref.column.Name)
+		}
+	}
+	return nil
+}
+
+// deletePlan은 DELETE 한 번으로 지워지거나 바뀌는 행을 외래 키의 삭제 동작에 따라 모읍니다.
+// 여러 테이블의 변경을 모두 확인한 뒤에 기록하므로, RESTRICT나 CHECK 위반이 있으면 아무것도 바꾸지 않습니다.
+type deletePlan struct {
+	dbInfo  dbinfo.DBInfo
+	tables  map[string]*TableData
+	order   []string                  // 불러온 순서 (첫 번째가 DELETE 명령의 테이블)
+	refs    map[string][]childRef     // 부모 테이블 -> 참조하는 외래 키 열
+	deleted map[string]map[string]Row // 테이블 -> 키 -> 삭제할 행
+	updated map[string]map[string]Row // 테이블 -> 키 -> SET NULL을 적용한 새 버전
+}
+
+func newDeletePlan(tableName string, tableData *TableData, dbInfo dbinfo.DBInfo) *deletePlan {
+	return &deletePlan{
+		dbInfo:  dbInfo,
+		tables:  map[string]*TableData{tableName: tableData},
+		order:   []string{tableName},
+		refs:    make(map[string][]childRef),
+		deleted: make(map[string]map[string]Row),
+		updated: make(map[string]map[string]Row),
+	}
+}
+
+// load는 테이블을 한 번만 불러옵니다.
+func (p *deletePlan) load(tableName string) (*TableData, error) {
+	if tableData, ok := p.tables[tableName]; ok {
+		return tableData, nil
+	}
+	tableData, err := takeTableData(tableName, p.dbInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load table '%s': %v", tableName, err)
+	}
+	p.tables[tableName] = tableData
+	p.order = append(p.order, tableName)
+	return tableData, nil
+}
+
+// deleteRow는 행 하나의 삭제를 계획하고, 그 행을 참조하는 행에 삭제 동작을 적용합니다.
+func (p *deletePlan) deleteRow(tableName string, row Row) error {
+	if _, ok := p.deleted[tableName][row.Key]; ok {
+		return nil
+	}
+	if p.deleted[tableName] == nil {
+		p.deleted[tableName] = make(map[string]Row)
+	}
+	p.deleted[tableName][row.Key] = row
+	delete(p.updated[tableName], row.Key)
+
+	// 복합 키 테이블은 참조될 수 없습니다.
+	if len(keyColumns(p.tables[tableName].Columns)) != 1 {
+		return nil
+	}
+	refs, ok := p.refs[tableName]
+	if !ok {
+		var err error
+		refs, err = referencingColumns(tableName, p.dbInfo)
+		if err != nil {
+			return err
+		}
+		p.refs[tableName] = refs
+	}
+
+	for _, ref := range refs {
+		childData, err := p.load(ref.table)
+		if err != nil {
+			return err
+		}
+		for _, child := range childData.Rows {
+			if _, gone := p.deleted[ref.table][child.Key]; gone {
+				continue
+			}
+			if newer, ok := p.updated[ref.table][child.Key]; ok {
+				child = newer
+			}
+			value := child.Data[ref.column.Name]
+			if value == nil || formatValue(value) != row.Key {
+				continue
+			}
+
+			switch ref.column.On_delete {
+			case table.RA_cascade:
+				if err := p.deleteRow(ref.table, child); err != nil {
+					return err
+				}
+			case table.RA_setNull:
+				data := copyRowData(child.Data)
+				data[ref.column.Name] = nil
+				if err := checkRow(childData.Checks, childData.Columns, data); err != nil {
+					return fmt.Errorf("cannot set column '%s' of row '%s' in table '%s' to NULL: %v",
+						ref.column.Name, child.Key, ref.table, err)
+				}
+				if p.updated[ref.table] == nil {
+					p.updated[ref.table] = make(map[string]Row)
+				}
+				p.updated[ref.table][child.Key] = Row{Key: child.Key, Data: data}
+			default:
+				return fmt.Errorf("key '%s' is referenced by row '%s' of table '%s' (column '%s')",
+					row.Key, child.Key, ref.table, ref.column.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// apply는 계획한 변경을 테이블마다 한 묶음으로 기록합니다.
+// DELETE 명령의 테이블을 마지막에 기록하므로, 도중에 중단되면 WAL 재실행이 명령 전체를 다시 계획합니다.
+// 반환값은 DELETE 명령이 지정한 행 외에 바뀐 행의 요약입니다.
+func (p *deletePlan) apply() ([]string, error) {
+	var summary []string
+	for i := len(p.order) - 1; i >= 0; i-- {
+		tableName := p.order[i]
+		tableData := p.tables[tableName]
+		deleted, updated := p.deleted[tableName], p.updated[tableName]
+		if len(deleted) == 0 && len(updated) == 0 {
+			continue
+		}
+
+		var records []tableRecord
+		var kept, changed []Row
+		for _, row := range tableData.Rows {
+			if _, ok := deleted[row.Key]; ok {
+				records = append(records, newTombstoneRecord(tableData.Columns, row))
+				reindexUnique(tableData, &row, nil)
+				continue
+			}
+			if newer, ok := updated[row.Key]; ok {
+				records = append(records, newDataRecord(newer))
+				reindexUnique(tableData, &row, &newer)
+				changed = append(changed, newer)
+				continue
+			}
+			kept = append(kept, row)
+		}
+		if err := appendTableRecords(tableData, tableName, p.dbInfo, records); err != nil {
+			return nil, fmt.Errorf("failed to save table '%s': %v", tableName, err)
+		}
+
+		// 새 버전은 파일 끝에 기록되었으므로 메모리에서도 끝으로 옮깁니다.
+		tableData.Rows = append(make([]Row, 0, len(kept)+len(changed)), kept...)
+		for _, row := range changed {
+			placeRow(tableData, row)
+		}
+		if err := compactTableIfNeeded(tableData, tableName, p.dbInfo); err != nil {
+			return nil, fmt.Errorf("failed to compact table '%s': %v", tableName, err)
+		}
+
+		cascaded := len(deleted)
+		if i == 0 {
+			cascaded-- // DELETE 명령이 지정한 행
+		}
+		if cascaded > 0 {
+			summary = append(summary, fmt.Sprintf("cascade: %d row(s) deleted from table '%s'", cascaded, tableName))
+		}
+		if len(updated) > 0 {
+			summary = append(summary, fmt.Sprintf("set null: %d row(s) updated in table '%s'", len(updated), tableName))
+		}
+	}
+
+	for _, tableName := range p.order {
+		keepTableData(tableName, p.tables[tableName], p.dbInfo)
+	}
+	return summary, nil
+}
+
+// renameReferences는 테이블 이름이 바뀔 때 외래 키 열이 참조하는 테이블 이름을 바꿉니다.
+func renameReferences(columns []table.Column, from, to string) {
+	for i := range columns {
+		if columns[i].References == from {
+			columns[i].References = to
+		}
+	}
+}
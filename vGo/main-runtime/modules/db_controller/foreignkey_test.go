@@ -0,0 +1,124 @@
+package dbcontroller
+
+import (
+	dbinfo "sedb/modules/db_info"
+	"testing"
+)
+
+// newShopDB는 외래 키 테스트에 쓰는 users, coupons, orders, reviews 테이블을 만듭니다.
+func newShopDB(t *testing.T) dbinfo.DBInfo {
+	t.Helper()
+	info := newTestDB(t)
+	mustExec(t, info, `create_table users (integer id NOTNULL KEY, text name);`)
+	mustExec(t, info, `create_table coupons (text code NOTNULL KEY);`)
+	mustExec(t, info, `create_table orders (
+		integer id NOTNULL KEY,
+		integer user_id NOTNULL REFERENCES users ON DELETE CASCADE,
+		text coupon REFERENCES coupons ON DELETE SET NULL
+	);`)
+	mustExec(t, info, `create_table reviews (integer id NOTNULL KEY, integer order_id REFERENCES orders);`)
+	mustExec(t, info, `add users (1, "kim");`)
+	mustExec(t, info, `add users (2, "lee");`)
+	mustExec(t, info, `add coupons ("SALE");`)
+	mustExec(t, info, `add orders (10, 1, "SALE");`)
+	mustExec(t, info, `add orders (11, 1, NULL);`)
+	mustExec(t, info, `add orders (12, 2, "SALE");`)
+	return info
+}
+
+// tableKeys는 테이블의 행 키를 행 순서대로 모읍니다.
+func tableKeys(t *testing.T, info dbinfo.DBInfo, tableName string) []string {
+	t.Helper()
+	tableData, err := loadTableData(tableName, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, len(tableData.Rows))
+	for i, row := range tableData.Rows {
+		keys[i] = row.Key
+	}
+	return keys
+}
+
+func checkKeys(t *testing.T, info dbinfo.DBInfo, tableName string, want ...string) {
+	t.Helper()
+	got := tableKeys(t, info, tableName)
+	if len(got) != len(want) {
+		t.Fatalf("%s keys: got %v, want %v", tableName, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%s keys: got %v, want %v", tableName, got, want)
+		}
+	}
+}
+
+func TestForeignKeyValues(t *testing.T) {
+	info := newShopDB(t)
+	for _, script := range []string{
+		`add orders (13, 7, NULL);`,
+		`add orders (13, 1, "NONE");`,
+		`update orders 10 (10, 7, NULL);`,
+		// 다른 행이 참조하는 행의 키는 바꿀 수 없습니다.
+		`update users 2 (3, "lee");`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected foreign key violation", script)
+		}
+	}
+	mustExec(t, info, `update users 2 (2, "park");`)
+}
+
+func TestForeignKeyOnDelete(t *testing.T) {
+	info := newShopDB(t)
+	mustExec(t, info, `add reviews (100, 12);`)
+
+	// 연쇄 삭제될 행을 RESTRICT 외래 키가 참조하면 아무것도 바꾸지 않습니다.
+	if CmdExec(`delete users 2;`, info) == 0 {
+		t.Error("deleted a user whose order has a review")
+	}
+	checkKeys(t, info, "orders", "10", "11", "12")
+
+	mustExec(t, info, `delete users 1;`)
+	checkKeys(t, info, "users", "2")
+	checkKeys(t, info, "orders", "12")
+
+	mustExec(t, info, `delete coupons "SALE";`)
+	tableData, err := loadTableData("orders", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tableData.Rows[0].Data["coupon"] != nil {
+		t.Errorf("coupon = %v, want NULL after SET NULL", tableData.Rows[0].Data["coupon"])
+	}
+}
+
+func TestForeignKeyTables(t *testing.T) {
+	info := newShopDB(t)
+	for _, script := range []string{
+		`create_table t (integer id NOTNULL KEY, integer u REFERENCES missing);`,
+		`create_table t (integer id NOTNULL KEY, text u REFERENCES users);`,
+		`create_table t (integer id NOTNULL KEY, integer u NOTNULL REFERENCES users ON DELETE SET NULL);`,
+		`drop_table users;`,
+		`truncate users;`,
+		`alter_table users modify_column id float;`,
+		`alter_table orders add_column integer reviewer NOTNULL DEFAULT 9 REFERENCES users;`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+
+	// 자기 참조 테이블의 행은 자신의 키를 참조할 수 있습니다.
+	mustExec(t, info, `create_table staff (integer id NOTNULL KEY, integer boss REFERENCES staff ON DELETE SET NULL);`)
+	mustExec(t, info, `add staff (1, 1);`)
+	mustExec(t, info, `add staff (2, 1);`)
+	mustExec(t, info, `delete staff 1;`)
+
+	// 참조되는 테이블의 이름을 바꾸면 외래 키도 새 이름을 따라갑니다.
+	mustExec(t, info, `rename_table users to members;`)
+	if CmdExec(`add orders (13, 9, NULL);`, info) == 0 {
+		t.Error("foreign key was lost after renaming the referenced table")
+	}
+	mustExec(t, info, `add orders (13, 2, NULL);`)
+}
@@ -0,0 +1,188 @@
+package dbcontroller
+
+import (
+	"fmt"
+	"math"
+	"sedb/modules/parsers"
+	"sedb/modules/table"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// generatedKinds는 키 생성 속성의 스크립트 및 헤더 표기(소문자)입니다.
+var generatedKinds = map[string]table.Generated_kind{
+	"auto_increment": table.GK_increment,
+	"autoincrement":  table.GK_increment,
+	"auto_uuid":      table.GK_uuid,
+	"auto_ulid":      table.GK_ulid,
+}
+
+// lastGeneratedKey는 마지막 ADD가 만든 키입니다. 키를 만들지 않았으면 빈 문자열입니다.
+var lastGeneratedKey string
+
+// LastGeneratedKey는 마지막으로 실행한 ADD 명령이 AUTO_INCREMENT, AUTO_UUID, AUTO_ULID로 만든 행 키를 반환합니다.
+// 키를 직접 지정했거나 ADD가 아닌 명령이었으면 빈 문자열입니다.
+func LastGeneratedKey() string {
+	execMu.Lock()
+	defer execMu.Unlock()
+	return lastGeneratedKey
+}
+
+// autoIncrementColumn은 AUTO_INCREMENT 열을 반환합니다. 없으면 nil입니다.
+func autoIncrementColumn(columns []table.Column) *table.Column {
+	for i := range columns {
+		if columns[i].Generated == table.GK_increment {
+			return &columns[i]
+		}
+	}
+	return nil
+}
+
+// checkGenerated는 생성 키 열 정의를 확인합니다.
+// 테이블에 하나만 둘 수 있고, 기본값이 없는 키 열이어야 합니다.
+// AUTO_INCREMENT는 INTEGER 열, AUTO_UUID와 AUTO_ULID는 TEXT 열에만 쓸 수 있습니다.
+func checkGenerated(columns []table.Column) string {
+	count := 0
+	for _, col := range columns {
+		if col.Generated == table.GK_none {
+			continue
+		}
+		count++
+		name := strings.ToUpper(generatedName(col.Generated))
+		want := table.CT_text
+		if col.Generated == table.GK_increment {
+			want = table.CT_integer
+		}
+		if col.Type != want || !col.Is_key {
+			return fmt.Sprintf("error: %s column '%s' must be a %s KEY column",
+				name, col.Name, columnTypeName(table.Column{Type: want}))
+		}
+		if col.Default_kind != table.DK_none {
+			return fmt.Sprintf("error: %s column '%s' cannot have a default value", name, col.Name)
+		}
+	}
+	if count > 1 {
+		return "error: only one generated KEY column is allowed per table"
+	}
+	return ""
+}
+
+// generatedName은 키 생성 속성의 표기 이름을 반환합니다.
+func generatedName(kind table.Generated_kind) string {
+	switch kind {
+	case table.GK_increment:
+		return "auto_increment"
+	case table.GK_uuid:
+		return "auto_uuid"
+	case table.GK_ulid:
+		return "auto_ulid"
+	}
+	return ""
+}
+
+// autoIncrementFromHeader는 헤더에 기록된 AUTO_INCREMENT 카운터(다음 값)를 읽습니다. 없으면 0입니다.
+func autoIncrementFromHeader(headerTokens []parsers.Tff_token) int64 {
+	for i := 0; i+1 < len(headerTokens); i++ {
+		if headerTokens[i].Token_type == parsers.Tff_Generated &&
+			strings.ToUpper(headerTokens[i].Token.(string)) == "AUTO_INCREMENT" {
+			next, _ := strconv.ParseInt(headerTokens[i+1].Token.(string), 10, 64)
+			return next
+		}
+	}
+	return 0
+}
+
+// trackAutoIncrement는 레코드의 AUTO_INCREMENT 값이 next 이상이면 그 다음 값을, 아니면 next를 반환합니다.
+// 값이 INTEGER 최댓값이면 다음 값이 없으므로 카운터가 다 쓰였음을 뜻하는 math.MaxInt64를 반환합니다.
+// 삭제 표시도 키 값을 담으므로, 파일에 남아 있는 한 삭제된 행의 값도 다시 쓰이지 않습니다.
+// 파일을 다시 쓸 때는 카운터가 헤더에 기록됩니다.
+func trackAutoIncrement(next int64, columns []table.Column, rec tableRecord) int64 {
+	col := autoIncrementColumn(columns)
+	if col == nil {
+		return next
+	}
+	if next < 1 {
+		next = 1
+	}
+	if value, ok := rec.row.Data[col.Name].(int64); ok && value >= next {
+		next = min(value, math.MaxInt64-1) + 1
+	}
+	return next
+}
+
+// isGeneratedKey는 ADD에서 값을 생략할 수 있는 생성 키 열인지 확인합니다.
+func isGeneratedKey(col table.Column) bool {
+	return col.Is_key && col.Generated != table.GK_none
+}
+
+// expandGeneratedKeys는 ADD 값 목록이 열 수보다 적으면 생성 키 열 자리에 DEFAULT를 끼워 넣습니다.
+// 나머지 값은 생성 키가 아닌 열에 순서대로 대응하며, 생략한 끝 열은 fillDefaults가 기본값으로 채웁니다.
+// 값을 모두 쓰면 키도 직접 지정한 것으로 보고 그대로 둡니다.
+func expandGeneratedKeys(data []interface{}, columns []table.Column) []interface{} {
+	if len(data) >= len(columns) {
+		return data
+	}
+	generated := false
+	for _, col := range columns {
+		generated = generated || isGeneratedKey(col)
+	}
+	if !generated {
+		return data
+	}
+
+	expanded := make([]interface{}, 0, len(columns))
+	for _, col := range columns {
+		switch {
+		case isGeneratedKey(col):
+			expanded = append(expanded, useDefault)
+		case len(data) > 0:
+			expanded = append(expanded, data[0])
+			data = data[1:]
+		case col.Default_kind != table.DK_none:
+			expanded = append(expanded, useDefault)
+		default:
+			return expanded // 기본값 없는 열의 생략은 fillDefaults가 오류로 알립니다.
+		}
+	}
+	return append(expanded, data...)
+}
+
+// fillGeneratedKeys는 생성 키 열의 DEFAULT 자리를 새 키 값으로 채웁니다.
+// AUTO_INCREMENT는 카운터의 다음 값, AUTO_UUID는 새 UUID, AUTO_ULID는 새 ULID입니다.
+func fillGeneratedKeys(data []interface{}, tableData *TableData) error {
+	for i, col := range tableData.Columns {
+		if !isGeneratedKey(col) || i >= len(data) || data[i] != useDefault {
+			continue
+		}
+
+		var value string
+		var err error
+		switch col.Generated {
+		case table.GK_increment:
+			if tableData.nextAuto == math.MaxInt64 {
+				return fmt.Errorf("AUTO_INCREMENT counter of column '%s' is exhausted", col.Name)
+			}
+			value = strconv.FormatInt(max(tableData.nextAuto, 1), 10)
+		case table.GK_uuid:
+			value, err = newUUID()
+		case table.GK_ulid:
+			value, err = newULID(time.Now())
+		}
+		if err != nil {
+			return err
+		}
+		data[i] = value
+	}
+	return nil
+}
+
+// generatesKey는 값 목록에서 키 열 중 하나라도 생성될 값(DEFAULT 자리)인지 확인합니다.
+func generatesKey(data []interface{}, columns []table.Column) bool {
+	for i, col := range columns {
+		if i < len(data) && data[i] == useDefault && isGeneratedKey(col) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package dbcontroller
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestAutoIncrement(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table orders (integer id NOTNULL KEY AUTO_INCREMENT, text item NOTNULL, integer qty NOTNULL DEFAULT 1);`)
+
+	mustExec(t, info, `add orders ("pen");`)
+	if LastGeneratedKey() != "1" {
+		t.Errorf("generated key = %q, want 1", LastGeneratedKey())
+	}
+	mustExec(t, info, `add orders ("ink", 3);`)
+	// 직접 지정한 키가 카운터보다 크면 카운터가 그 다음 값으로 올라갑니다.
+	mustExec(t, info, `add orders (100, "cap", 1);`)
+	if LastGeneratedKey() != "" {
+		t.Errorf("generated key = %q after an explicit key", LastGeneratedKey())
+	}
+	mustExec(t, info, `add orders (DEFAULT, "cup", 2);`)
+	if LastGeneratedKey() != "101" {
+		t.Errorf("generated key = %q, want 101", LastGeneratedKey())
+	}
+
+	// 삭제하거나 비운 행의 키는 다시 쓰이지 않습니다.
+	mustExec(t, info, `delete orders 101;`)
+	mustExec(t, info, `add orders ("mug");`)
+	if LastGeneratedKey() != "102" {
+		t.Errorf("generated key = %q after delete, want 102", LastGeneratedKey())
+	}
+	mustExec(t, info, `truncate orders;`)
+	mustExec(t, info, `add orders ("pad");`)
+	if LastGeneratedKey() != "103" {
+		t.Errorf("generated key = %q after truncate, want 103", LastGeneratedKey())
+	}
+	// 파일 전체를 다시 쓰는 구조 변경 뒤에도 카운터가 유지됩니다.
+	mustExec(t, info, `alter_table orders add_column text note;`)
+	mustExec(t, info, `add orders ("pin", 1, NULL);`)
+	if LastGeneratedKey() != "104" {
+		t.Errorf("generated key = %q after alter, want 104", LastGeneratedKey())
+	}
+}
+
+func TestGeneratedTextKeys(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table a (text id NOTNULL KEY AUTO_UUID, text name);`)
+	mustExec(t, info, `create_table b (text id NOTNULL KEY AUTO_ULID, text name);`)
+
+	mustExec(t, info, `add a ("kim");`)
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !uuidPattern.MatchString(LastGeneratedKey()) {
+		t.Errorf("AUTO_UUID key %q is not a UUID", LastGeneratedKey())
+	}
+
+	// ULID 앞 10자는 밀리초 시각이므로 나중에 만든 키가 작지 않습니다.
+	mustExec(t, info, `add b ("kim");`)
+	first := LastGeneratedKey()
+	mustExec(t, info, `add b ("lee");`)
+	second := LastGeneratedKey()
+	ulidPattern := regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
+	if !ulidPattern.MatchString(first) || !ulidPattern.MatchString(second) || first[:10] > second[:10] {
+		t.Errorf("ULID keys %q, %q are not ordered ULIDs", first, second)
+	}
+}
+
+func TestGeneratedKeyErrors(t *testing.T) {
+	info := newTestDB(t)
+	for _, script := range []string{
+		`create_table t (text id NOTNULL KEY AUTO_INCREMENT);`,
+		`create_table t (integer id NOTNULL KEY, integer n AUTO_INCREMENT);`,
+		`create_table t (integer id NOTNULL KEY AUTO_INCREMENT DEFAULT 1);`,
+		`create_table t (integer id NOTNULL KEY AUTO_UUID);`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY AUTO_INCREMENT, text name);`)
+	if CmdExec(`alter_table t modify_column id text;`, info) == 0 {
+		t.Error("changed the type of a generated key column")
+	}
+
+	// 직접 지정한 키가 INTEGER 최댓값이면 더 만들 키가 없습니다.
+	mustExec(t, info, `add t (9223372036854775807, "max");`)
+	if CmdExec(`add t ("next");`, info) == 0 {
+		t.Error("generated a key after the INTEGER maximum")
+	}
+	resetTableCache()
+	if CmdExec(`add t ("next");`, info) == 0 {
+		t.Error("generated a key after the INTEGER maximum once the table was reloaded")
+	}
+}
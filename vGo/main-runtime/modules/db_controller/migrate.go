@@ -0,0 +1,379 @@
+package dbcontroller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	dbinfo "sedb/modules/db_info"
+	"sedb/modules/parsers"
+	"sort"
+	"strconv"
+)
+
+// migrationsTable은 적용한 마이그레이션의 버전과 체크섬을 기록하는 테이블입니다.
+const migrationsTable = "_migrations"
+
+// 마이그레이션 상태. applied가 아닌 행은 도중에 실패한 마이그레이션입니다.
+const (
+	migrationApplying  = "applying"
+	migrationApplied   = "applied"
+	migrationReverting = "reverting"
+)
+
+// createMigrationsTable은 마이그레이션 기록 테이블을 만드는 명령입니다.
+var createMigrationsTable = `create_table ` + migrationsTable + ` (
+	integer version NOTNULL KEY,
+	text name NOTNULL,
+	text checksum NOTNULL,
+	text status NOTNULL CHECK (status IN ("applying", "applied", "reverting")),
+	timestamp applied_at DEFAULT NOW()
+);`
+
+// migrationFileName은 마이그레이션 파일 이름입니다. 예) 0001_create_users.dcl, 0001_create_users.down.dcl
+var migrationFileName = regexp.MustCompile(`^([0-9]+)_([A-Za-z0-9_]+?)(\.down)?\.dcl$`)
+
+// migrationFile은 버전 하나의 마이그레이션 파일입니다.
+type migrationFile struct {
+	version  int64
+	name     string
+	upPath   string
+	downPath string // 되돌리는 파일 (없으면 빈 문자열)
+	checksum string // up 파일 내용의 SHA-256
+}
+
+// appliedMigration은 마이그레이션 기록 테이블의 행입니다.
+type appliedMigration struct {
+	version  int64
+	name     string
+	checksum string
+	status   string
+}
+
+// readMigrations는 디렉토리의 마이그레이션 파일을 버전 순서로 읽습니다.
+// 이름 규칙에 맞지 않는 .dcl 파일, 겹치는 버전, up 파일이 없는 down 파일은 오류입니다.
+func readMigrations(dir string) ([]*migrationFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*migrationFile)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".dcl" {
+			continue
+		}
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name '%s' (expected [version]_[name].dcl)", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in '%s'", entry.Name())
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &migrationFile{version: version, name: match[2]}
+			byVersion[version] = m
+		} else if m.name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both '%s' and '%s'", version, m.name, match[2])
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if match[3] != "" {
+			m.downPath = path
+			continue
+		}
+		if m.upPath != "" {
+			return nil, fmt.Errorf("migration version %d has more than one file", version)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(content)
+		m.upPath = path
+		m.checksum = hex.EncodeToString(sum[:])
+	}
+
+	migrations := make([]*migrationFile, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.upPath == "" {
+			return nil, fmt.Errorf("migration %d (%s) has a down file but no up file", m.version, m.name)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	return migrations, nil
+}
+
+// readMigrationScript는 마이그레이션 파일을 명령 단위로 나누고 각 명령을 미리 파싱해 봅니다.
+func readMigrationScript(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []string
+	if parsers.SplitScript(string(content), &statements) != 0 {
+		return nil, fmt.Errorf("%s: unterminated string", filepath.Base(path))
+	}
+	for i, stmt := range statements {
+		var tokens []parsers.SC_token
+		if parsers.Parsing_script(stmt, &tokens) != 0 || len(tokens) == 0 {
+			return nil, fmt.Errorf("%s: statement %d: failed to parse script", filepath.Base(path), i+1)
+		}
+		if tokens[0].Token_type == parsers.SC_migrate {
+			return nil, fmt.Errorf("%s: statement %d: MIGRATE cannot be used in a migration", filepath.Base(path), i+1)
+		}
+	}
+	return statements, nil
+}
+
+// appliedMigrations는 기록 테이블의 행을 버전 순서로 읽습니다. 테이블이 없으면 빈 목록입니다.
+func appliedMigrations(dbInfo dbinfo.DBInfo) ([]appliedMigration, error) {
+	if !tableExists(migrationsTable, dbInfo) {
+		return nil, nil
+	}
+	tableData, err := loadTableData(migrationsTable, dbInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]appliedMigration, 0, len(tableData.Rows))
+	for _, row := range tableData.Rows {
+		version, _ := row.Data["version"].(int64)
+		name, _ := row.Data["name"].(string)
+		checksum, _ := row.Data["checksum"].(string)
+		status, _ := row.Data["status"].(string)
+		applied = append(applied, appliedMigration{version: version, name: name, checksum: checksum, status: status})
+	}
+	sort.Slice(applied, func(i, j int) bool {
+		return applied[i].version < applied[j].version
+	})
+	return applied, nil
+}
+
+// checkMigrationHistory는 적용한 마이그레이션이 모두 끝났고 파일이 바뀌지 않았는지 확인합니다.
+func checkMigrationHistory(migrations []*migrationFile, applied []appliedMigration) error {
+	files := make(map[int64]*migrationFile, len(migrations))
+	for _, m := range migrations {
+		files[m.version] = m
+	}
+
+	for _, a := range applied {
+		if a.status != migrationApplied {
+			return fmt.Errorf("migration %d (%s) stopped while %s; fix the database and delete its row from table '%s'",
+				a.version, a.name, a.status, migrationsTable)
+		}
+		m := files[a.version]
+		if m == nil {
+			return fmt.Errorf("applied migration %d (%s) is missing from the migration directory", a.version, a.name)
+		}
+		if m.checksum != a.checksum {
+			return fmt.Errorf("migration %d (%s) was edited after it was applied (checksum mismatch)", a.version, m.name)
+		}
+	}
+	return nil
+}
+
+// handleMigrate는 MIGRATE 명령을 처리합니다.
+// 디렉토리의 [버전]_[이름].dcl 파일 중 적용하지 않은 것을 버전 순서로 적용하고 _migrations 테이블에 기록합니다.
+// 파일의 명령은 일반 명령과 같이 하나씩 WAL에 기록하며 실행합니다.
+// 문법: migrate "[디렉토리]" --dry-run(선택) --down [단계 수](선택);
+func handleMigrate(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
+	if len(tokens) < 2 || tokens[1].Token_type != parsers.SC_string {
+		return printError("syntax error: expected migration directory")
+	}
+	dir := tokens[1].Token.(string)
+
+	dryRun, down, steps := false, false, 1
+	for i := 2; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.Token_type == parsers.SC_option && tok.Token == "dry-run":
+			dryRun = true
+		case tok.Token_type == parsers.SC_option && tok.Token == "down":
+			down = true
+			if i+1 < len(tokens) && tokens[i+1].Token_type == parsers.SC_number {
+				n, err := strconv.Atoi(tokens[i+1].Token.(string))
+				if err != nil || n < 1 {
+					return printError(fmt.Sprintf("syntax error: invalid number of steps '%v'", tokens[i+1].Token))
+				}
+				steps = n
+				i++
+			}
+		case tok.Token_type == parsers.SC_endCmd:
+		default:
+			return printError(fmt.Sprintf("syntax error: unexpected '%v' in MIGRATE statement", tok.Token))
+		}
+	}
+
+	migrations, err := readMigrations(dir)
+	if err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+	applied, err := appliedMigrations(dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: failed to read table '%s': %v", migrationsTable, err))
+	}
+	if err := checkMigrationHistory(migrations, applied); err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+
+	if down {
+		return migrateDown(migrations, applied, steps, dryRun, dbInfo)
+	}
+	return migrateUp(migrations, applied, dryRun, dbInfo)
+}
+
+// migrateUp은 적용하지 않은 마이그레이션을 버전 순서로 적용합니다.
+// 이미 적용한 버전보다 낮은 버전이 새로 생겼으면 아무것도 적용하지 않습니다.
+func migrateUp(migrations []*migrationFile, applied []appliedMigration, dryRun bool, dbInfo dbinfo.DBInfo) int {
+	var current int64
+	done := make(map[int64]bool, len(applied))
+	for _, a := range applied {
+		done[a.version] = true
+		current = max(current, a.version)
+	}
+
+	var pending []*migrationFile
+	for _, m := range migrations {
+		if done[m.version] {
+			continue
+		}
+		if m.version < current {
+			return printError(fmt.Sprintf("error: migration %d (%s) is older than applied migration %d", m.version, m.name, current))
+		}
+		pending = append(pending, m)
+	}
+
+	// 실행하기 전에 모든 파일을 읽고 파싱해 봅니다.
+	scripts := make([][]string, len(pending))
+	for i, m := range pending {
+		statements, err := readMigrationScript(m.upPath)
+		if err != nil {
+			return printError(fmt.Sprintf("error: %v", err))
+		}
+		scripts[i] = statements
+	}
+
+	if len(pending) == 0 {
+		fmt.Printf("Database is up to date (version %d)\n", current)
+		return 0
+	}
+	if dryRun {
+		for i, m := range pending {
+			fmt.Printf("Would apply migration %d (%s):\n", m.version, m.name)
+			for _, stmt := range scripts[i] {
+				fmt.Printf("  %s\n", stmt)
+			}
+		}
+		fmt.Printf("Dry run: %d migration(s) pending\n", len(pending))
+		return 0
+	}
+
+	if !tableExists(migrationsTable, dbInfo) && execScript(createMigrationsTable, dbInfo) != 0 {
+		return printError(fmt.Sprintf("error: failed to create table '%s'", migrationsTable))
+	}
+
+	for i, m := range pending {
+		fmt.Printf("Applying migration %d (%s)\n", m.version, m.name)
+		// 먼저 applying으로 기록하므로 도중에 실패하면 다음 실행이 멈춥니다.
+		record := fmt.Sprintf("add %s (%d, %s, %s, %s, NULL);", migrationsTable, m.version,
+			parsers.QuoteTffString(m.name), parsers.QuoteTffString(m.checksum), parsers.QuoteTffString(migrationApplying))
+		if execScript(record, dbInfo) != 0 {
+			return printError(fmt.Sprintf("error: failed to record migration %d", m.version))
+		}
+		if errMsg := runMigrationScript(m, scripts[i], dbInfo); errMsg != "" {
+			return printError(errMsg)
+		}
+		record = fmt.Sprintf("update %s %d (%d, %s, %s, %s, DEFAULT);", migrationsTable, m.version, m.version,
+			parsers.QuoteTffString(m.name), parsers.QuoteTffString(m.checksum), parsers.QuoteTffString(migrationApplied))
+		if execScript(record, dbInfo) != 0 {
+			return printError(fmt.Sprintf("error: failed to record migration %d", m.version))
+		}
+	}
+
+	fmt.Printf("%d migration(s) applied (version %d)\n", len(pending), pending[len(pending)-1].version)
+	return 0
+}
+
+// migrateDown은 마지막으로 적용한 마이그레이션부터 steps개를 down 파일로 되돌리고 기록을 지웁니다.
+func migrateDown(migrations []*migrationFile, applied []appliedMigration, steps int, dryRun bool, dbInfo dbinfo.DBInfo) int {
+	files := make(map[int64]*migrationFile, len(migrations))
+	for _, m := range migrations {
+		files[m.version] = m
+	}
+	if len(applied) == 0 {
+		fmt.Println("No applied migrations to revert")
+		return 0
+	}
+	if steps > len(applied) {
+		return printError(fmt.Sprintf("error: cannot revert %d migration(s); only %d applied", steps, len(applied)))
+	}
+
+	targets := make([]*migrationFile, 0, steps)
+	scripts := make([][]string, 0, steps)
+	for i := len(applied) - 1; i >= len(applied)-steps; i-- {
+		m := files[applied[i].version]
+		if m.downPath == "" {
+			return printError(fmt.Sprintf("error: migration %d (%s) has no down file", m.version, m.name))
+		}
+		statements, err := readMigrationScript(m.downPath)
+		if err != nil {
+			return printError(fmt.Sprintf("error: %v", err))
+		}
+		targets = append(targets, m)
+		scripts = append(scripts, statements)
+	}
+
+	if dryRun {
+		for i, m := range targets {
+			fmt.Printf("Would revert migration %d (%s):\n", m.version, m.name)
+			for _, stmt := range scripts[i] {
+				fmt.Printf("  %s\n", stmt)
+			}
+		}
+		fmt.Printf("Dry run: %d migration(s) would be reverted\n", len(targets))
+		return 0
+	}
+
+	for i, m := range targets {
+		fmt.Printf("Reverting migration %d (%s)\n", m.version, m.name)
+		record := fmt.Sprintf("update %s %d (%d, %s, %s, %s, NULL);", migrationsTable, m.version, m.version,
+			parsers.QuoteTffString(m.name), parsers.QuoteTffString(m.checksum), parsers.QuoteTffString(migrationReverting))
+		if execScript(record, dbInfo) != 0 {
+			return printError(fmt.Sprintf("error: failed to record migration %d", m.version))
+		}
+		if errMsg := runMigrationScript(m, scripts[i], dbInfo); errMsg != "" {
+			return printError(errMsg)
+		}
+		if execScript(fmt.Sprintf("delete %s %d;", migrationsTable, m.version), dbInfo) != 0 {
+			return printError(fmt.Sprintf("error: failed to record migration %d", m.version))
+		}
+	}
+
+	var current int64
+	if remaining := len(applied) - steps; remaining > 0 {
+		current = applied[remaining-1].version
+	}
+	fmt.Printf("%d migration(s) reverted (version %d)\n", len(targets), current)
+	return 0
+}
+
+// runMigrationScript는 마이그레이션 파일의 명령을 차례로 실행하고, 실패하면 실패한 명령을 알려줍니다.
+// 이미 실행한 명령은 되돌리지 않으므로 기록 테이블의 행은 applying 또는 reverting으로 남습니다.
+func runMigrationScript(m *migrationFile, statements []string, dbInfo dbinfo.DBInfo) string {
+	for i, stmt := range statements {
+		if execScript(stmt, dbInfo) != 0 {
+			return fmt.Sprintf("error: migration %d (%s) failed at statement %d: %s", m.version, m.name, i+1, stmt)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package dbcontroller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeMigrations는 migrations 디렉토리에 마이그레이션 파일을 만듭니다.
+func writeMigrations(t *testing.T, files map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll("migrations", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join("migrations", name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMigrateUpAndDown(t *testing.T) {
+	info := newTestDB(t)
+	writeMigrations(t, map[string]string{
+		"0001_create_users.dcl":      `create_table users (integer id NOTNULL KEY, text name);`,
+		"0001_create_users.down.dcl": `drop_table users;`,
+		"0002_seed.dcl":              "add users (1, \"kim\");\nadd users (2, \"lee\");\n",
+		"0002_seed.down.dcl":         `truncate users;`,
+		"notes.txt":                  `not a migration`,
+	})
+
+	mustExec(t, info, `migrate "migrations";`)
+	checkValues(t, mustQuery(t, info, `select _migrations;`), "status", "applied", "applied")
+	checkValues(t, mustQuery(t, info, `select users;`), "id", int64(1), int64(2))
+
+	// 이미 적용한 마이그레이션은 건너뜁니다.
+	mustExec(t, info, `migrate "migrations";`)
+	checkValues(t, mustQuery(t, info, `select users;`), "id", int64(1), int64(2))
+
+	mustExec(t, info, `migrate "migrations" --down;`)
+	checkValues(t, mustQuery(t, info, `select _migrations;`), "version", int64(1))
+	checkValues(t, mustQuery(t, info, `select users;`), "id")
+	mustExec(t, info, `migrate "migrations" --down 1;`)
+	checkValues(t, mustQuery(t, info, `select _migrations;`), "version")
+	if _, err := Query(`select users;`, info); err == nil {
+		t.Error("users table still exists after migrating down")
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	info := newTestDB(t)
+	writeMigrations(t, map[string]string{
+		"1_create_users.dcl": `create_table users (integer id NOTNULL KEY);`,
+	})
+
+	mustExec(t, info, `migrate "migrations" --dry-run;`)
+	if _, err := Query(`select users;`, info); err == nil {
+		t.Error("dry run created the users table")
+	}
+}
+
+func TestMigrateRejectsChangedFile(t *testing.T) {
+	info := newTestDB(t)
+	writeMigrations(t, map[string]string{
+		"1_create_users.dcl": `create_table users (integer id NOTNULL KEY);`,
+	})
+	mustExec(t, info, `migrate "migrations";`)
+
+	writeMigrations(t, map[string]string{
+		"1_create_users.dcl": `create_table users (integer id NOTNULL KEY, text name);`,
+		"2_seed.dcl":         `add users (1);`,
+	})
+	if CmdExec(`migrate "migrations";`, info) == 0 {
+		t.Fatal("migrate accepted an applied migration whose file changed")
+	}
+	checkValues(t, mustQuery(t, info, `select _migrations;`), "version", int64(1))
+}
+
+func TestMigrateFailureStaysApplying(t *testing.T) {
+	info := newTestDB(t)
+	writeMigrations(t, map[string]string{
+		"1_create_users.dcl": `create_table users (integer id NOTNULL KEY);`,
+		"2_seed.dcl":         "add users (1);\nadd users (1);\n",
+		"3_more.dcl":         `add users (3);`,
+	})
+
+	if CmdExec(`migrate "migrations";`, info) == 0 {
+		t.Fatal("migrate succeeded although a statement failed")
+	}
+	// 실행한 명령은 되돌리지 않고, 실패한 마이그레이션은 applying으로 남아 다음 실행을 막습니다.
+	checkValues(t, mustQuery(t, info, `select users;`), "id", int64(1))
+	checkValues(t, mustQuery(t, info, `select _migrations;`), "status", "applied", "applying")
+	if CmdExec(`migrate "migrations";`, info) == 0 {
+		t.Error("migrate ran with an unfinished migration record")
+	}
+}
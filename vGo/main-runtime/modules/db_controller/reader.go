@@ -0,0 +1,321 @@
+package dbcontroller
+
+import (
+	"bufio"
+	"hash/crc32"
+	"io"
+	dbinfo "sedb/modules/db_info"
+	"sedb/modules/parsers"
+	"sedb/modules/table"
+	"strings"
+)
+
+// textReader는 텍스트 TFF 파일을 파일 전체를 메모리에 올리지 않고 줄 단위로 읽습니다.
+// 생성 시 헤더를 먼저 파싱하고, next로 확정된 레코드를 하나씩 반환합니다.
+type textReader struct {
+	reader   *bufio.Reader
+	path     string
+	cols     []table.Column
+	chks     []table.Check
+	lsn      int64 // 지금까지 읽은 마지막 Lsn-> 번호
+	autoNext int64 // 헤더에 기록된 AUTO_INCREMENT 카운터
+	offset   int64 // 지금까지 읽은 바이트 수
+	st       storageState
+
+	lineNo      int    // 마지막으로 읽은 줄 번호
+	sum         uint32 // 지금까지 읽은 내용의 파일 체크섬
+	lineSum     uint32 // 마지막으로 읽은 줄 앞까지의 파일 체크섬
+	checksummed bool   // 체크섬이 있는 줄을 읽었는지 여부 (이후 줄은 모두 체크섬이 있어야 함)
+
+	raw      string        // 마지막으로 읽은 데이터 섹션 줄
+	damageFn damageHandler // 설정되면 손상을 오류 대신 보고하고 계속 읽음
+
+	pending    []tableRecord // 다음 Lsn-> 줄을 기다리는 레코드 묶음
+	pendingErr error         // pending 묶음에서 발견한 첫 번째 손상
+	ready      []tableRecord // 확정되어 반환을 기다리는 레코드
+	done       bool
+}
+
+// newTextReader는 r에서 DATA_SECTION까지의 헤더를 읽고 열 정의를 구성합니다.
+// path는 오류 메시지에 표시할 파일 경로입니다.
+func newTextReader(r io.Reader, path string) (*textReader, error) {
+	tr := &textReader{reader: bufio.NewReader(r), path: path, st: storageState{format: formatText}}
+
+	var header strings.Builder
+	for {
+		line, torn, err := tr.readLine()
+		if err != nil {
+			return nil, err
+		}
+		header.WriteString(line + "\n")
+		if strings.HasPrefix(strings.TrimSpace(line), "DATA_SECTION") {
+			break
+		}
+		if torn {
+			tr.done = true
+			break
+		}
+	}
+
+	// 헤더(테이블 구조) 파싱
+	var headerTokens []parsers.Tff_token
+	if parsers.ParseHeader(header.String(), &headerTokens) != 0 {
+		return nil, &corruptionError{path: path, where: "header", reason: "failed to parse table header"}
+	}
+	tr.cols = columnsFromHeader(headerTokens)
+	tr.chks = checksFromHeader(headerTokens)
+	tr.autoNext = autoIncrementFromHeader(headerTokens)
+	tr.st.committedSize = tr.offset
+	tr.st.checksum = tr.sum
+	return tr, nil
+}
+
+func (tr *textReader) columns() []table.Column { return tr.cols }
+func (tr *textReader) checks() []table.Check   { return tr.chks }
+func (tr *textReader) lastLsn() int64          { return tr.lsn }
+func (tr *textReader) storage() storageState   { return tr.st }
+func (tr *textReader) autoIncrement() int64    { return tr.autoNext }
+
+func (tr *textReader) onDamage(fn damageHandler) { tr.damageFn = fn }
+
+// readLine은 한 줄을 읽습니다. 줄바꿈 없이 파일이 끝났으면 torn이 true입니다.
+func (tr *textReader) readLine() (line string, torn bool, err error) {
+	line, err = tr.reader.ReadString('\n')
+	tr.offset += int64(len(line))
+	tr.lineNo++
+	tr.lineSum = tr.sum
+	tr.sum = crc32.Update(tr.sum, crc32.IEEETable, []byte(line))
+	if err == io.EOF {
+		return line, true, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSuffix(line, "\n"), false, nil
+}
+
+// next는 확정된 다음 레코드를 반환합니다. 더 이상 레코드가 없으면 ok가 false입니다.
+// 첫 Lsn-> 줄 이전의 레코드는 파일 교체로 원자적으로 기록된 기본 레코드이므로 바로 반환하고,
+// 그 이후 덧붙여진 레코드는 뒤따르는 Lsn-> 줄을 읽은 뒤에 반환합니다.
+// Lsn-> 줄 없이 끝난 꼬리는 기록이 완료되지 않은 것으로 보고 버립니다.
+func (tr *textReader) next() (tableRecord, bool, error) {
+	for len(tr.ready) == 0 {
+		if tr.done {
+			return tableRecord{}, false, nil
+		}
+		if err := tr.readRecords(); err != nil {
+			return tableRecord{}, false, err
+		}
+	}
+
+	rec := tr.ready[0]
+	tr.ready = tr.ready[1:]
+	return rec, true, nil
+}
+
+// readRecords는 데이터 섹션의 한 줄을 읽어 ready 또는 pending에 반영합니다.
+func (tr *textReader) readRecords() error {
+	raw, torn, err := tr.readLine()
+	if err != nil {
+		return err
+	}
+	line := strings.TrimSpace(raw)
+	tr.raw = line
+
+	if torn {
+		tr.done = true
+		// 덧붙이기는 항상 줄바꿈으로 끝나므로, 줄바꿈 없는 마지막 줄은 잘린 기록입니다.
+		// Lsn-> 줄이 없는 이전 형식 파일은 파일 전체가 기본 레코드이며 줄바꿈 없이 끝날 수 있습니다.
+		if tr.st.hasLsn {
+			return nil
+		}
+		if raw == "" || strings.HasPrefix(line, "Lsn->") {
+			tr.st.committedSize = tr.offset - int64(len(raw))
+			tr.st.checksum = tr.lineSum
+			return nil
+		}
+		tr.st.committedSize = tr.offset
+		tr.st.checksum = tr.sum
+		tr.st.needsNewline = true
+	}
+
+	if line == "" {
+		return nil
+	}
+
+	body, hasSum, err := splitLineChecksum(line)
+	if err != nil {
+		return tr.damaged(err.Error())
+	}
+	if hasSum {
+		tr.checksummed = true
+	} else if tr.checksummed {
+		return tr.damaged("missing line checksum")
+	}
+
+	if strings.HasPrefix(body, "Lsn->") {
+		var lsn int64
+		var fileSum string
+		if parsers.ParseLsnLine(body, &lsn, &fileSum) != 0 {
+			return tr.damaged("invalid Lsn line")
+		}
+		// 줄 자체는 온전하므로 앞선 묶음은 확정된 것입니다. 그 안의 손상은 잘린 기록이 아닙니다.
+		if tr.pendingErr != nil {
+			return tr.pendingErr
+		}
+		if fileSum != "" && fileSum != formatChecksum(tr.lineSum) {
+			err := lineCorruption(tr.path, tr.lineNo, fileChecksumMismatch)
+			if tr.damageFn == nil {
+				return err
+			}
+			tr.damageFn(err, "")
+		}
+
+		tr.lsn = lsn
+		tr.ready = append(tr.ready, tr.pending...)
+		tr.pending = tr.pending[:0]
+		tr.st.hasLsn = true
+		tr.st.committedSize = tr.offset
+		tr.st.checksum = tr.sum
+		return nil
+	}
+
+	rec, err := parseTableRecord(body, tr.cols, !hasSum)
+	if err != nil {
+		return tr.damaged(err.Error())
+	}
+	if tr.st.hasLsn {
+		tr.pending = append(tr.pending, rec)
+	} else {
+		tr.ready = append(tr.ready, rec)
+	}
+	return nil
+}
+
+// damaged는 마지막으로 읽은 줄의 손상을 처리합니다.
+// 기본 레코드 영역의 손상은 바로 오류입니다. 덧붙여진 영역의 손상은 뒤따르는 온전한 Lsn-> 줄이
+// 그 묶음을 확정할 때 오류가 되며, 그 전에 파일이 끝나면 완료되지 않은 꼬리로 보고 버립니다.
+// 손상 처리기가 설정되어 있으면 손상된 줄을 보고하고 건너뜁니다.
+func (tr *textReader) damaged(reason string) error {
+	err := lineCorruption(tr.path, tr.lineNo, reason)
+	if tr.damageFn != nil {
+		tr.damageFn(err, tr.raw)
+		return nil
+	}
+	if !tr.st.hasLsn {
+		return err
+	}
+	if tr.pendingErr == nil {
+		tr.pendingErr = err
+	}
+	return nil
+}
+
+// scanTable은 살아 있는 행을 하나씩 fn에 전달합니다.
+// 첫 번째 읽기에서 키별 마지막 레코드 위치만 기억하고 두 번째 읽기에서 행을 전달하므로,
+// 메모리 사용량은 행 데이터가 아닌 키 개수에 비례합니다.
+// 행은 각 키의 마지막 버전 위치 순서로 전달되며, 복합 키 테이블이 아니면 loadTableData와 같은 순서입니다.
+func scanTable(tableName string, dbInfo dbinfo.DBInfo, fn func(columns []table.Column, row Row) error) error {
+	// 1차: 키별 마지막 레코드 위치
+	latest := make(map[string]int64)
+	err := forEachRecord(tableName, dbInfo, func(_ []table.Column, pos int64, rec tableRecord) error {
+		if rec.tombstone {
+			delete(latest, rec.key)
+		} else {
+			latest[rec.key] = pos
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 2차: 마지막 버전인 레코드만 전달
+	return forEachRecord(tableName, dbInfo, func(columns []table.Column, pos int64, rec tableRecord) error {
+		if last, ok := latest[rec.key]; !ok || rec.tombstone || last != pos {
+			return nil
+		}
+		return fn(columns, rec.row)
+	})
+}
+
+// forEachRecord는 확정된 레코드를 순서 번호와 함께 fn에 전달합니다.
+func forEachRecord(tableName string, dbInfo dbinfo.DBInfo, fn func(columns []table.Column, pos int64, rec tableRecord) error) error {
+	reader, file, err := openTableReader(tableName, dbInfo)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var pos int64
+	for {
+		rec, ok, err := reader.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if err := fn(reader.columns(), pos, rec); err != nil {
+			return err
+		}
+		pos++
+	}
+}
+
+// findRow는 키에 해당하는 살아 있는 행을 한 번의 읽기로 찾습니다.
+// 키의 마지막 버전만 기억하므로 테이블 크기와 관계없이 메모리 사용량이 일정합니다.
+// 행이 없으면 nil을 반환합니다.
+func findRow(tableName string, rawKey []string, dbInfo dbinfo.DBInfo) ([]table.Column, *Row, string, error) {
+	reader, file, err := openTableReader(tableName, dbInfo)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	defer file.Close()
+
+	key, err := canonicalKey(rawKey, reader.columns())
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	var found *Row
+	for {
+		rec, ok, err := reader.next()
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if !ok {
+			break
+		}
+		if rec.key != key {
+			continue
+		}
+		if rec.tombstone {
+			found = nil
+		} else {
+			row := rec.row
+			found = &row
+		}
+	}
+
+	return reader.columns(), found, key, nil
+}
+
+// readTableLsn은 테이블에 마지막으로 반영된 WAL 레코드 번호를 읽습니다.
+func readTableLsn(tableName string, dbInfo dbinfo.DBInfo) (int64, error) {
+	reader, file, err := openTableReader(tableName, dbInfo)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	for {
+		_, ok, err := reader.next()
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return reader.lastLsn(), nil
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package dbcontroller
+
+import (
+	"fmt"
+	"sedb/modules/table"
+	"strings"
+	"testing"
+)
+
+const readerTestFile = `Title : "t"
+
+TABLE_S BEGIN
+    INTEGER id NOTNULL KEY,
+    TEXT name
+END
+
+DATA_SECTION :
+Data-> [1, "a"] ->End
+Lsn-> 3 ->End
+Data-> [2, "b"] ->End
+Del-> [1] ->End
+Lsn-> 4 ->End
+Data-> [3, "c"] ->End
+`
+
+func TestTableReaderStreamsRecords(t *testing.T) {
+	reader, err := newTextReader(strings.NewReader(readerTestFile), "t.tff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := reader.columns()
+	if len(columns) != 2 || columns[0].Name != "id" || !columns[0].Is_key {
+		t.Fatalf("columns = %+v", columns)
+	}
+
+	// Lsn-> 줄로 확정되지 않은 마지막 레코드는 반환하지 않습니다.
+	var got []string
+	for {
+		rec, ok, err := reader.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		if rec.tombstone {
+			got = append(got, "del "+rec.key)
+		} else {
+			got = append(got, fmt.Sprintf("data %s=%v", rec.key, rec.row.Data["name"]))
+		}
+	}
+	if want := "data 1=a|data 2=b|del 1"; strings.Join(got, "|") != want {
+		t.Errorf("records = %v, want %s", got, want)
+	}
+	if reader.lastLsn() != 4 {
+		t.Errorf("Lsn = %d, want 4", reader.lastLsn())
+	}
+}
+
+func TestScanTableAndFindRow(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, text name);`)
+	mustExec(t, info, `add t (1, "kim");`)
+	mustExec(t, info, `add t (2, "lee");`)
+	mustExec(t, info, `add t (3, "choi");`)
+	mustExec(t, info, `update t 1 (1, "park");`)
+	mustExec(t, info, `delete t 2;`)
+
+	// 스캔은 loadTableData와 같은 행을 같은 순서로 전달합니다.
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scanned []Row
+	err = scanTable("t", info, func(_ []table.Column, row Row) error {
+		scanned = append(scanned, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scanned) != len(tableData.Rows) {
+		t.Fatalf("scanned %d rows, want %d", len(scanned), len(tableData.Rows))
+	}
+	for i := range scanned {
+		if scanned[i].Key != tableData.Rows[i].Key || scanned[i].Data["name"] != tableData.Rows[i].Data["name"] {
+			t.Errorf("row %d: scanned %v, loaded %v", i, scanned[i], tableData.Rows[i])
+		}
+	}
+
+	_, row, _, err := findRow("t", []string{"1"}, info)
+	if err != nil || row == nil || row.Data["name"] != "park" {
+		t.Errorf("findRow(1) = %v, %v; want the updated row", row, err)
+	}
+	if _, row, _, err := findRow("t", []string{"2"}, info); err != nil || row != nil {
+		t.Errorf("findRow(2) = %v, %v; want no row", row, err)
+	}
+}
@@ -0,0 +1,296 @@
+package dbcontroller
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	dbinfo "sedb/modules/db_info"
+	"sedb/modules/parsers"
+	"sedb/modules/table"
+	"strings"
+)
+
+// 압축 조건: 쓸모없는 레코드가 compactMinGarbage개 이상이고 살아 있는 행보다 많을 때
+const compactMinGarbage = 64
+
+// tableRecord는 데이터 섹션의 레코드 하나입니다.
+// Data-> 레코드는 같은 키의 이전 버전을 대체하고, Del-> 레코드는 키를 삭제합니다.
+type tableRecord struct {
+	tombstone bool
+	key       string
+	row       Row
+}
+
+// rowSet은 레코드를 순서대로 적용하여 살아 있는 행을 구성합니다.
+type rowSet struct {
+	rows    []Row
+	index   map[string]int // 키 -> rows 위치
+	removed int            // rows에서 삭제된 자리 수
+	records int            // 적용한 레코드 수
+}
+
+func newRowSet() *rowSet {
+	return &rowSet{index: make(map[string]int)}
+}
+
+// apply는 레코드 하나를 적용합니다.
+// 행은 마지막 버전이 기록된 순서로 놓이므로, 수정된 행은 끝으로 옮겨집니다.
+// 이 순서는 압축 후에도, scanTable로 스트리밍할 때도 같습니다.
+func (rs *rowSet) apply(rec tableRecord) {
+	rs.records++
+
+	if pos, exists := rs.index[rec.key]; exists {
+		rs.rows[pos].Data = nil
+		delete(rs.index, rec.key)
+		rs.removed++
+	}
+	if rec.tombstone {
+		return
+	}
+
+	rs.index[rec.key] = len(rs.rows)
+	rs.rows = append(rs.rows, rec.row)
+}
+
+// live는 삭제되지 않은 행을 순서대로 반환합니다.
+func (rs *rowSet) live() []Row {
+	live := make([]Row, 0, len(rs.rows)-rs.removed)
+	for _, row := range rs.rows {
+		if row.Data != nil {
+			live = append(live, row)
+		}
+	}
+	return live
+}
+
+// parseTableRecord는 데이터 섹션의 한 줄을 레코드로 해석합니다.
+// legacy는 줄 체크섬이 없는 이전 형식의 줄이며, 따옴표 없는 NULL을 NULL 값이 아닌 문자열로 읽습니다.
+// 해석할 수 없는 줄이면 오류를 반환합니다.
+func parseTableRecord(line string, columns []table.Column, legacy bool) (tableRecord, error) {
+	var rec tableRecord
+	var tokens []parsers.Tff_token
+
+	parseData := parsers.ParseDataLine
+	if legacy {
+		parseData = parsers.ParseLegacyDataLine
+	}
+
+	switch {
+	case strings.HasPrefix(line, "Data->"):
+		if parseData(line, &tokens) != 0 {
+			return rec, fmt.Errorf("invalid data line")
+		}
+		row, err := rowFromTokens(tokens, columns)
+		if err != nil {
+			return rec, err
+		}
+		return newDataRecord(row), nil
+
+	case strings.HasPrefix(line, "Del->"):
+		if parsers.ParseTombstoneLine(line, &tokens) != 0 {
+			return rec, fmt.Errorf("invalid tombstone line")
+		}
+		keyCols := keyColumns(columns)
+		if len(keyCols) == 0 {
+			return rec, fmt.Errorf("cannot find key column")
+		}
+		// 삭제 표시는 키 열 순서대로 키 값만 담습니다.
+		row := Row{Data: make(map[string]interface{})}
+		n := 0
+		for _, token := range tokens {
+			if token.Token_type != parsers.Tff_numeric && token.Token_type != parsers.Tff_string {
+				continue
+			}
+			if n >= len(keyCols) {
+				return rec, fmt.Errorf("tombstone has more values than key columns")
+			}
+			value, err := convertValue(keyCols[n], token.Token.(string))
+			if err != nil {
+				return rec, err
+			}
+			row.Data[keyCols[n].Name] = value
+			n++
+		}
+		if n == 0 {
+			return rec, fmt.Errorf("tombstone without key")
+		}
+		if n != len(keyCols) {
+			return rec, fmt.Errorf("tombstone has %d key values, expected %d", n, len(keyCols))
+		}
+		return newTombstoneRecord(columns, row), nil
+	}
+
+	return rec, fmt.Errorf("unrecognized line")
+}
+
+// rowFromTokens는 Data-> 줄의 토큰을 열 순서대로 행으로 변환합니다.
+// 값이 열보다 적은 줄(이전 형식)은 나머지 열을 NULL로 채웁니다.
+func rowFromTokens(tokens []parsers.Tff_token, columns []table.Column) (Row, error) {
+	row := Row{
+		Data: make(map[string]interface{}),
+	}
+
+	dataIndex := 0
+	for _, token := range tokens {
+		if token.Token_type == parsers.Tff_numeric ||
+			token.Token_type == parsers.Tff_string ||
+			token.Token_type == parsers.Tff_null {
+			if dataIndex < len(columns) {
+				col := columns[dataIndex]
+
+				// 숫자 원문을 열 타입으로 변환하여 정밀도를 보존합니다.
+				// 이전 형식의 빈 칸은 TEXT 열에서는 빈 문자열, 다른 열에서는 NULL입니다.
+				var value interface{}
+				blank := token.Token_type == parsers.Tff_string && token.Token.(string) == ""
+				if token.Token_type != parsers.Tff_null && !(blank && col.Type != table.CT_text) {
+					var err error
+					value, err = convertValue(col, token.Token.(string))
+					if err != nil {
+						return row, err
+					}
+				}
+
+				row.Data[col.Name] = value
+				dataIndex++
+			}
+		}
+	}
+
+	for ; dataIndex < len(columns); dataIndex++ {
+		row.Data[columns[dataIndex].Name] = nil
+	}
+
+	row.Key, _ = rowKey(columns, row.Data)
+	return row, nil
+}
+
+// formatDataLine은 행을 Data-> 레코드 줄로 변환합니다.
+func formatDataLine(columns []table.Column, row Row) string {
+	var b strings.Builder
+	b.WriteString("Data-> [")
+	for i, col := range columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(formatTffValue(col, row.Data[col.Name]))
+	}
+	b.WriteString("] ->End")
+	return b.String()
+}
+
+// formatTombstoneLine은 행 키의 삭제 표시 레코드 줄을 만듭니다. 복합 키는 키 열 순서대로 값을 나열합니다.
+func formatTombstoneLine(columns []table.Column, row Row) string {
+	keyCols := keyColumns(columns)
+	values := make([]string, len(keyCols))
+	for i, col := range keyCols {
+		values[i] = formatTffValue(col, row.Data[col.Name])
+	}
+	return fmt.Sprintf("Del-> [%s] ->End", strings.Join(values, ", "))
+}
+
+// formatRecordLine은 레코드를 텍스트 TFF 레코드 줄로 변환합니다.
+func formatRecordLine(columns []table.Column, rec tableRecord) string {
+	if rec.tombstone {
+		return formatTombstoneLine(columns, rec.row)
+	}
+	return formatDataLine(columns, rec.row)
+}
+
+// newDataRecord는 행을 추가하거나 같은 키의 이전 버전을 대체하는 레코드를 만듭니다.
+func newDataRecord(row Row) tableRecord {
+	return tableRecord{key: row.Key, row: row}
+}
+
+// newTombstoneRecord는 행의 삭제 표시 레코드를 만듭니다. 행에서 키 열 값만 사용합니다.
+func newTombstoneRecord(columns []table.Column, row Row) tableRecord {
+	data := make(map[string]interface{})
+	for _, col := range keyColumns(columns) {
+		data[col.Name] = row.Data[col.Name]
+	}
+	key, _ := rowKey(columns, data)
+	return tableRecord{
+		tombstone: true,
+		key:       key,
+		row:       Row{Key: key, Data: data},
+	}
+}
+
+// formatLsnLine은 레코드 묶음을 확정하는 Lsn-> 줄의 내용을 만듭니다.
+// fileSum은 이 줄 앞까지의 파일 내용 체크섬입니다.
+func formatLsnLine(lsn int64, fileSum uint32) string {
+	return fmt.Sprintf("Lsn-> %d %s ->End", lsn, formatChecksum(fileSum))
+}
+
+// appendTextRecords는 레코드를 텍스트 테이블 파일 끝에 덧붙이고
+// Lsn-> 줄로 확정한 뒤 디스크에 동기화합니다.
+// 이전 기록이 남긴 완료되지 않은 꼬리는 먼저 잘라냅니다.
+func appendTextRecords(tableData *TableData, tableName string, dbInfo dbinfo.DBInfo, records []tableRecord, lsn int64) error {
+	var buf bytes.Buffer
+	cw := &checksumWriter{w: &buf, sum: tableData.storage.checksum}
+	if tableData.storage.needsNewline {
+		cw.writeString("\n")
+	}
+	if !tableData.storage.hasLsn {
+		// 이전 형식 파일: 기존 레코드를 먼저 확정하여 덧붙인 레코드와 구분
+		cw.writeLsnLine(tableData.Lsn)
+	}
+	for _, rec := range records {
+		cw.writeLine(formatRecordLine(tableData.Columns, rec))
+	}
+	cw.writeLsnLine(lsn)
+
+	size, err := writeAtCommitted(tableFilePath(tableName, dbInfo), tableData.storage.committedSize, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	tableData.storage.committedSize = size
+	tableData.storage.checksum = cw.sum
+	tableData.storage.hasLsn = true
+	tableData.storage.needsNewline = false
+	return nil
+}
+
+// writeAtCommitted는 path의 파일을 committedSize로 자른 뒤 data를 덧붙이고
+// 디스크에 동기화합니다. 새 파일 크기를 반환합니다.
+func writeAtCommitted(path string, committedSize int64, data []byte) (int64, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	if err := file.Truncate(committedSize); err != nil {
+		return 0, err
+	}
+	if _, err := file.Seek(committedSize, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if _, err := file.Write(data); err != nil {
+		return 0, err
+	}
+	if err := file.Sync(); err != nil {
+		return 0, err
+	}
+	return committedSize + int64(len(data)), nil
+}
+
+// compactTableIfNeeded는 대체되거나 삭제된 레코드가 충분히 쌓이면
+// 살아 있는 행만 남도록 테이블 파일을 다시 씁니다.
+// 바이너리 파일은 마지막 페이지에 들어가지 않는 묶음이 새 페이지를 쓰므로 페이지의 빈 공간이 쌓여도 다시 씁니다.
+func compactTableIfNeeded(tableData *TableData, tableName string, dbInfo dbinfo.DBInfo) error {
+	garbage := tableData.records - len(tableData.Rows)
+	if garbage >= compactMinGarbage && garbage > len(tableData.Rows) {
+		return saveTableData(tableData, tableName, dbInfo)
+	}
+
+	if tableData.storage.format == formatBinary {
+		st := tableData.storage
+		unused := st.committedSize - binaryPageSize - st.payload
+		if unused >= compactMinGarbage*binaryPageSize && unused > st.payload {
+			return saveTableData(tableData, tableName, dbInfo)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package dbcontroller
+
+import (
+	"fmt"
+	"os"
+	dbinfo "sedb/modules/db_info"
+	"strings"
+	"testing"
+)
+
+// readTableFile은 테이블 파일 내용을 문자열로 읽습니다.
+func readTableFile(t *testing.T, name string, info dbinfo.DBInfo) string {
+	t.Helper()
+	content, err := os.ReadFile(tableFilePath(name, info))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestMutationsAreAppended(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, text name);`)
+	mustExec(t, info, `add t (1, "kim");`)
+	mustExec(t, info, `add t (2, "lee");`)
+	before := readTableFile(t, "t", info)
+
+	mustExec(t, info, `update t 1 (1, "park");`)
+	mustExec(t, info, `delete t 2;`)
+	mustExec(t, info, `add t (3, "choi");`)
+	mustExec(t, info, `update t 3 (4, "choi");`)
+
+	// 기존 내용은 그대로 두고 대체 버전과 삭제 표시를 덧붙입니다.
+	after := readTableFile(t, "t", info)
+	if !strings.HasPrefix(after, before) {
+		t.Fatalf("table file was rewritten:\n%s", after)
+	}
+	appended := after[len(before):]
+	for _, line := range []string{`Data-> [1, "park"] ->End`, `Del-> [2] ->End`, `Del-> [3] ->End`, `Data-> [4, "choi"] ->End`} {
+		if !strings.Contains(appended, withLineChecksum(line)+"\n") {
+			t.Errorf("appended records do not contain %q:\n%s", line, appended)
+		}
+	}
+
+	// 행의 순서는 각 키의 마지막 버전이 기록된 순서입니다.
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, row := range tableData.Rows {
+		got = append(got, fmt.Sprintf("%s=%v", row.Key, row.Data["name"]))
+	}
+	if want := "1=park 4=choi"; strings.Join(got, " ") != want {
+		t.Errorf("rows = %v, want %s", got, want)
+	}
+}
+
+func TestUnfinishedAppendIsDropped(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, text name);`)
+	mustExec(t, info, `add t (1, "kim");`)
+
+	// Lsn-> 줄로 확정되지 않은 레코드는 기록이 끝나지 않은 것입니다.
+	file, err := os.OpenFile(tableFilePath("t", info), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("Data-> [9, \"torn\"] ->End\nDel-> [1")
+	file.Close()
+
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 1 || tableData.Rows[0].Key != "1" {
+		t.Fatalf("rows = %v, want only key 1", tableData.Rows)
+	}
+
+	// 다음 덧붙이기는 완료되지 않은 꼬리를 잘라낸 뒤 기록합니다.
+	mustExec(t, info, `add t (2, "lee");`)
+	if content := readTableFile(t, "t", info); strings.Contains(content, "torn") {
+		t.Errorf("unfinished records were not truncated:\n%s", content)
+	}
+	tableData, err = loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 2 {
+		t.Errorf("got %d rows, want 2", len(tableData.Rows))
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, integer n);`)
+	mustExec(t, info, `add t (1, 0);`)
+	mustExec(t, info, `add t (2, 0);`)
+	for i := 1; i <= compactMinGarbage+1; i++ {
+		mustExec(t, info, fmt.Sprintf(`update t 1 (1, %d);`, i))
+	}
+
+	// 쓸모없는 레코드가 기준을 넘으면 살아 있는 행만 남도록 다시 씁니다.
+	content := readTableFile(t, "t", info)
+	if n := strings.Count(content, "Data->"); n > 3 {
+		t.Errorf("table file has %d data records after compaction, want at most 3", n)
+	}
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 2 {
+		t.Fatalf("rows after compaction = %v", tableData.Rows)
+	}
+	for _, row := range tableData.Rows {
+		if row.Key == "1" && formatValue(row.Data["n"]) != fmt.Sprint(compactMinGarbage+1) {
+			t.Errorf("row 1 after compaction = %v", row.Data)
+		}
+	}
+}
+
+func TestLoadLegacyRows(t *testing.T) {
+	info := newTestDB(t, "legacy.tff")
+
+	tableData, err := loadTableData("legacy", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 5 {
+		t.Fatalf("got %d rows, want 5", len(tableData.Rows))
+	}
+
+	// 빈 칸은 TEXT 열에서는 빈 문자열, NUMBER 열에서는 NULL입니다. 값이 모자란 줄은 나머지 열이 NULL입니다.
+	// 이전 형식에는 NULL 표기가 없었으므로 따옴표 없는 NULL은 문자열입니다.
+	want := []map[string]interface{}{
+		{"id": 1.0, "name": "kim", "score": 10.0},
+		{"id": 2.0, "name": "", "score": 4.0},
+		{"id": 3.0, "name": "lee", "score": nil},
+		{"id": 4.0, "name": "park", "score": nil},
+		{"id": 5.0, "name": "NULL", "score": 2.0},
+	}
+	for i, row := range tableData.Rows {
+		if len(row.Data) != 3 {
+			t.Errorf("row %d has %d values, want 3", i, len(row.Data))
+		}
+		for col, value := range want[i] {
+			if row.Data[col] != value {
+				t.Errorf("row %d column %s = %#v, want %#v", i, col, row.Data[col], value)
+			}
+		}
+	}
+
+	// 새로 덧붙인 줄의 NULL은 NULL 값이고, 이전 형식 줄의 문자열은 그대로입니다.
+	mustExec(t, info, `add legacy (6, NULL, 1);`)
+	tableData, err = loadTableData("legacy", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range tableData.Rows {
+		if row.Key == "5" && row.Data["name"] != "NULL" || row.Key == "6" && row.Data["name"] != nil {
+			t.Errorf("row %s after append = %v", row.Key, row.Data)
+		}
+	}
+}
+
+func TestAppendKeepsTableData(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, text name UNIQUE);`)
+	mustExec(t, info, `add t (1, "kim");`)
+
+	// 기록을 마친 테이블 데이터를 보관하고 다음 명령이 그대로 이어서 씁니다.
+	cached := tableCache[tableCacheKey("t", info)]
+	if cached == nil {
+		t.Fatal("table data was not kept after ADD")
+	}
+	mustExec(t, info, `add t (2, "lee");`)
+	mustExec(t, info, `update t 2 (2, "park");`)
+	if next := tableCache[tableCacheKey("t", info)]; next == nil || next.data != cached.data {
+		t.Fatal("ADD and UPDATE reloaded the table instead of using the kept data")
+	}
+	if CmdExec(`add t (3, "park");`, info) == 0 {
+		t.Error("kept unique index missed a duplicate")
+	}
+
+	// 실패한 명령 뒤에는 파일에서 다시 읽습니다.
+	earlier := readTableFile(t, "t", info)
+	mustExec(t, info, `add t (3, "choi");`)
+	if next := tableCache[tableCacheKey("t", info)]; next == nil || next.data == cached.data {
+		t.Error("table data was kept after a failed command")
+	}
+
+	// 밖에서 바꾼 파일은 다시 읽습니다.
+	if err := os.WriteFile(tableFilePath("t", info), []byte(earlier), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mustExec(t, info, `add t (3, "jung");`)
+	checkValues(t, mustQuery(t, info, `select t;`), "name", "kim", "park", "jung")
+}
@@ -0,0 +1,565 @@
+package dbcontroller
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	dbinfo "sedb/modules/db_info"
+	"sedb/modules/parsers"
+	"sedb/modules/table"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// ResultSet은 SELECT의 결과입니다. Rows의 값은 Columns 순서이며,
+// NULL은 nil, 정수·실수·참거짓·문자열은 그대로, 그 밖의 타입은 정규화된 문자열 표기입니다.
+type ResultSet struct {
+	Table   string          `json:"table"`
+	Columns []string        `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+	Cursor  string          `json:"cursor,omitempty"` // LIMIT 뒤에 남은 행이 있으면 다음 페이지를 조회할 CURSOR 값
+}
+
+// selectQuery는 파싱한 SELECT 명령입니다.
+type selectQuery struct {
+	table    string
+	distinct bool
+	items    []*parsers.Expr // 결과 열: 열 이름 또는 집계 함수 (생략하면 nil)
+	where    *parsers.Expr   // 조건이 없으면 nil
+	groupBy  []string
+	having   *parsers.Expr // 조건이 없으면 nil
+	order    []sortKey
+	limit    int // LIMIT이 없으면 -1
+	offset   int
+	cursor   string // 이전 결과의 Cursor (없으면 빈 문자열)
+}
+
+// sortKey는 ORDER BY의 열 또는 집계 함수 하나입니다.
+type sortKey struct {
+	expr *parsers.Expr
+	desc bool
+}
+
+// aggregated는 행을 묶어 집계하는 질의인지 확인합니다.
+// GROUP BY나 HAVING이 있거나, 결과 열 또는 ORDER BY에 집계 함수가 있으면 집계 질의입니다.
+func (query *selectQuery) aggregated() bool {
+	if len(query.groupBy) > 0 || query.having != nil {
+		return true
+	}
+	for _, item := range query.items {
+		if item.Kind == parsers.EX_aggregate {
+			return true
+		}
+	}
+	for _, key := range query.order {
+		if key.expr.Kind == parsers.EX_aggregate {
+			return true
+		}
+	}
+	return false
+}
+
+// paged는 결과를 정렬해 페이지로 나누는 질의인지 확인합니다.
+func (query *selectQuery) paged() bool {
+	return len(query.order) > 0 || query.limit >= 0 || query.offset > 0 || query.cursor != ""
+}
+
+// cursorState는 CURSOR 값에 담는 마지막 행의 위치입니다.
+type cursorState struct {
+	Query  string    `json:"q"` // 질의의 지문: 다른 질의의 CURSOR를 거부하기 위함
+	Values []*string `json:"v"` // 정렬 열 값의 정규화된 표기 (NULL은 null)
+}
+
+// Query는 SELECT 명령 하나를 실행하고 결과를 반환합니다.
+func Query(script string, dbInfo dbinfo.DBInfo) (*ResultSet, error) {
+	execMu.Lock()
+	defer execMu.Unlock()
+
+	var tokens []parsers.SC_token
+	if parsers.Parsing_script(script, &tokens) != 0 {
+		return nil, errors.New("syntax error: failed to parse script")
+	}
+	if len(tokens) == 0 || tokens[0].Token_type != parsers.SC_select {
+		return nil, errors.New("syntax error: expected SELECT statement")
+	}
+	query, errMsg := parseSelect(tokens)
+	if errMsg != "" {
+		return nil, errors.New(errMsg)
+	}
+	return runSelect(query, dbInfo)
+}
+
+// parseSelect는 SELECT 명령 토큰을 읽습니다.
+// 문법: select distinct(선택) [테이블 이름] ([열 이름 또는 집계 함수], ...)(선택) where [조건식](선택)
+// group by [열 이름], ...(선택) having [조건식](선택) order by [열 이름 또는 집계 함수] asc|desc, ...(선택)
+// limit [행 수](선택) offset [행 수](선택) cursor "[CURSOR 값]"(선택);
+func parseSelect(tokens []parsers.SC_token) (*selectQuery, string) {
+	query := &selectQuery{limit: -1}
+	i := 1
+	if i < len(tokens) && tokens[i].Token_type == parsers.SC_distinct {
+		query.distinct = true
+		i++
+	}
+	if i >= len(tokens) || tokens[i].Token_type != parsers.SC_tableName {
+		return nil, "syntax error: expected table name"
+	}
+	query.table = tokens[i].Token.(string)
+	i++
+
+	if i < len(tokens) && tokens[i].Token_type == parsers.SC_parenOpen {
+		i++
+		for {
+			item, next, errMsg := parsers.ParseExpr(tokens, i)
+			if errMsg != "" {
+				return nil, errMsg
+			}
+			if item.Kind != parsers.EX_column && item.Kind != parsers.EX_aggregate {
+				return nil, fmt.Sprintf("syntax error: '%s' is not a column name or an aggregate", parsers.FormatExpr(item))
+			}
+			query.items = append(query.items, item)
+			i = next
+			if i < len(tokens) && tokens[i].Token_type == parsers.SC_comma {
+				i++
+				continue
+			}
+			break
+		}
+		if i >= len(tokens) || tokens[i].Token_type != parsers.SC_parenClose {
+			return nil, "syntax error: missing closing parenthesis in column list"
+		}
+		i++
+	}
+
+	if i < len(tokens) && tokens[i].Token_type == parsers.SC_where {
+		expr, next, errMsg := parsers.ParseExpr(tokens, i+1)
+		if errMsg != "" {
+			return nil, errMsg
+		}
+		query.where, i = expr, next
+	}
+
+	if i < len(tokens) && tokens[i].Token_type == parsers.SC_group {
+		if i+1 >= len(tokens) || tokens[i+1].Token_type != parsers.SC_by {
+			return nil, "syntax error: expected BY after GROUP"
+		}
+		i += 2
+		for {
+			if i >= len(tokens) || tokens[i].Token_type != parsers.SC_columnName {
+				return nil, "syntax error: expected column name in GROUP BY"
+			}
+			query.groupBy = append(query.groupBy, tokens[i].Token.(string))
+			i++
+			if i < len(tokens) && tokens[i].Token_type == parsers.SC_comma {
+				i++
+				continue
+			}
+			break
+		}
+	}
+
+	if i < len(tokens) && tokens[i].Token_type == parsers.SC_having {
+		expr, next, errMsg := parsers.ParseExpr(tokens, i+1)
+		if errMsg != "" {
+			return nil, errMsg
+		}
+		query.having, i = expr, next
+	}
+
+	if i < len(tokens) && tokens[i].Token_type == parsers.SC_order {
+		if i+1 >= len(tokens) || tokens[i+1].Token_type != parsers.SC_by {
+			return nil, "syntax error: expected BY after ORDER"
+		}
+		i += 2
+		for {
+			if i >= len(tokens) || (tokens[i].Token_type != parsers.SC_columnName && tokens[i].Token_type != parsers.SC_aggregate) {
+				return nil, "syntax error: expected column name or aggregate in ORDER BY"
+			}
+			expr, next, errMsg := parsers.ParseExpr(tokens, i)
+			if errMsg != "" {
+				return nil, errMsg
+			}
+			if expr.Kind != parsers.EX_column && expr.Kind != parsers.EX_aggregate {
+				return nil, fmt.Sprintf("syntax error: cannot order by '%s'", parsers.FormatExpr(expr))
+			}
+			key := sortKey{expr: expr}
+			i = next
+			if i < len(tokens) && tokens[i].Token_type == parsers.SC_sortDir {
+				key.desc = tokens[i].Token == "desc"
+				i++
+			}
+			query.order = append(query.order, key)
+			if i < len(tokens) && tokens[i].Token_type == parsers.SC_comma {
+				i++
+				continue
+			}
+			break
+		}
+	}
+
+	// LIMIT, OFFSET, CURSOR는 순서에 관계없이 한 번씩 쓸 수 있습니다.
+	seen := make(map[parsers.Sc_tokenT]bool)
+	for i < len(tokens) && tokens[i].Token_type != parsers.SC_endCmd {
+		tok := tokens[i]
+		switch tok.Token_type {
+		case parsers.SC_limit, parsers.SC_offset:
+			name := strings.ToUpper(tok.Token.(string))
+			if seen[tok.Token_type] {
+				return nil, fmt.Sprintf("syntax error: duplicate %s", name)
+			}
+			if i+1 >= len(tokens) || tokens[i+1].Token_type != parsers.SC_number {
+				return nil, fmt.Sprintf("syntax error: expected number after %s", name)
+			}
+			n, err := strconv.Atoi(tokens[i+1].Token.(string))
+			if err != nil || n < 0 {
+				return nil, fmt.Sprintf("syntax error: invalid %s '%v'", name, tokens[i+1].Token)
+			}
+			if tok.Token_type == parsers.SC_limit {
+				query.limit = n
+			} else {
+				query.offset = n
+			}
+		case parsers.SC_cursor:
+			if seen[tok.Token_type] {
+				return nil, "syntax error: duplicate CURSOR"
+			}
+			if i+1 >= len(tokens) || tokens[i+1].Token_type != parsers.SC_string {
+				return nil, "syntax error: expected cursor string after CURSOR"
+			}
+			query.cursor = tokens[i+1].Token.(string)
+		default:
+			return nil, fmt.Sprintf("syntax error: unexpected '%v' in SELECT statement", tok.Token)
+		}
+		seen[tok.Token_type] = true
+		i += 2
+	}
+	// CURSOR는 이전 페이지의 OFFSET을 이미 반영한 위치이므로 OFFSET과 함께 쓸 수 없습니다.
+	if seen[parsers.SC_offset] && seen[parsers.SC_cursor] {
+		return nil, "syntax error: OFFSET cannot be used with CURSOR"
+	}
+	return query, ""
+}
+
+// resultRows는 결과 열을 고르기 전의 질의 결과입니다.
+type resultRows struct {
+	columns []table.Column // ORDER BY와 HAVING에서 참조할 수 있는 열 (집계 값은 "COUNT(*)" 같은 이름의 열)
+	output  []table.Column // 결과에 보여줄 열
+	rows    []map[string]interface{}
+	unique  []table.Column // 행마다 값이 다른 열: 정렬의 마지막 기준
+}
+
+// projectRows는 집계하지 않는 질의의 결과입니다. 열 목록을 생략하면 모든 열을 보여줍니다.
+func projectRows(query *selectQuery, columns []table.Column, rows []map[string]interface{}) (*resultRows, error) {
+	res := &resultRows{columns: columns, output: columns, rows: rows, unique: keyColumns(columns)}
+	if len(query.items) > 0 {
+		res.output = make([]table.Column, 0, len(query.items))
+		for _, item := range query.items {
+			idx := findColumnIndex(columns, item.Name)
+			if idx < 0 {
+				return nil, fmt.Errorf("column '%s' does not exist", item.Name)
+			}
+			res.output = append(res.output, columns[idx])
+		}
+	}
+	return res, nil
+}
+
+// distinct는 결과 열의 값이 같은 행을 처음 나온 하나만 남깁니다. NULL끼리는 같은 값으로 봅니다.
+// 이후 ORDER BY는 결과 열만 참조할 수 있습니다.
+func (res *resultRows) distinct() {
+	seen := make(map[string]bool)
+	rows := make([]map[string]interface{}, 0, len(res.rows))
+	for _, data := range res.rows {
+		key := valuesKey(res.output, data)
+		if !seen[key] {
+			seen[key] = true
+			rows = append(rows, data)
+		}
+	}
+	res.rows, res.columns, res.unique = rows, res.output, res.output
+}
+
+// valuesKey는 행 데이터의 열 값들을 비교용 문자열로 만듭니다. 값의 표기는 데이터 줄과 같아 서로 구별됩니다.
+func valuesKey(columns []table.Column, data map[string]interface{}) string {
+	parts := make([]string, len(columns))
+	for i, col := range columns {
+		parts[i] = formatTffValue(col, data[col.Name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// runSelect는 테이블을 불러와 조건이 참인 행을 반환합니다. 조건이 NULL 비교로 unknown인 행은 포함하지 않습니다.
+// 집계 질의는 행을 GROUP BY 열 값으로 묶어 묶음마다 한 행을 반환합니다.
+// ORDER BY, LIMIT, OFFSET, CURSOR가 없으면 파일에 기록된 순서(집계 질의는 묶음이 처음 나온 순서)이고,
+// 있으면 ORDER BY 뒤에 행마다 값이 다른 열(키 열, GROUP BY 열, DISTINCT의 결과 열)을 붙인 순서로 정렬합니다.
+func runSelect(query *selectQuery, dbInfo dbinfo.DBInfo) (*ResultSet, error) {
+	if !tableExists(query.table, dbInfo) {
+		return nil, fmt.Errorf("table '%s' does not exist", query.table)
+	}
+	tableData, err := loadTableData(query.table, dbInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load table: %v", err)
+	}
+	if query.where != nil {
+		if err := bindCondition(query.where, tableData.Columns); err != nil {
+			return nil, fmt.Errorf("invalid WHERE condition: %v", err)
+		}
+	}
+
+	var matched []map[string]interface{}
+	for _, row := range tableData.Rows {
+		if query.where != nil {
+			t, err := evalCondition(query.where, tableData.Columns, row.Data)
+			if err != nil {
+				return nil, fmt.Errorf("row '%s': %v", row.Key, err)
+			}
+			if t != truthTrue {
+				continue
+			}
+		}
+		matched = append(matched, row.Data)
+	}
+
+	var res *resultRows
+	if query.aggregated() {
+		res, err = groupRows(query, tableData.Columns, matched)
+	} else {
+		res, err = projectRows(query, tableData.Columns, matched)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if query.distinct {
+		res.distinct()
+	}
+	order, err := res.sortColumns(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := res.rows
+	var next string
+	if query.paged() {
+		sort.SliceStable(rows, func(i, j int) bool {
+			return compareSortValues(order, sortValues(order, rows[i]), sortValues(order, rows[j])) < 0
+		})
+		fingerprint := queryFingerprint(query, order)
+
+		// CURSOR는 마지막으로 반환한 행의 정렬 값이므로, 그 뒤의 행부터 이어서 반환합니다.
+		// 키 열까지 정렬 값에 포함하므로 그 사이에 추가된 행이 있어도 이미 반환한 행을 다시 반환하지 않습니다.
+		if query.cursor != "" {
+			after, err := decodeCursor(query.cursor, fingerprint, order)
+			if err != nil {
+				return nil, err
+			}
+			start := sort.Search(len(rows), func(i int) bool {
+				return compareSortValues(order, sortValues(order, rows[i]), after) > 0
+			})
+			rows = rows[start:]
+		}
+
+		rows = rows[min(query.offset, len(rows)):]
+		if query.limit >= 0 && query.limit < len(rows) {
+			rows = rows[:query.limit]
+			if len(rows) > 0 {
+				next, err = encodeCursor(fingerprint, sortValues(order, rows[len(rows)-1]))
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	result := &ResultSet{
+		Table:   query.table,
+		Columns: make([]string, len(res.output)),
+		Rows:    make([][]interface{}, 0, len(rows)),
+		Cursor:  next,
+	}
+	for i, col := range res.output {
+		result.Columns[i] = col.Name
+	}
+	for _, data := range rows {
+		values := make([]interface{}, len(res.output))
+		for i, col := range res.output {
+			values[i] = resultValue(data[col.Name])
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return result, nil
+}
+
+// sortColumn은 정렬에 쓰는 열과 방향입니다.
+type sortColumn struct {
+	col  table.Column
+	desc bool
+}
+
+// sortColumns는 ORDER BY 열 뒤에 아직 없는 unique 열을 오름차순으로 붙여, 모든 행의 순서가 정해지게 합니다.
+// 집계 함수는 같은 표기의 집계 결과 열입니다.
+func (res *resultRows) sortColumns(query *selectQuery) ([]sortColumn, error) {
+	order := make([]sortColumn, 0, len(query.order)+len(res.unique))
+	used := make(map[string]bool)
+	for _, key := range query.order {
+		name := parsers.FormatExpr(key.expr)
+		idx := findColumnIndex(res.columns, name)
+		if idx < 0 {
+			if query.aggregated() || query.distinct {
+				return nil, fmt.Errorf("cannot order by %s; it is not in the result", name)
+			}
+			return nil, fmt.Errorf("column '%s' does not exist", name)
+		}
+		if used[name] {
+			return nil, fmt.Errorf("%s appears more than once in ORDER BY", name)
+		}
+		used[name] = true
+		order = append(order, sortColumn{col: res.columns[idx], desc: key.desc})
+	}
+	for _, col := range res.unique {
+		if !used[col.Name] {
+			order = append(order, sortColumn{col: col})
+		}
+	}
+	return order, nil
+}
+
+// sortValues는 행 데이터에서 정렬 열의 값을 꺼냅니다.
+func sortValues(order []sortColumn, data map[string]interface{}) []interface{} {
+	values := make([]interface{}, len(order))
+	for i, sc := range order {
+		values[i] = data[sc.col.Name]
+	}
+	return values
+}
+
+// compareSortValues는 정렬 값 두 개를 비교합니다. 값은 열 타입에 맞게 비교하며 NULL은 가장 작은 값입니다.
+func compareSortValues(order []sortColumn, a, b []interface{}) int {
+	for i, sc := range order {
+		c := compareValues(a[i], b[i])
+		if sc.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// queryFingerprint는 테이블, 결과 열, 조건식, 묶음, 정렬 순서로 질의의 지문을 만듭니다.
+func queryFingerprint(query *selectQuery, order []sortColumn) string {
+	var sb strings.Builder
+	sb.WriteString(query.table)
+	if query.distinct {
+		sb.WriteString(" DISTINCT")
+	}
+	for _, item := range query.items {
+		sb.WriteString("\n" + parsers.FormatExpr(item))
+	}
+	sb.WriteString("\n")
+	if query.where != nil {
+		sb.WriteString(parsers.FormatExpr(query.where))
+	}
+	sb.WriteString("\n" + strings.Join(query.groupBy, ", ") + "\n")
+	if query.having != nil {
+		sb.WriteString(parsers.FormatExpr(query.having))
+	}
+	for _, sc := range order {
+		sb.WriteString("\n" + sc.col.Name)
+		if sc.desc {
+			sb.WriteString(" DESC")
+		}
+	}
+	sum := sha256.Sum256([]byte(sb.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// encodeCursor는 마지막 행의 정렬 값을 CURSOR 문자열로 만듭니다. 값은 URL에 쓸 수 있는 base64입니다.
+func encodeCursor(fingerprint string, values []interface{}) (string, error) {
+	state := cursorState{Query: fingerprint, Values: make([]*string, len(values))}
+	for i, value := range values {
+		if value != nil {
+			s := formatValue(value)
+			state.Values[i] = &s
+		}
+	}
+	content, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(content), nil
+}
+
+// decodeCursor는 CURSOR 문자열을 정렬 값으로 되돌립니다. 다른 질의에서 받은 CURSOR는 거부합니다.
+func decodeCursor(cursor, fingerprint string, order []sortColumn) ([]interface{}, error) {
+	content, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	var state cursorState
+	if err := json.Unmarshal(content, &state); err != nil || len(state.Values) != len(order) {
+		return nil, errors.New("invalid cursor")
+	}
+	if state.Query != fingerprint {
+		return nil, errors.New("cursor does not belong to this query")
+	}
+
+	values := make([]interface{}, len(order))
+	for i, raw := range state.Values {
+		if raw == nil {
+			continue
+		}
+		value, err := convertValue(order[i].col, *raw)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// resultValue는 행의 값을 ResultSet에 담을 값으로 바꿉니다.
+func resultValue(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, int64, float64, bool, string:
+		return value
+	}
+	return formatValue(value)
+}
+
+// handleSelect는 SELECT 명령을 처리합니다. WHERE를 생략하면 모든 행을, 열 목록을 생략하면 모든 열을 보여줍니다.
+// LIMIT 뒤에 남은 행이 있으면 다음 페이지를 조회할 CURSOR 값을 함께 보여줍니다.
+func handleSelect(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
+	query, errMsg := parseSelect(tokens)
+	if errMsg != "" {
+		return printError(errMsg)
+	}
+	result, err := runSelect(query, dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: %v", err))
+	}
+
+	fmt.Printf("%d row(s) from table '%s':\n", len(result.Rows), result.Table)
+	if len(result.Rows) == 0 {
+		return 0
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  %s\n", strings.Join(result.Columns, "\t"))
+	for _, values := range result.Rows {
+		cells := make([]string, len(values))
+		for i, value := range values {
+			cells[i] = formatValue(value)
+		}
+		fmt.Fprintf(w, "  %s\n", strings.Join(cells, "\t"))
+	}
+	w.Flush()
+	if result.Cursor != "" {
+		fmt.Printf("More rows: cursor \"%s\"\n", result.Cursor)
+	}
+	return 0
+}
@@ -0,0 +1,166 @@
+package dbcontroller
+
+import (
+	dbinfo "sedb/modules/db_info"
+	"testing"
+)
+
+// newUsersDB는 조회 테스트에 쓰는 users 테이블을 만듭니다.
+func newUsersDB(t *testing.T) dbinfo.DBInfo {
+	t.Helper()
+	info := newTestDB(t)
+	mustExec(t, info, `create_table users (integer id NOTNULL KEY, text name, integer age);`)
+	mustExec(t, info, `add users (1, "kim", 30);`)
+	mustExec(t, info, `add users (2, "choi", 41);`)
+	mustExec(t, info, `add users (3, NULL, 25);`)
+	mustExec(t, info, `add users (4, "lee", NULL);`)
+	mustExec(t, info, `add users (5, "park", 30);`)
+	return info
+}
+
+// mustQuery는 SELECT 명령을 실행하고 결과를 반환합니다.
+func mustQuery(t *testing.T, info dbinfo.DBInfo, script string) *ResultSet {
+	t.Helper()
+	res, err := Query(script, info)
+	if err != nil {
+		t.Fatalf("%s: %v", script, err)
+	}
+	return res
+}
+
+// columnValues는 결과의 한 열 값을 행 순서대로 모읍니다.
+func columnValues(t *testing.T, res *ResultSet, column string) []interface{} {
+	t.Helper()
+	idx := -1
+	for i, name := range res.Columns {
+		if name == column {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		t.Fatalf("column '%s' not in %v", column, res.Columns)
+	}
+	values := make([]interface{}, len(res.Rows))
+	for i, row := range res.Rows {
+		values[i] = row[idx]
+	}
+	return values
+}
+
+// checkValues는 결과의 한 열 값이 want와 같은지 확인합니다.
+func checkValues(t *testing.T, res *ResultSet, column string, want ...interface{}) {
+	t.Helper()
+	got := columnValues(t, res, column)
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %v, want %v", column, got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("%s: got %v, want %v", column, got, want)
+		}
+	}
+}
+
+func TestSelectWhere(t *testing.T) {
+	info := newUsersDB(t)
+
+	cases := []struct {
+		script string
+		ids    []interface{}
+	}{
+		{`select users;`, []interface{}{int64(1), int64(2), int64(3), int64(4), int64(5)}},
+		{`select users where age > 28;`, []interface{}{int64(1), int64(2), int64(5)}},
+		// NULL과의 비교는 unknown이므로 NOT을 붙여도 그 행은 나오지 않습니다.
+		{`select users where not age > 28;`, []interface{}{int64(3)}},
+		{`select users where name is null;`, []interface{}{int64(3)}},
+		{`select users where age is not null and name in ("kim", "choi");`, []interface{}{int64(1), int64(2)}},
+		{`select users where age < 26 or name = "lee";`, []interface{}{int64(3), int64(4)}},
+		{`select users where age = 30 and not (name = "kim");`, []interface{}{int64(5)}},
+	}
+	for _, c := range cases {
+		checkValues(t, mustQuery(t, info, c.script), "id", c.ids...)
+	}
+}
+
+func TestSelectWhereErrors(t *testing.T) {
+	info := newUsersDB(t)
+
+	for _, script := range []string{
+		`select missing;`,
+		`select users where height > 1;`,
+		`select users where age > "old";`,
+		`select users where age;`,
+		`select users order by height;`,
+		`select users limit -1;`,
+		`select users limit 1 limit 2;`,
+	} {
+		if _, err := Query(script, info); err == nil {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+}
+
+func TestSelectOrderAndPaging(t *testing.T) {
+	info := newUsersDB(t)
+
+	// 같은 나이는 키 순서이고, NULL은 가장 작은 값입니다.
+	res := mustQuery(t, info, `select users order by age desc;`)
+	checkValues(t, res, "id", int64(2), int64(1), int64(5), int64(3), int64(4))
+	res = mustQuery(t, info, `select users order by age;`)
+	checkValues(t, res, "id", int64(4), int64(3), int64(1), int64(5), int64(2))
+
+	res = mustQuery(t, info, `select users order by age limit 2 offset 1;`)
+	checkValues(t, res, "id", int64(3), int64(1))
+	if res.Cursor == "" {
+		t.Error("expected a cursor for the remaining rows")
+	}
+
+	res = mustQuery(t, info, `select users limit 10;`)
+	if res.Cursor != "" {
+		t.Errorf("unexpected cursor %q when no rows remain", res.Cursor)
+	}
+}
+
+func TestSelectCursor(t *testing.T) {
+	info := newUsersDB(t)
+
+	first := mustQuery(t, info, `select users order by age limit 2;`)
+	checkValues(t, first, "id", int64(4), int64(3))
+
+	// 페이지 사이에 지나간 위치에 추가한 행은 보이지 않고, 남은 행은 건너뛰지 않습니다.
+	mustExec(t, info, `add users (6, "jung", 20);`)
+	mustExec(t, info, `add users (7, "han", 35);`)
+	second := mustQuery(t, info, `select users order by age limit 2 cursor "`+first.Cursor+`";`)
+	checkValues(t, second, "id", int64(1), int64(5))
+	third := mustQuery(t, info, `select users order by age limit 2 cursor "`+second.Cursor+`";`)
+	checkValues(t, third, "id", int64(7), int64(2))
+	if third.Cursor != "" {
+		t.Errorf("unexpected cursor %q on the last page", third.Cursor)
+	}
+
+	// 다른 질의의 CURSOR 값과 잘못된 CURSOR 값은 거부합니다.
+	for _, script := range []string{
+		`select users order by age desc limit 2 cursor "` + first.Cursor + `";`,
+		`select users where age > 0 order by age limit 2 cursor "` + first.Cursor + `";`,
+		`select users order by age limit 2 cursor "not a cursor";`,
+		// CURSOR 위치에서 다시 OFFSET만큼 건너뛰면 행을 빠뜨리므로 함께 쓸 수 없습니다.
+		`select users order by age limit 2 offset 1 cursor "` + first.Cursor + `";`,
+		`select users order by age cursor "` + first.Cursor + `" offset 1;`,
+	} {
+		if _, err := Query(script, info); err == nil {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+}
+
+func TestSelectContextKeywordNames(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table order (integer id NOTNULL KEY, integer limit, text by);`)
+	mustExec(t, info, `add order (1, 5, "a");`)
+	mustExec(t, info, `add order (2, 7, "b");`)
+	mustExec(t, info, `add order (3, 9, "c");`)
+
+	res := mustQuery(t, info, `select order (limit, by) where limit > 5 order by by desc limit 1;`)
+	checkValues(t, res, "by", "c")
+	checkValues(t, res, "limit", int64(9))
+}
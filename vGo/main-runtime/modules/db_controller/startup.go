@@ -0,0 +1,51 @@
+package dbcontroller
+
+import (
+	"fmt"
+	dbinfo "sedb/modules/db_info"
+)
+
+// Startup은 런타임 시작 시 데이터베이스 디렉토리를 점검하고 복구합니다.
+// Returns: 0 on success, 1 on error
+func Startup(dbInfo dbinfo.DBInfo) int {
+	execMu.Lock()
+	defer execMu.Unlock()
+
+	// 보관한 테이블 데이터는 이전 실행의 것이므로 파일에서 다시 읽습니다.
+	resetTableCache()
+
+	// 중단된 원자적 쓰기의 임시 파일 정리: 테이블 파일(tables), 카탈로그와 WAL(데이터베이스 디렉토리),
+	// 검사에서 격리한 내용(archive)
+	removed, err := cleanupTempFiles(tablesDirPath(dbInfo), dbDirPath(dbInfo), archiveDirPath(dbInfo))
+	if err != nil {
+		return printError(fmt.Sprintf("error: failed to clean up temp files: %v", err))
+	}
+	for _, path := range removed {
+		fmt.Printf("Removed incomplete write '%s'\n", path)
+	}
+
+	// 바이너리 테이블의 마지막 페이지를 다시 쓰다 중단된 경우 이전 페이지로 되돌림
+	restored, err := restorePageImages(tablesDirPath(dbInfo))
+	if err != nil {
+		return printError(fmt.Sprintf("error: failed to restore table pages: %v", err))
+	}
+	for _, path := range restored {
+		fmt.Printf("Restored interrupted page write in '%s'\n", path)
+	}
+
+	// 카탈로그를 테이블 파일에 맞춤 (테이블 파일을 기록한 뒤 카탈로그를 고치기 전에 중단된 경우 등)
+	changes, err := reconcileCatalog(dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: failed to reconcile catalog: %v", err))
+	}
+	for _, change := range changes {
+		fmt.Printf("Catalog: %s\n", change)
+	}
+
+	// 완료되지 않은 WAL 레코드를 재실행 또는 폐기하고 체크포인트
+	if err := replayWal(dbInfo); err != nil {
+		return printError(fmt.Sprintf("error: failed to replay write-ahead log: %v", err))
+	}
+
+	return 0
+}
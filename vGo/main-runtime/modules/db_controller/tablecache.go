@@ -0,0 +1,59 @@
+package dbcontroller
+
+import (
+	"os"
+	"path/filepath"
+	dbinfo "sedb/modules/db_info"
+)
+
+// cachedTable은 명령 사이에 보관하는 테이블 데이터와 보관할 때의 테이블 파일 정보입니다.
+type cachedTable struct {
+	data *TableData
+	file os.FileInfo
+}
+
+// tableCache는 ADD, UPDATE, DELETE가 명령마다 테이블 파일 전체를 다시 읽지 않도록
+// 마지막으로 기록한 테이블 데이터(행, 유일 인덱스, AUTO_INCREMENT 카운터, 덧붙일 위치)를 보관합니다.
+// 키는 테이블 파일의 절대 경로이며, execMu를 잡은 상태에서만 사용합니다.
+var tableCache = make(map[string]*cachedTable)
+
+// tableCacheKey는 테이블의 보관 키를 반환합니다.
+func tableCacheKey(tableName string, dbInfo dbinfo.DBInfo) string {
+	path := tableFilePath(tableName, dbInfo)
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// takeTableData는 보관한 테이블 데이터를 꺼내 반환합니다.
+// 보관한 것이 없거나 그 뒤에 파일이 바뀌었으면(다른 명령이 다시 썼거나 밖에서 고친 경우) 파일에서 불러옵니다.
+// 꺼낸 데이터는 보관소에서 빠지므로, 명령이 성공한 뒤 keepTableData로 다시 보관합니다.
+// 실패한 명령이 메모리에서 일부만 바꾼 데이터는 보관하지 않고 다음 명령이 파일에서 다시 읽습니다.
+func takeTableData(tableName string, dbInfo dbinfo.DBInfo) (*TableData, error) {
+	key := tableCacheKey(tableName, dbInfo)
+	cached, ok := tableCache[key]
+	delete(tableCache, key)
+	if ok {
+		info, err := os.Stat(tableFilePath(tableName, dbInfo))
+		if err == nil && os.SameFile(info, cached.file) &&
+			info.Size() == cached.file.Size() && info.ModTime().Equal(cached.file.ModTime()) {
+			return cached.data, nil
+		}
+	}
+	return loadTableData(tableName, dbInfo)
+}
+
+// keepTableData는 기록을 마친 테이블 데이터를 현재 파일 정보와 함께 보관합니다.
+func keepTableData(tableName string, tableData *TableData, dbInfo dbinfo.DBInfo) {
+	info, err := os.Stat(tableFilePath(tableName, dbInfo))
+	if err != nil {
+		return
+	}
+	tableCache[tableCacheKey(tableName, dbInfo)] = &cachedTable{data: tableData, file: info}
+}
+
+// resetTableCache는 보관한 테이블 데이터를 모두 버립니다.
+func resetTableCache() {
+	tableCache = make(map[string]*cachedTable)
+}
@@ -0,0 +1,87 @@
+package dbcontroller
+
+import (
+	"fmt"
+	indexsystem "sedb/modules/index_system"
+	"sedb/modules/table"
+)
+
+// uniqueIndex는 UNIQUE 열의 유일 인덱스를 반환합니다.
+// 인덱스는 테이블 데이터와 함께 명령 사이에 보관되므로(tableCache), 행을 훑어 만드는 것은
+// 테이블을 파일에서 불러온 뒤 처음 요청할 때 한 번뿐이고 이후에는 reindexUnique가 기록한 행만 반영합니다.
+// NULL은 인덱스에 넣지 않으므로 여러 행이 NULL을 가질 수 있습니다.
+func uniqueIndex(tableData *TableData, col table.Column) *indexsystem.Unique_index {
+	if tableData.unique == nil {
+		tableData.unique = make(map[string]*indexsystem.Unique_index)
+	}
+	if idx, ok := tableData.unique[col.Name]; ok {
+		return idx
+	}
+
+	idx := &indexsystem.Unique_index{}
+	indexsystem.NewUniqueIndex(col.Name, idx)
+	for _, row := range tableData.Rows {
+		if value := row.Data[col.Name]; value != nil {
+			indexsystem.Insert(idx, formatValue(value), row.Key)
+		}
+	}
+	tableData.unique[col.Name] = idx
+	return idx
+}
+
+// checkUnique는 values(열 순서의 값)가 UNIQUE 제약을 어기는지 확인합니다.
+// rowKey는 값을 가질 행의 키이며, UPDATE에서는 이전 키를 넘겨 자기 자신과의 충돌을 제외합니다.
+func checkUnique(tableData *TableData, values []interface{}, rowKey string) error {
+	for i, col := range tableData.Columns {
+		if !col.Unique || values[i] == nil {
+			continue
+		}
+		value := formatValue(values[i])
+		if owner, ok := indexsystem.Lookup(uniqueIndex(tableData, col), value); ok && owner != rowKey {
+			return fmt.Errorf("unique constraint violated: value '%s' already exists in column '%s' (row '%s')",
+				value, col.Name, owner)
+		}
+	}
+	return nil
+}
+
+// reindexUnique는 행 하나가 기록된 뒤 유일 인덱스를 갱신합니다.
+// oldRow가 nil이면 새 행이고, newRow가 nil이면 삭제된 행입니다.
+func reindexUnique(tableData *TableData, oldRow *Row, newRow *Row) {
+	for _, col := range tableData.Columns {
+		if !col.Unique {
+			continue
+		}
+		idx, ok := tableData.unique[col.Name]
+		if !ok {
+			continue // 아직 만들지 않은 인덱스는 필요할 때 행에서 만듭니다.
+		}
+		if oldRow != nil && oldRow.Data[col.Name] != nil {
+			indexsystem.Remove(idx, formatValue(oldRow.Data[col.Name]), oldRow.Key)
+		}
+		if newRow != nil && newRow.Data[col.Name] != nil {
+			indexsystem.Insert(idx, formatValue(newRow.Data[col.Name]), newRow.Key)
+		}
+	}
+}
+
+// findUniqueDuplicate는 rows에서 UNIQUE 열 col의 값이 겹치는 첫 행의 위치를 찾습니다.
+// 없으면 -1입니다. values가 nil이 아니면 행의 값 대신 values[r]을 검사합니다.
+func findUniqueDuplicate(rows []Row, col table.Column, values []interface{}) (int, string) {
+	seen := make(map[string]bool)
+	for r, row := range rows {
+		value := row.Data[col.Name]
+		if values != nil {
+			value = values[r]
+		}
+		if value == nil {
+			continue
+		}
+		text := formatValue(value)
+		if seen[text] {
+			return r, text
+		}
+		seen[text] = true
+	}
+	return -1, ""
+}
@@ -0,0 +1,81 @@
+package dbcontroller
+
+import "testing"
+
+func TestUniqueConstraint(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table users (text id NOTNULL KEY, text email UNIQUE, decimal(2) code UNIQUE);`)
+	mustExec(t, info, `add users ("u1", "kim@example.com", 1.5);`)
+	mustExec(t, info, `add users ("u2", NULL, NULL);`)
+	// NULL은 비교하지 않으므로 여러 행이 NULL을 가질 수 있습니다.
+	mustExec(t, info, `add users ("u3", NULL, NULL);`)
+
+	for _, script := range []string{
+		`add users ("u4", "kim@example.com", 2);`,
+		// 1.5와 1.50은 정규화된 표기가 같습니다.
+		`add users ("u4", "lee@example.com", 1.50);`,
+		`update users "u2" ("u2", "kim@example.com", NULL);`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected unique violation", script)
+		}
+	}
+
+	// 수정하는 행 자신의 이전 값과는 겹쳐도 됩니다.
+	mustExec(t, info, `update users "u1" ("u1", "kim@example.com", 2);`)
+	mustExec(t, info, `add users ("u4", "lee@example.com", 1.5);`)
+	// 삭제한 행의 값은 다시 쓸 수 있습니다.
+	mustExec(t, info, `delete users "u1";`)
+	mustExec(t, info, `add users ("u5", "kim@example.com", NULL);`)
+
+	if CmdExec("verify;", info) != 0 {
+		t.Error("verify reported problems in a table without duplicates")
+	}
+}
+
+func TestUniqueConstraintErrors(t *testing.T) {
+	info := newTestDB(t)
+	if CmdExec(`create_table t (integer id NOTNULL KEY UNIQUE);`, info) == 0 {
+		t.Error("KEY column accepted UNIQUE")
+	}
+
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, text name, integer n);`)
+	mustExec(t, info, `add t (1, "kim", 1);`)
+	mustExec(t, info, `add t (2, "kim", 1);`)
+	if CmdExec(`alter_table t add_column text code UNIQUE default "x";`, info) == 0 {
+		t.Error("added a UNIQUE column filled with the same literal default")
+	}
+	mustExec(t, info, `alter_table t add_column text code UNIQUE default UUID();`)
+	mustExec(t, info, `alter_table t add_column text note UNIQUE;`)
+
+	// "1"과 "01"은 INTEGER로 바꾸면 같은 값이 됩니다.
+	mustExec(t, info, `update t 1 (1, "kim", 1, "a", "1");`)
+	mustExec(t, info, `update t 2 (2, "kim", 1, "b", "01");`)
+	if CmdExec(`alter_table t modify_column note integer;`, info) == 0 {
+		t.Error("modify_column made UNIQUE values collide")
+	}
+}
+
+func TestUniqueIndexIsKept(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table users (text id NOTNULL KEY, text email UNIQUE);`)
+	mustExec(t, info, `add users ("u1", "kim@example.com");`)
+
+	kept := tableCache[tableCacheKey("users", info)]
+	if kept == nil || kept.data.unique["email"] == nil {
+		t.Fatal("unique index was not kept with the table data")
+	}
+	idx := kept.data.unique["email"]
+
+	// 다음 명령은 같은 인덱스를 기록한 행만큼 갱신합니다.
+	mustExec(t, info, `add users ("u2", "lee@example.com");`)
+	mustExec(t, info, `update users "u1" ("u1", "park@example.com");`)
+	mustExec(t, info, `delete users "u2";`)
+	if now := tableCache[tableCacheKey("users", info)]; now == nil || now.data.unique["email"] != idx {
+		t.Fatal("unique index was rebuilt between commands")
+	}
+	want := map[string]string{"park@example.com": "u1"}
+	if len(idx.Entries) != len(want) || idx.Entries["park@example.com"] != "u1" {
+		t.Errorf("index entries = %v, want %v", idx.Entries, want)
+	}
+}
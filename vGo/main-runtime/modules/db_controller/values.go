@@ -0,0 +1,381 @@
+package dbcontroller
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sedb/modules/table"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decimal은 고정 소수 자릿수의 정확한 10진수 값입니다.
+// 값은 Unscaled / 10^Scale 입니다.
+type Decimal struct {
+	Unscaled *big.Int
+	Scale    int
+}
+
+// String은 소수 자릿수를 모두 채운 10진수 문자열을 반환합니다. 예) 3.50
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.Unscaled).String()
+	sign := ""
+	if d.Unscaled.Sign() < 0 {
+		sign = "-"
+	}
+	if d.Scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= d.Scale {
+		digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-d.Scale] + "." + digits[len(digits)-d.Scale:]
+}
+
+// Cmp는 두 Decimal을 비교하여 -1, 0, 1을 반환합니다.
+func (d Decimal) Cmp(other Decimal) int {
+	a, b := d.Unscaled, other.Unscaled
+	if d.Scale < other.Scale {
+		a = new(big.Int).Mul(a, pow10(other.Scale-d.Scale))
+	} else if d.Scale > other.Scale {
+		b = new(big.Int).Mul(b, pow10(d.Scale-other.Scale))
+	}
+	return a.Cmp(b)
+}
+
+// pow10은 10^n을 반환합니다.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// Date는 시간대가 없는 날짜 값입니다.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// String은 YYYY-MM-DD 형식의 날짜 문자열을 반환합니다.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, int(d.Month), d.Day)
+}
+
+// Cmp는 두 Date를 비교하여 -1, 0, 1을 반환합니다.
+func (d Date) Cmp(other Date) int {
+	a := d.Year*10000 + int(d.Month)*100 + d.Day
+	b := other.Year*10000 + int(other.Month)*100 + other.Day
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// parseDate는 YYYY-MM-DD 형식의 날짜를 읽습니다. 존재하지 않는 날짜는 오류입니다.
+func parseDate(s string) (Date, error) {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date{Year: t.Year(), Month: t.Month(), Day: t.Day()}, nil
+}
+
+// timestampLayouts는 TIMESTAMP 값으로 받는 형식입니다.
+// 시간대가 없는 형식은 UTC로 해석합니다.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02", // 날짜만 있으면 그날 0시 (UTC)
+}
+
+// parseTimestamp는 시각을 읽어 UTC로 변환합니다.
+// 예) 2024-03-01T09:00:00+09:00 은 2024-03-01T00:00:00Z 와 같은 값입니다.
+func parseTimestamp(s string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("expected RFC 3339 timestamp")
+}
+
+// parseBlob은 0x로 시작하는 16진수 표기를 바이트로 변환합니다.
+func parseBlob(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return nil, fmt.Errorf("expected 0x prefix")
+	}
+	return hex.DecodeString(s[2:])
+}
+
+// parseDecimal은 10진수 문자열을 scale 자릿수의 Decimal로 변환합니다.
+// 소수 자릿수가 scale보다 많으면 반올림하지 않고 오류를 반환합니다.
+func parseDecimal(s string, scale int) (Decimal, error) {
+	neg := false
+	if strings.HasPrefix(s, "-") {
+		neg = true
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if dot := strings.IndexByte(s, '.'); dot != -1 {
+		intPart, fracPart = s[:dot], s[dot+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return Decimal{}, fmt.Errorf("empty decimal")
+	}
+	for _, part := range []string{intPart, fracPart} {
+		for i := 0; i < len(part); i++ {
+			if part[i] < '0' || part[i] > '9' {
+				return Decimal{}, fmt.Errorf("not a decimal number")
+			}
+		}
+	}
+
+	// 자릿수를 넘는 0은 값에 영향이 없으므로 허용
+	trimmed := strings.TrimRight(fracPart, "0")
+	if len(trimmed) > scale {
+		return Decimal{}, fmt.Errorf("more than %d decimal places", scale)
+	}
+	if len(fracPart) > scale {
+		fracPart = fracPart[:scale]
+	}
+	fracPart += strings.Repeat("0", scale-len(fracPart))
+
+	unscaled, ok := new(big.Int).SetString("0"+intPart+fracPart, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("not a decimal number")
+	}
+	if neg {
+		unscaled.Neg(unscaled)
+	}
+	return Decimal{Unscaled: unscaled, Scale: scale}, nil
+}
+
+// convertValue는 문자열 값을 열 타입에 맞는 값으로 변환합니다.
+//   - CT_integer: int64
+//   - CT_float, CT_number: float64 (유한한 값만 허용)
+//   - CT_decimal: Decimal
+//   - CT_text: string
+//   - CT_bool: bool (true, false만 허용)
+//   - CT_date: Date
+//   - CT_timestamp: time.Time (UTC)
+//   - CT_blob: []byte
+//   - CT_json: string (유효한 JSON만 허용, 원문 그대로 보존)
+func convertValue(col table.Column, raw string) (interface{}, error) {
+	switch col.Type {
+	case table.CT_integer:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer format for column '%s': %s", col.Name, raw)
+		}
+		return n, nil
+	case table.CT_float, table.CT_number:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("invalid number format for column '%s': %s", col.Name, raw)
+		}
+		return f, nil
+	case table.CT_decimal:
+		d, err := parseDecimal(raw, col.Scale)
+		if err != nil {
+			return nil, fmt.Errorf("invalid decimal format for column '%s': %s (%v)", col.Name, raw, err)
+		}
+		return d, nil
+	case table.CT_text:
+		return raw, nil
+	case table.CT_bool:
+		// strconv.ParseBool과 달리 1, t, TRUE 등은 받지 않습니다.
+		switch raw {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid boolean format for column '%s': %s (expected true or false)", col.Name, raw)
+	case table.CT_date:
+		d, err := parseDate(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date format for column '%s': %s (expected YYYY-MM-DD)", col.Name, raw)
+		}
+		return d, nil
+	case table.CT_timestamp:
+		t, err := parseTimestamp(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp format for column '%s': %s (%v)", col.Name, raw, err)
+		}
+		return t, nil
+	case table.CT_blob:
+		b, err := parseBlob(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blob format for column '%s': %s (%v)", col.Name, raw, err)
+		}
+		return b, nil
+	case table.CT_json:
+		if !json.Valid([]byte(raw)) {
+			return nil, fmt.Errorf("invalid JSON for column '%s': %s", col.Name, raw)
+		}
+		return raw, nil
+	}
+	return nil, fmt.Errorf("unknown type for column '%s'", col.Name)
+}
+
+// formatValue는 값을 정규화된 문자열로 변환합니다.
+// 실수는 다시 읽었을 때 같은 값이 되는 가장 짧은 표기를 사용합니다.
+// 시각은 UTC의 RFC 3339 표기(소수 초 포함), 바이너리 데이터는 0x로 시작하는 16진수입니다.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case []byte:
+		return "0x" + hex.EncodeToString(v)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// keyColumns는 열 목록에서 키 열을 열 순서대로 반환합니다. 복합 키이면 둘 이상입니다.
+func keyColumns(columns []table.Column) []table.Column {
+	var keys []table.Column
+	for _, col := range columns {
+		if col.Is_key {
+			keys = append(keys, col)
+		}
+	}
+	return keys
+}
+
+// formatKey는 키 값들을 행 키 문자열로 만듭니다.
+// 단일 키는 값의 정규화된 표기 그대로이고, 복합 키는 데이터 줄과 같은 표기로 값을 나열한 튜플입니다.
+// 예) 7, ("acme", 42)
+func formatKey(keyCols []table.Column, values []interface{}) string {
+	if len(keyCols) == 1 {
+		return formatValue(values[0])
+	}
+	parts := make([]string, len(keyCols))
+	for i, col := range keyCols {
+		parts[i] = formatTffValue(col, values[i])
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// rowKey는 행 데이터의 키 열 값으로 행 키를 만듭니다. 키 값 중 NULL이 있으면 false입니다.
+func rowKey(columns []table.Column, data map[string]interface{}) (string, bool) {
+	keyCols := keyColumns(columns)
+	if len(keyCols) == 0 {
+		return "", false
+	}
+	values := make([]interface{}, len(keyCols))
+	for i, col := range keyCols {
+		if data[col.Name] == nil {
+			return "", false
+		}
+		values[i] = data[col.Name]
+	}
+	return formatKey(keyCols, values), true
+}
+
+// canonicalKey는 스크립트로 받은 키 값들을 키 열 타입의 정규화된 행 키로 변환합니다.
+// 예) INTEGER 키에서 "007"과 "7"은 같은 행을 가리킵니다.
+// 복합 키는 키 열 수만큼 값이 있어야 합니다.
+func canonicalKey(raw []string, columns []table.Column) (string, error) {
+	keyCols := keyColumns(columns)
+	if len(keyCols) == 0 {
+		return "", fmt.Errorf("cannot find key column")
+	}
+	if len(raw) != len(keyCols) {
+		return "", fmt.Errorf("key has %d values but the table key has %d columns", len(raw), len(keyCols))
+	}
+	values := make([]interface{}, len(keyCols))
+	for i, col := range keyCols {
+		value, err := convertValue(col, raw[i])
+		if err != nil {
+			return "", err
+		}
+		values[i] = value
+	}
+	return formatKey(keyCols, values), nil
+}
+
+// compareValues는 같은 열 타입의 두 값을 비교하여 -1, 0, 1을 반환합니다.
+// NULL(nil)은 어떤 값보다도 앞입니다.
+func compareValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch x := a.(type) {
+	case int64:
+		if y, ok := b.(int64); ok {
+			return cmp.Compare(x, y)
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmp.Compare(x, y)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	case Decimal:
+		if y, ok := b.(Decimal); ok {
+			return x.Cmp(y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return cmp.Compare(boolRank(x), boolRank(y))
+		}
+	case Date:
+		if y, ok := b.(Date); ok {
+			return x.Cmp(y)
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	case []byte:
+		if y, ok := b.([]byte); ok {
+			return bytes.Compare(x, y)
+		}
+	}
+	// 타입이 다르면 표기로 비교합니다.
+	return strings.Compare(formatValue(a), formatValue(b))
+}
+
+// boolRank는 false를 true보다 앞에 두기 위한 순위입니다.
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// compareRowKeys는 두 행을 키 열 순서대로 비교합니다.
+func compareRowKeys(keyCols []table.Column, a, b Row) int {
+	for _, col := range keyCols {
+		if c := compareValues(a.Data[col.Name], b.Data[col.Name]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
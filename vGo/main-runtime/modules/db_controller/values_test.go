@@ -0,0 +1,136 @@
+package dbcontroller
+
+import (
+	"sedb/modules/table"
+	"testing"
+)
+
+func TestConvertBool(t *testing.T) {
+	col := table.Column{Name: "flag", Type: table.CT_bool}
+	for raw, want := range map[string]bool{"true": true, "false": false} {
+		got, err := convertValue(col, raw)
+		if err != nil || got != want {
+			t.Errorf("convertValue(%q) = %v, %v; want %v", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"1", "0", "t", "F", "TRUE", "False", "yes", ""} {
+		if got, err := convertValue(col, raw); err == nil {
+			t.Errorf("convertValue(%q) = %v; want error", raw, got)
+		}
+	}
+}
+
+func TestConvertValue(t *testing.T) {
+	tests := []struct {
+		col  table.Column
+		raw  string
+		want string // formatValue 표기
+	}{
+		{table.Column{Type: table.CT_integer}, "9007199254740993", "9007199254740993"},
+		{table.Column{Type: table.CT_integer}, "-42", "-42"},
+		{table.Column{Type: table.CT_float}, "3.75", "3.75"},
+		{table.Column{Type: table.CT_number}, "3.75", "3.75"},
+		{table.Column{Type: table.CT_decimal, Scale: 2}, "12.5", "12.50"},
+		{table.Column{Type: table.CT_decimal, Scale: 2}, "-0.05", "-0.05"},
+		{table.Column{Type: table.CT_text}, "NULL", "NULL"},
+		{table.Column{Type: table.CT_bool}, "true", "true"},
+		{table.Column{Type: table.CT_date}, "2024-01-31", "2024-01-31"},
+		{table.Column{Type: table.CT_timestamp}, "2024-03-01T09:00:00+09:00", "2024-03-01T00:00:00Z"},
+		{table.Column{Type: table.CT_timestamp}, "1999-12-31T23:59:59.5", "1999-12-31T23:59:59.5Z"},
+		{table.Column{Type: table.CT_blob}, "0xDEADbeef", "0xdeadbeef"},
+		{table.Column{Type: table.CT_blob}, "0x", "0x"},
+		{table.Column{Type: table.CT_json}, `{"tags": ["a"]}`, `{"tags": ["a"]}`},
+	}
+	for _, tt := range tests {
+		got, err := convertValue(tt.col, tt.raw)
+		if err != nil {
+			t.Errorf("convertValue(%q): %v", tt.raw, err)
+			continue
+		}
+		if formatValue(got) != tt.want {
+			t.Errorf("convertValue(%q) = %s, want %s", tt.raw, formatValue(got), tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		col table.Column
+		raw string
+	}{
+		{table.Column{Type: table.CT_integer}, "1.5"},
+		{table.Column{Type: table.CT_integer}, "9223372036854775808"},
+		{table.Column{Type: table.CT_float}, "Inf"},
+		{table.Column{Type: table.CT_decimal, Scale: 2}, "1.234"},
+		{table.Column{Type: table.CT_decimal, Scale: 2}, "abc"},
+		{table.Column{Type: table.CT_bool}, "yes"},
+		{table.Column{Type: table.CT_date}, "2024-02-30"},
+		{table.Column{Type: table.CT_timestamp}, "2024-03-01 09:00"},
+		{table.Column{Type: table.CT_blob}, "0xabc"},
+		{table.Column{Type: table.CT_json}, "{"},
+	} {
+		if got, err := convertValue(tt.col, tt.raw); err == nil {
+			t.Errorf("convertValue(%q) = %v; want error", tt.raw, got)
+		}
+	}
+}
+
+func TestNumericValuesRoundTrip(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (integer id NOTNULL KEY, float f, decimal(2) d, number n);`)
+	mustExec(t, info, `add t (9007199254740993, 3.75, 12.5, 0.1);`)
+
+	for _, script := range []string{
+		`add t (4.5, 1, 1, 1);`,
+		`add t (5, 1, 1.234, 1);`,
+		`add t (6, "x", 1, 1);`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: expected error", script)
+		}
+	}
+
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(tableData.Rows))
+	}
+	row := tableData.Rows[0]
+	want := map[string]string{"id": "9007199254740993", "f": "3.75", "d": "12.50", "n": "0.1"}
+	for name, value := range want {
+		if got := formatValue(row.Data[name]); got != value {
+			t.Errorf("column %s: got %s, want %s", name, got, value)
+		}
+	}
+	if row.Key != "9007199254740993" {
+		t.Errorf("key = %s, want 9007199254740993", row.Key)
+	}
+}
+
+func TestKeywordsAreNotNames(t *testing.T) {
+	info := newTestDB(t)
+
+	// 열 타입 이름과 열 속성 키워드로 만든 열은 SELECT, WHERE, ALTER_TABLE에서 가리킬 수 없으므로 만들지 않습니다.
+	for _, word := range []string{"date", "json", "timestamp", "decimal", "bool", "blob", "text",
+		"unique", "default", "check", "references", "null", "true", "false", "binary", "key"} {
+		script := `create_table t (integer id NOTNULL KEY, text ` + word + `);`
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: accepted a keyword as a column name", script)
+			mustExec(t, info, `drop_table t;`)
+		}
+	}
+	if CmdExec(`create_table date (integer id NOTNULL KEY);`, info) == 0 {
+		t.Error("accepted a keyword as a table name")
+	}
+	if CmdExec(`create_table t (integer id NOTNULL KEY, text name);`, info) != 0 {
+		t.Fatal("create_table failed")
+	}
+	for _, script := range []string{
+		`alter_table t add_column text json;`,
+		`alter_table t rename_column name to timestamp;`,
+	} {
+		if CmdExec(script, info) == 0 {
+			t.Errorf("%s: accepted a keyword as a column name", script)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package dbcontroller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	dbinfo "sedb/modules/db_info"
+	"sedb/modules/parsers"
+	"sort"
+	"strings"
+	"time"
+)
+
+// tableIssue는 테이블 검사에서 발견한 문제 하나입니다.
+type tableIssue struct {
+	where  string // 예) "line 12", "page 3", "header"
+	reason string
+	raw    string // 격리할 원래 내용 (행 단위 문제가 아니면 빈 문자열)
+}
+
+// tableReport는 테이블 하나의 검사 결과입니다.
+type tableReport struct {
+	name   string
+	issues []tableIssue
+	fatal  bool       // 헤더 또는 키 정의 문제로 복구할 수 없음
+	data   *TableData // 문제가 있는 레코드를 제외하고 구성한 테이블 (복구용)
+}
+
+// newArchivePath는 archive 디렉토리에 테이블 이름과 현재 시각으로 겹치지 않는 파일 경로를 만듭니다.
+// 예) archive/users.20250101-120000.quarantine
+func newArchivePath(tableName string, ext string, dbInfo dbinfo.DBInfo) (string, error) {
+	dir := archiveDirPath(dbInfo)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	base := tableName + "." + time.Now().Format("20060102-150405")
+	path := filepath.Join(dir, base+ext)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path, nil
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d%s", base, i, ext))
+	}
+}
+
+// listTables는 tables 디렉토리의 테이블 이름을 정렬하여 반환합니다.
+func listTables(dbInfo dbinfo.DBInfo) ([]string, error) {
+	entries, err := os.ReadDir(tablesDirPath(dbInfo))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tff") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ".tff"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// verifyTable은 테이블 파일 하나를 검사합니다.
+// 헤더, KEY 열 정의, 체크섬, 레코드 형식과 값 개수, 타입과 NOTNULL 제약, 키 중복, UNIQUE 값 중복, CHECK 제약을 확인합니다.
+// 읽기를 멈추지 않고 모든 문제를 모으며, 문제가 없는 레코드로 복구용 테이블을 구성합니다.
+func verifyTable(tableName string, dbInfo dbinfo.DBInfo) tableReport {
+	report := tableReport{name: tableName}
+	fail := func(where, reason string) tableReport {
+		report.issues = append(report.issues, tableIssue{where: where, reason: reason})
+		report.fatal = true
+		return report
+	}
+
+	reader, file, err := openTableReader(tableName, dbInfo)
+	if err != nil {
+		if corrupt, ok := err.(*corruptionError); ok {
+			return fail(corrupt.where, corrupt.reason)
+		}
+		return fail("file", err.Error())
+	}
+	defer file.Close()
+
+	// KEY 열이 하나 이상이어야 합니다. 둘 이상이면 복합 키입니다.
+	columns := reader.columns()
+	if len(keyColumns(columns)) == 0 {
+		return fail("header", "no KEY column")
+	}
+
+	// 파일 체크섬은 이어서 계산되므로 한 번 어긋나면 이후의 확정 지점도 모두 어긋납니다. 첫 번째만 보고합니다.
+	fileSumReported := false
+	reader.onDamage(func(err *corruptionError, raw string) {
+		if err.reason == fileChecksumMismatch {
+			if fileSumReported {
+				return
+			}
+			fileSumReported = true
+		}
+		report.issues = append(report.issues, tableIssue{where: err.where, reason: err.reason, raw: raw})
+	})
+
+	// 같은 묶음(한 번의 기록) 안에서 키가 두 번 나오면 중복입니다.
+	// 같은 묶음의 레코드는 같은 확정 위치(committedSize)에서 반환됩니다.
+	rows := newRowSet()
+	nextAuto := reader.autoIncrement()
+	var batch int64 = -1
+	batchKeys := make(map[string]bool)
+	for n := 1; ; n++ {
+		rec, ok, err := reader.next()
+		if err != nil {
+			return fail("file", err.Error())
+		}
+		if !ok {
+			break
+		}
+
+		if st := reader.storage(); st.committedSize != batch {
+			batch = st.committedSize
+			batchKeys = make(map[string]bool)
+		}
+
+		where := fmt.Sprintf("record %d", n)
+		if !rec.tombstone {
+			values := make([]interface{}, len(columns))
+			for i, col := range columns {
+				if value := rec.row.Data[col.Name]; value != nil {
+					values[i] = formatValue(value)
+				}
+			}
+			if _, err := validateDataTypes(values, columns); err != nil {
+				report.issues = append(report.issues, tableIssue{where: where, reason: err.Error(), raw: formatRecordLine(columns, rec)})
+				continue
+			}
+			if rec.key == "" {
+				report.issues = append(report.issues, tableIssue{where: where, reason: "missing key value", raw: formatRecordLine(columns, rec)})
+				continue
+			}
+			if batchKeys[rec.key] {
+				report.issues = append(report.issues, tableIssue{where: where, reason: fmt.Sprintf("duplicate key '%s'", rec.key), raw: formatRecordLine(columns, rec)})
+				continue
+			}
+		}
+		batchKeys[rec.key] = true
+		rows.apply(rec)
+		nextAuto = trackAutoIncrement(nextAuto, columns, rec)
+	}
+
+	// 마지막 확정 이후의 완료되지 않은 기록
+	st := reader.storage()
+	if info, err := file.Stat(); err == nil && info.Size() > st.committedSize {
+		report.issues = append(report.issues, tableIssue{
+			where:  "end of file",
+			reason: fmt.Sprintf("%d bytes of incomplete writes after the last commit", info.Size()-st.committedSize),
+		})
+	}
+
+	// UNIQUE 열의 값이 겹치면 먼저 기록된 행을 남기고 뒤의 행을 문제로 보고합니다.
+	live := rows.live()
+	for _, col := range columns {
+		if !col.Unique {
+			continue
+		}
+		for {
+			r, value := findUniqueDuplicate(live, col, nil)
+			if r < 0 {
+				break
+			}
+			report.issues = append(report.issues, tableIssue{
+				where:  fmt.Sprintf("row '%s'", live[r].Key),
+				reason: fmt.Sprintf("duplicate value '%s' in UNIQUE column '%s'", value, col.Name),
+				raw:    formatRecordLine(columns, newDataRecord(live[r])),
+			})
+			live = append(live[:r], live[r+1:]...)
+		}
+	}
+
+	// CHECK 제약을 어기는 행을 문제로 보고합니다.
+	checks := reader.checks()
+	if _, err := compileChecks(checks, columns); err != nil {
+		return fail("header", err.Error())
+	}
+	kept := live[:0]
+	for _, row := range live {
+		if err := checkRow(checks, columns, row.Data); err != nil {
+			report.issues = append(report.issues, tableIssue{
+				where:  fmt.Sprintf("row '%s'", row.Key),
+				reason: err.Error(),
+				raw:    formatRecordLine(columns, newDataRecord(row)),
+			})
+			continue
+		}
+		kept = append(kept, row)
+	}
+	live = kept
+
+	sortRowsByKey(columns, live)
+	report.data = &TableData{
+		Columns: columns,
+		Checks:  checks,
+		Rows:    live,
+		Lsn:     reader.lastLsn(),
+		storage: storageState{format: st.format},
+
+		nextAuto: nextAuto,
+	}
+	return report
+}
+
+// repairTable은 문제가 있는 레코드를 archive 디렉토리에 격리하고
+// 문제가 없는 레코드만으로 테이블 파일을 다시 씁니다.
+// 격리 파일을 먼저 동기화하므로 도중에 중단되어도 격리한 내용이 유실되지 않습니다.
+func repairTable(report tableReport, dbInfo dbinfo.DBInfo) (string, error) {
+	var quarantine strings.Builder
+	for _, issue := range report.issues {
+		if issue.raw == "" {
+			continue
+		}
+		fmt.Fprintf(&quarantine, "# %s: %s\n", issue.where, issue.reason)
+		quarantine.WriteString(issue.raw + "\n")
+	}
+
+	var archived string
+	if quarantine.Len() > 0 {
+		path, err := newArchivePath(report.name, ".quarantine", dbInfo)
+		if err != nil {
+			return "", err
+		}
+		err = writeFileAtomic(path, func(file *os.File) error {
+			_, err := file.WriteString(quarantine.String())
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+		archived = path
+	}
+
+	if err := saveTableData(report.data, report.name, dbInfo); err != nil {
+		return archived, err
+	}
+	return archived, catalogPutTable(report.name, report.data, dbInfo)
+}
+
+// handleVerify는 VERIFY 명령을 처리합니다.
+// 모든 테이블 파일을 검사하여 테이블별 결과를 출력하고,
+// --repair 옵션이 있으면 문제가 있는 행을 archive 디렉토리로 격리합니다.
+// 문제가 남아 있으면 1을 반환합니다.
+func handleVerify(tokens []parsers.SC_token, dbInfo dbinfo.DBInfo) int {
+	repair := false
+	for _, tok := range tokens[1:] {
+		switch {
+		case tok.Token_type == parsers.SC_option && tok.Token == "repair":
+			repair = true
+		case tok.Token_type == parsers.SC_endCmd:
+		default:
+			return printError(fmt.Sprintf("syntax error: unexpected '%v' in VERIFY statement", tok.Token))
+		}
+	}
+
+	names, err := listTables(dbInfo)
+	if err != nil {
+		return printError(fmt.Sprintf("error: failed to list tables: %v", err))
+	}
+
+	result := 0
+	for _, name := range names {
+		report := verifyTable(name, dbInfo)
+		if len(report.issues) == 0 {
+			fmt.Printf("Table '%s': OK (%d rows)\n", name, len(report.data.Rows))
+			continue
+		}
+
+		fmt.Printf("Table '%s': %d problem(s)\n", name, len(report.issues))
+		for _, issue := range report.issues {
+			fmt.Printf("  %s: %s\n", issue.where, issue.reason)
+		}
+
+		if !repair {
+			result = 1
+			continue
+		}
+		if report.fatal {
+			fmt.Printf("  cannot repair table '%s'\n", name)
+			result = 1
+			continue
+		}
+
+		archived, err := repairTable(report, dbInfo)
+		if err != nil {
+			fmt.Printf("  failed to repair table '%s': %v\n", name, err)
+			result = 1
+			continue
+		}
+		if archived != "" {
+			fmt.Printf("  quarantined bad rows to '%s'\n", archived)
+		}
+		fmt.Printf("  repaired table '%s' (%d rows)\n", name, len(report.data.Rows))
+	}
+
+	return result
+}
@@ -0,0 +1,56 @@
+package dbcontroller
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyRepairIsLogged(t *testing.T) {
+	info := newTestDB(t, "broken.tff")
+
+	if CmdExec("verify;", info) != 1 {
+		t.Fatal("verify without --repair should report the NULL row")
+	}
+	if CmdExec("verify --fix;", info) == 0 {
+		t.Error("verify accepted an unknown option")
+	}
+	mustExec(t, info, "verify --repair;")
+
+	tableData, err := loadTableData("broken", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 1 || tableData.Rows[0].Key != "1" {
+		t.Fatalf("rows after repair = %v, want only key 1", tableData.Rows)
+	}
+	quarantined, _ := filepath.Glob(filepath.Join(info.DbName, "archive", "broken.*.quarantine"))
+	if len(quarantined) != 1 {
+		t.Fatalf("got %d quarantine files, want 1", len(quarantined))
+	}
+
+	// 복구는 WAL에 BEGIN/COMMIT으로 기록되고, 다시 쓴 테이블에 그 레코드 번호가 남습니다.
+	records, _, err := readWalRecords(walFilePath(info))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var begin walRecord
+	committed := false
+	for _, rec := range records {
+		if rec.Kind == walBegin && rec.Script == "verify --repair;" {
+			begin = rec
+		}
+		if rec.Kind == walCommit && begin.Lsn != 0 && rec.Lsn == begin.Lsn {
+			committed = true
+		}
+	}
+	if begin.Lsn == 0 || !committed {
+		t.Fatalf("verify --repair is not logged: %v", records)
+	}
+	if tableData.Lsn != begin.Lsn {
+		t.Errorf("table Lsn = %d, want %d", tableData.Lsn, begin.Lsn)
+	}
+
+	if CmdExec("verify;", info) != 0 {
+		t.Error("verify after repair should find no problems")
+	}
+}
@@ -0,0 +1,307 @@
+package dbcontroller
+
+import (
+	"bufio"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	dbinfo "sedb/modules/db_info"
+	"sedb/modules/parsers"
+	"strconv"
+	"strings"
+)
+
+// WAL 레코드 종류
+const (
+	walBegin      = "BEGIN"      // 명령 실행 직전에 기록 (스크립트 포함)
+	walCommit     = "COMMIT"     // 명령이 테이블 파일에 반영됨
+	walAbort      = "ABORT"      // 명령이 오류로 반영되지 않음
+	walCheckpoint = "CHECKPOINT" // 이전 레코드가 모두 테이블 파일에 반영됨
+)
+
+// walCheckpointSize는 WAL을 체크포인트하기 전까지 허용하는 최대 크기입니다.
+const walCheckpointSize = 1 << 20
+
+// currentLsn은 현재 실행 중인 명령의 WAL 레코드 번호입니다. (실행 중이 아니면 0)
+var currentLsn int64
+
+// wals는 데이터베이스 이름별로 열린 WAL 상태를 보관합니다.
+var wals = map[string]*writeAheadLog{}
+
+// walRecord는 WAL의 단일 레코드를 나타냅니다.
+type walRecord struct {
+	Kind   string
+	Lsn    int64
+	Script string
+}
+
+// writeAheadLog는 데이터베이스 디렉토리의 추가 전용 로그입니다.
+type writeAheadLog struct {
+	path    string
+	nextLsn int64
+	size    int64
+}
+
+// walFilePath는 데이터베이스의 WAL 파일 경로를 반환합니다.
+func walFilePath(dbInfo dbinfo.DBInfo) string {
+	return filepath.Join("./", dbInfo.DbName, "wal.log")
+}
+
+// isMutatingCommand는 명령이 테이블 파일을 변경하는지 확인합니다.
+// VERIFY는 --repair 옵션이 있을 때만 테이블 파일을 다시 씁니다.
+func isMutatingCommand(tokens []parsers.SC_token) bool {
+	switch tokens[0].Token_type {
+	case parsers.SC_createTable, parsers.SC_add, parsers.SC_update, parsers.SC_delete,
+		parsers.SC_convertTable, parsers.SC_alterTable, parsers.SC_dropTable, parsers.SC_renameTable,
+		parsers.SC_truncate:
+		return true
+	case parsers.SC_verify:
+		for _, tok := range tokens[1:] {
+			if tok.Token_type == parsers.SC_option && tok.Token == "repair" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// commandTable은 명령이 대상으로 하는 테이블 이름을 반환합니다.
+// 특정 테이블을 대상으로 하지 않는 명령(VERIFY 등)이면 빈 문자열입니다.
+func commandTable(tokens []parsers.SC_token) string {
+	if len(tokens) < 2 || tokens[1].Token_type != parsers.SC_tableName {
+		return ""
+	}
+	name, _ := tokens[1].Token.(string)
+	return name
+}
+
+// formatWalRecord는 레코드를 체크섬이 붙은 한 줄로 직렬화합니다.
+func formatWalRecord(rec walRecord) string {
+	body := fmt.Sprintf("%s %d %s", rec.Kind, rec.Lsn, strconv.Quote(rec.Script))
+	return fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE([]byte(body)), body)
+}
+
+// parseWalRecord는 한 줄을 레코드로 해석합니다. 체크섬이 맞지 않으면 오류를 반환합니다.
+func parseWalRecord(line string) (walRecord, error) {
+	var rec walRecord
+
+	sep := strings.IndexByte(line, ' ')
+	if sep == -1 {
+		return rec, fmt.Errorf("malformed record")
+	}
+	sum, err := strconv.ParseUint(line[:sep], 16, 32)
+	if err != nil {
+		return rec, fmt.Errorf("malformed checksum")
+	}
+	body := line[sep+1:]
+	if crc32.ChecksumIEEE([]byte(body)) != uint32(sum) {
+		return rec, fmt.Errorf("checksum mismatch")
+	}
+
+	fields := strings.SplitN(body, " ", 3)
+	if len(fields) != 3 {
+		return rec, fmt.Errorf("malformed record")
+	}
+	rec.Kind = fields[0]
+	rec.Lsn, err = strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return rec, fmt.Errorf("malformed lsn")
+	}
+	rec.Script, err = strconv.Unquote(fields[2])
+	if err != nil {
+		return rec, fmt.Errorf("malformed script")
+	}
+	return rec, nil
+}
+
+// readWalRecords는 WAL의 유효한 레코드를 읽습니다.
+// 기록 도중 중단되어 잘렸거나 체크섬이 맞지 않는 꼬리 부분은 버리고,
+// 유효한 부분의 바이트 크기를 함께 반환합니다.
+func readWalRecords(path string) ([]walRecord, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	var records []walRecord
+	var size int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			// 줄바꿈 없이 끝난 마지막 줄은 기록이 완료되지 않은 것
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		rec, perr := parseWalRecord(strings.TrimSuffix(line, "\n"))
+		if perr != nil {
+			break
+		}
+		records = append(records, rec)
+		size += int64(len(line))
+	}
+
+	return records, size, nil
+}
+
+// openWal은 데이터베이스의 WAL을 열고 다음 레코드 번호를 결정합니다.
+func openWal(dbInfo dbinfo.DBInfo) (*writeAheadLog, error) {
+	if wal, ok := wals[dbInfo.DbName]; ok {
+		return wal, nil
+	}
+
+	path := walFilePath(dbInfo)
+	records, size, err := readWalRecords(path)
+	if err != nil {
+		return nil, err
+	}
+
+	wal := &writeAheadLog{path: path, nextLsn: 1, size: size}
+	for _, rec := range records {
+		if rec.Lsn >= wal.nextLsn {
+			wal.nextLsn = rec.Lsn + 1
+		}
+	}
+
+	wals[dbInfo.DbName] = wal
+	return wal, nil
+}
+
+// append는 레코드를 WAL 끝에 추가합니다. sync가 true이면 디스크에 동기화합니다.
+func (wal *writeAheadLog) append(rec walRecord, sync bool) error {
+	file, err := os.OpenFile(wal.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// 이전 기록이 잘린 꼬리를 남겼다면 그 뒤에 붙이지 않도록 유효한 크기로 자릅니다.
+	if info, err := file.Stat(); err == nil && info.Size() != wal.size {
+		if err := file.Truncate(wal.size); err != nil {
+			return err
+		}
+	}
+
+	line := formatWalRecord(rec)
+	if _, err := file.WriteString(line); err != nil {
+		return err
+	}
+	if sync {
+		if err := file.Sync(); err != nil {
+			return err
+		}
+	}
+
+	wal.size += int64(len(line))
+	return nil
+}
+
+// begin은 명령 스크립트를 새 레코드 번호로 기록하고 디스크에 동기화합니다.
+func (wal *writeAheadLog) begin(script string) (int64, error) {
+	lsn := wal.nextLsn
+	if err := wal.append(walRecord{Kind: walBegin, Lsn: lsn, Script: script}, true); err != nil {
+		return 0, err
+	}
+	wal.nextLsn++
+	return lsn, nil
+}
+
+// finish는 명령의 결과를 기록합니다.
+// 결과 레코드가 유실되어도 테이블 파일의 Lsn으로 반영 여부를 판단할 수 있으므로
+// 동기화하지 않습니다. 다음 BEGIN의 동기화가 이 레코드도 함께 내려 씁니다.
+func (wal *writeAheadLog) finish(lsn int64, ok bool) error {
+	kind := walCommit
+	if !ok {
+		kind = walAbort
+	}
+	return wal.append(walRecord{Kind: kind, Lsn: lsn}, false)
+}
+
+// needsCheckpoint는 WAL이 체크포인트가 필요할 만큼 커졌는지 확인합니다.
+func (wal *writeAheadLog) needsCheckpoint() bool {
+	return wal.size >= walCheckpointSize
+}
+
+// checkpoint는 WAL을 마지막 레코드 번호만 담은 체크포인트 레코드로 교체합니다.
+// 테이블 파일은 저장 시 이미 fsync 되므로 이전 레코드는 더 이상 필요하지 않습니다.
+func (wal *writeAheadLog) checkpoint() error {
+	line := formatWalRecord(walRecord{Kind: walCheckpoint, Lsn: wal.nextLsn - 1})
+
+	err := writeFileAtomic(wal.path, func(file *os.File) error {
+		_, err := file.WriteString(line)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	wal.size = int64(len(line))
+	return nil
+}
+
+// replayWal은 완료 기록이 없는 BEGIN 레코드를 다시 실행하거나 폐기한 뒤
+// WAL을 체크포인트합니다.
+// 명령은 직렬화되어 실행되므로 완료되지 않은 레코드는 마지막 하나뿐입니다.
+func replayWal(dbInfo dbinfo.DBInfo) error {
+	delete(wals, dbInfo.DbName)
+
+	path := walFilePath(dbInfo)
+	records, _, err := readWalRecords(path)
+	if err != nil {
+		return err
+	}
+
+	finished := map[int64]bool{}
+	for _, rec := range records {
+		if rec.Kind == walCommit || rec.Kind == walAbort {
+			finished[rec.Lsn] = true
+		}
+	}
+
+	for _, rec := range records {
+		if rec.Kind != walBegin || finished[rec.Lsn] {
+			continue
+		}
+		replayWalRecord(rec, dbInfo)
+	}
+
+	wal, err := openWal(dbInfo)
+	if err != nil {
+		return err
+	}
+	return wal.checkpoint()
+}
+
+// replayWalRecord는 완료되지 않은 레코드 하나를 처리합니다.
+// 테이블 파일에 이미 반영된 경우(테이블 Lsn >= 레코드 번호)에는 건너뜁니다.
+func replayWalRecord(rec walRecord, dbInfo dbinfo.DBInfo) {
+	var tokens []parsers.SC_token
+	if parsers.Parsing_script(rec.Script, &tokens) != 0 || len(tokens) == 0 {
+		fmt.Printf("Discarded unreadable WAL record %d\n", rec.Lsn)
+		return
+	}
+
+	tableName := commandTable(tokens)
+	if tableName != "" && tableExists(tableName, dbInfo) {
+		lsn, err := readTableLsn(tableName, dbInfo)
+		if err == nil && lsn >= rec.Lsn {
+			return
+		}
+	}
+
+	fmt.Printf("Replaying WAL record %d: %s\n", rec.Lsn, rec.Script)
+	currentLsn = rec.Lsn
+	if execTokens(tokens, dbInfo) != 0 {
+		fmt.Printf("Discarded WAL record %d\n", rec.Lsn)
+	}
+	currentLsn = 0
+}
@@ -0,0 +1,155 @@
+package dbcontroller
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWalRecordRoundTrip(t *testing.T) {
+	rec := walRecord{Kind: walBegin, Lsn: 42, Script: "add t (1, \"a \\\"b\\\"\\n\");"}
+	line := formatWalRecord(rec)
+	if !strings.HasSuffix(line, "\n") || strings.Count(line, "\n") != 1 {
+		t.Fatalf("record is not a single line: %q", line)
+	}
+	got, err := parseWalRecord(strings.TrimSuffix(line, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != rec {
+		t.Errorf("got %+v, want %+v", got, rec)
+	}
+
+	// 한 글자라도 바뀌면 체크섬이 맞지 않습니다.
+	corrupted := strings.Replace(line, "42", "43", 1)
+	if _, err := parseWalRecord(strings.TrimSuffix(corrupted, "\n")); err == nil {
+		t.Error("corrupted record was accepted")
+	}
+}
+
+func TestReadWalRecordsDropsTornTail(t *testing.T) {
+	path := t.TempDir() + "/wal.log"
+	good := formatWalRecord(walRecord{Kind: walBegin, Lsn: 1, Script: "truncate t;"}) +
+		formatWalRecord(walRecord{Kind: walCommit, Lsn: 1})
+	bad := strings.Replace(formatWalRecord(walRecord{Kind: walBegin, Lsn: 2, Script: "truncate t;"}), "truncate", "TRUNCATE", 1)
+	content := good + bad + formatWalRecord(walRecord{Kind: walCommit, Lsn: 2}) + "0000 BEG"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 체크섬이 맞지 않는 레코드부터 끝까지 버립니다.
+	records, size, err := readWalRecords(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || size != int64(len(good)) {
+		t.Errorf("got %d records (%d bytes), want 2 (%d bytes)", len(records), size, len(good))
+	}
+}
+
+func TestWalReplayUnfinishedCommand(t *testing.T) {
+	info := newTestDB(t)
+
+	// BEGIN만 기록되고 명령이 실행되기 전에 중단된 경우, 이어서 잘린 레코드가 남은 경우
+	wal, err := openWal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lsn, err := wal.begin(`create_table t (number id NOTNULL KEY, text n);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(walFilePath(info), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("deadbeef BEG")
+	file.Close()
+
+	if Startup(info) != 0 {
+		t.Fatal("startup failed")
+	}
+	tableData, err := loadTableData("t", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Columns) != 2 {
+		t.Fatalf("columns after replay = %v, want the replayed table", tableData.Columns)
+	}
+	if tableData.Lsn != lsn {
+		t.Errorf("table Lsn = %d, want %d", tableData.Lsn, lsn)
+	}
+
+	// 재실행 뒤에는 체크포인트 레코드 하나만 남고, 레코드 번호는 이어집니다.
+	records, _, err := readWalRecords(walFilePath(info))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Kind != walCheckpoint || records[0].Lsn != lsn {
+		t.Fatalf("wal after replay = %+v, want one CHECKPOINT %d", records, lsn)
+	}
+	mustExec(t, info, `create_table u (number id NOTNULL KEY);`)
+	tableData, _ = loadTableData("u", info)
+	if tableData.Lsn != lsn+1 {
+		t.Errorf("next command Lsn = %d, want %d", tableData.Lsn, lsn+1)
+	}
+}
+
+func TestWalLogsMutatingCommands(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table t (number id NOTNULL KEY);`)
+	if CmdExec(`create_table t (number id NOTNULL KEY);`, info) == 0 {
+		t.Fatal("second create_table succeeded")
+	}
+
+	// 시작 시의 체크포인트 뒤에 명령마다 BEGIN과 그 결과가 기록됩니다.
+	records, _, err := readWalRecords(walFilePath(info))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, rec := range records {
+		kinds = append(kinds, rec.Kind)
+	}
+	want := []string{walCheckpoint, walBegin, walCommit, walBegin, walAbort}
+	if strings.Join(kinds, " ") != strings.Join(want, " ") {
+		t.Fatalf("wal records = %v, want %v", kinds, want)
+	}
+	if records[1].Script != `create_table t (number id NOTNULL KEY);` || records[2].Lsn != records[1].Lsn {
+		t.Errorf("BEGIN %+v / COMMIT %+v do not match the command", records[1], records[2])
+	}
+}
+
+func TestWalReplaySkipsAppliedCommand(t *testing.T) {
+	info := newTestDB(t)
+	mustExec(t, info, `create_table a (integer id NOTNULL KEY AUTO_INCREMENT, text name);`)
+	mustExec(t, info, `add a ("kim");`)
+
+	// 테이블 파일에 반영된 뒤 COMMIT을 기록하기 전에 중단된 경우
+	records, _, err := readWalRecords(walFilePath(info))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content strings.Builder
+	for _, rec := range records[:len(records)-1] {
+		content.WriteString(formatWalRecord(rec))
+	}
+	if records[len(records)-1].Kind != walCommit {
+		t.Fatalf("last record is %s, want COMMIT", records[len(records)-1].Kind)
+	}
+	if err := os.WriteFile(walFilePath(info), []byte(content.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 다시 실행하면 새 키로 행이 하나 더 생기므로, 건너뛰었는지 행 수로 확인합니다.
+	if Startup(info) != 0 {
+		t.Fatal("startup failed")
+	}
+	tableData, err := loadTableData("a", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tableData.Rows) != 1 {
+		t.Errorf("got %d rows, want 1", len(tableData.Rows))
+	}
+}
@@ -5,3 +5,43 @@ type Index struct {
 	Index_key interface{} //키
 	Location  int         //위치
 }
+
+// 유일 인덱스: 열 값 -> 그 값을 가진 행의 키
+type Unique_index struct {
+	Column  string            //열 이름
+	Entries map[string]string //값 -> 행 키
+}
+
+// 유일 인덱스 생성
+func NewUniqueIndex(column string, idx *Unique_index) int {
+	if column == "" {
+		return 1 // 열 이름이 없으면 1 반환
+	}
+	idx.Column = column
+	idx.Entries = make(map[string]string)
+	return 0
+}
+
+// 값으로 행 키 찾기
+func Lookup(idx *Unique_index, value string) (string, bool) {
+	rowKey, ok := idx.Entries[value]
+	return rowKey, ok
+}
+
+// 값 추가. 다른 행이 이미 같은 값을 가지고 있으면 1 반환
+func Insert(idx *Unique_index, value string, rowKey string) int {
+	if owner, ok := idx.Entries[value]; ok && owner != rowKey {
+		return 1
+	}
+	idx.Entries[value] = rowKey
+	return 0
+}
+
+// 값 제거. 해당 행의 값일 때만 제거
+func Remove(idx *Unique_index, value string, rowKey string) int {
+	if owner, ok := idx.Entries[value]; !ok || owner != rowKey {
+		return 1
+	}
+	delete(idx.Entries, value)
+	return 0
+}
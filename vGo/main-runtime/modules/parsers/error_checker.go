@@ -0,0 +1,38 @@
+package parsers
+
+// Error_checker는 토큰 목록의 공통 문법 오류를 검사합니다.
+// 명령어로 시작하는지와 괄호 짝이 맞는지 확인하며, 명령어별 문법은 각 명령 처리에서 검사합니다.
+// 오류가 있으면 errBuffer에 메시지를 쓰고 1을 반환합니다.
+func Error_checker(tokens []SC_token, errBuffer *string) int {
+	if len(tokens) == 0 {
+		return 0
+	}
+	if tokens[0].Token_type < SC_createTable || tokens[0].Token_type > SC_select {
+		*errBuffer = "syntax error: script must start with a command"
+		return 1
+	}
+
+	depth := 0
+	for _, tok := range tokens {
+		switch tok.Token_type {
+		case SC_parenOpen:
+			depth++
+		case SC_parenClose:
+			depth--
+			if depth < 0 {
+				*errBuffer = "syntax error: unexpected ')'"
+				return 1
+			}
+		case SC_endCmd:
+			if depth != 0 {
+				*errBuffer = "syntax error: missing ')'"
+				return 1
+			}
+		}
+	}
+	if depth != 0 {
+		*errBuffer = "syntax error: missing ')'"
+		return 1
+	}
+	return 0
+}
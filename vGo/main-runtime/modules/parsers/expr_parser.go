@@ -0,0 +1,316 @@
+package parsers
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Expr_kind int
+
+const (
+	EX_none      Expr_kind = iota
+	EX_column              // 열 참조 (Name)
+	EX_literal             // 값 (Value: SC_number, SC_string, SC_bool, SC_null 토큰)
+	EX_compare             // Left Op Right (Op는 =, !=, <, <=, >, >=)
+	EX_and                 // Left AND Right
+	EX_or                  // Left OR Right
+	EX_not                 // NOT Left
+	EX_in                  // Left [NOT] IN (List...)
+	EX_isNull              // Left IS [NOT] NULL
+	EX_aggregate           // 집계 함수 Name([DISTINCT] Left) (Name은 소문자, COUNT(*)이면 Left는 nil)
+)
+
+// Expr는 조건식(CHECK 제약 등)의 구문 트리입니다.
+type Expr struct {
+	Kind     Expr_kind
+	Name     string   // EX_column: 열 이름
+	Value    SC_token // EX_literal: 값 토큰
+	Op       string   // EX_compare: 비교 연산자
+	Left     *Expr
+	Right    *Expr
+	List     []*Expr // EX_in: 값 목록
+	Negated  bool    // EX_in, EX_isNull: NOT IN, IS NOT NULL
+	Distinct bool    // EX_aggregate: 중복을 뺀 값으로 집계
+}
+
+// ParseExpr는 tokens[i]부터 조건식 하나를 읽습니다.
+// 구문 트리와 조건식 다음 토큰의 위치를 반환하며, 잘못된 조건식이면 오류 메시지를 반환합니다.
+//
+//	조건식   := AND식 { OR AND식 }
+//	AND식    := NOT식 { AND NOT식 }
+//	NOT식    := NOT NOT식 | ( 조건식 ) | 피연산자 [ 비교연산자 피연산자 | [NOT] IN ( 피연산자, ... ) | IS [NOT] NULL ]
+//	피연산자 := 열 이름 | 숫자 | 문자열 | true | false | NULL | 집계함수
+//	집계함수 := COUNT ( * ) | { COUNT | SUM | AVG | MIN | MAX } ( [DISTINCT] 열 이름 )
+func ParseExpr(tokens []SC_token, i int) (*Expr, int, string) {
+	return parseOr(tokens, i)
+}
+
+func parseOr(tokens []SC_token, i int) (*Expr, int, string) {
+	left, i, errMsg := parseAnd(tokens, i)
+	if errMsg != "" {
+		return nil, i, errMsg
+	}
+	for i < len(tokens) && tokens[i].Token_type == SC_or {
+		right, next, errMsg := parseAnd(tokens, i+1)
+		if errMsg != "" {
+			return nil, next, errMsg
+		}
+		left = &Expr{Kind: EX_or, Left: left, Right: right}
+		i = next
+	}
+	return left, i, ""
+}
+
+func parseAnd(tokens []SC_token, i int) (*Expr, int, string) {
+	left, i, errMsg := parseNot(tokens, i)
+	if errMsg != "" {
+		return nil, i, errMsg
+	}
+	for i < len(tokens) && tokens[i].Token_type == SC_and {
+		right, next, errMsg := parseNot(tokens, i+1)
+		if errMsg != "" {
+			return nil, next, errMsg
+		}
+		left = &Expr{Kind: EX_and, Left: left, Right: right}
+		i = next
+	}
+	return left, i, ""
+}
+
+func parseNot(tokens []SC_token, i int) (*Expr, int, string) {
+	if i >= len(tokens) {
+		return nil, i, "syntax error: condition is incomplete"
+	}
+
+	switch tokens[i].Token_type {
+	case SC_not:
+		operand, next, errMsg := parseNot(tokens, i+1)
+		if errMsg != "" {
+			return nil, next, errMsg
+		}
+		return &Expr{Kind: EX_not, Left: operand}, next, ""
+	case SC_parenOpen:
+		inner, next, errMsg := parseOr(tokens, i+1)
+		if errMsg != "" {
+			return nil, next, errMsg
+		}
+		if next >= len(tokens) || tokens[next].Token_type != SC_parenClose {
+			return nil, next, "syntax error: missing closing parenthesis in condition"
+		}
+		return inner, next + 1, ""
+	}
+
+	left, i, errMsg := parseOperand(tokens, i)
+	if errMsg != "" {
+		return nil, i, errMsg
+	}
+	if i >= len(tokens) {
+		return left, i, ""
+	}
+
+	switch tokens[i].Token_type {
+	case SC_operator:
+		op := tokens[i].Token.(string)
+		if op == "<>" {
+			op = "!="
+		}
+		right, next, errMsg := parseOperand(tokens, i+1)
+		if errMsg != "" {
+			return nil, next, errMsg
+		}
+		return &Expr{Kind: EX_compare, Op: op, Left: left, Right: right}, next, ""
+	case SC_in:
+		return parseInList(tokens, i+1, left, false)
+	case SC_not:
+		if i+1 < len(tokens) && tokens[i+1].Token_type == SC_in {
+			return parseInList(tokens, i+2, left, true)
+		}
+		return nil, i, "syntax error: expected IN after NOT"
+	case SC_is:
+		i++
+		negated := false
+		if i < len(tokens) && tokens[i].Token_type == SC_not {
+			negated = true
+			i++
+		}
+		if i >= len(tokens) || tokens[i].Token_type != SC_null {
+			return nil, i, "syntax error: expected NULL after IS"
+		}
+		return &Expr{Kind: EX_isNull, Left: left, Negated: negated}, i + 1, ""
+	}
+	return left, i, ""
+}
+
+// parseInList는 IN 뒤의 ( 값, ... ) 목록을 읽습니다.
+func parseInList(tokens []SC_token, i int, left *Expr, negated bool) (*Expr, int, string) {
+	if i >= len(tokens) || tokens[i].Token_type != SC_parenOpen {
+		return nil, i, "syntax error: expected ( after IN"
+	}
+	i++
+
+	expr := &Expr{Kind: EX_in, Left: left, Negated: negated}
+	for {
+		item, next, errMsg := parseOperand(tokens, i)
+		if errMsg != "" {
+			return nil, next, errMsg
+		}
+		expr.List = append(expr.List, item)
+		i = next
+		if i < len(tokens) && tokens[i].Token_type == SC_comma {
+			i++
+			continue
+		}
+		break
+	}
+	if i >= len(tokens) || tokens[i].Token_type != SC_parenClose {
+		return nil, i, "syntax error: missing closing parenthesis in IN list"
+	}
+	return expr, i + 1, ""
+}
+
+// parseOperand는 열 이름, 값, 또는 집계 함수 하나를 읽습니다.
+func parseOperand(tokens []SC_token, i int) (*Expr, int, string) {
+	if i >= len(tokens) {
+		return nil, i, "syntax error: condition is incomplete"
+	}
+	switch tokens[i].Token_type {
+	case SC_columnName:
+		return &Expr{Kind: EX_column, Name: tokens[i].Token.(string)}, i + 1, ""
+	case SC_number, SC_string, SC_bool, SC_null:
+		return &Expr{Kind: EX_literal, Value: tokens[i]}, i + 1, ""
+	case SC_aggregate:
+		return parseAggregate(tokens, i)
+	}
+	return nil, i, fmt.Sprintf("syntax error: unexpected '%v' in condition", tokens[i].Token)
+}
+
+// parseAggregate는 집계 함수 COUNT(*), SUM(열), COUNT(DISTINCT 열) 등을 읽습니다.
+func parseAggregate(tokens []SC_token, i int) (*Expr, int, string) {
+	name := tokens[i].Token.(string)
+	fn := strings.ToUpper(name)
+	i++
+	if i >= len(tokens) || tokens[i].Token_type != SC_parenOpen {
+		return nil, i, fmt.Sprintf("syntax error: expected ( after %s", fn)
+	}
+	i++
+
+	expr := &Expr{Kind: EX_aggregate, Name: name}
+	if i < len(tokens) && tokens[i].Token_type == SC_star {
+		if name != "count" {
+			return nil, i, fmt.Sprintf("syntax error: %s(*) is not allowed; only COUNT accepts *", fn)
+		}
+		i++
+	} else {
+		if i < len(tokens) && tokens[i].Token_type == SC_distinct {
+			expr.Distinct = true
+			i++
+		}
+		if i >= len(tokens) || tokens[i].Token_type != SC_columnName {
+			return nil, i, fmt.Sprintf("syntax error: expected column name in %s", fn)
+		}
+		expr.Left = &Expr{Kind: EX_column, Name: tokens[i].Token.(string)}
+		i++
+	}
+	if i >= len(tokens) || tokens[i].Token_type != SC_parenClose {
+		return nil, i, fmt.Sprintf("syntax error: missing closing parenthesis in %s", fn)
+	}
+	return expr, i + 1, ""
+}
+
+// FormatExpr는 조건식을 스크립트로 다시 읽을 수 있는 정규화된 문자열로 만듭니다.
+// 키워드는 대문자, 문자열 값은 큰따옴표로 감싸며, 우선순위를 지키기 위해 필요한 곳에 괄호를 넣습니다.
+// 예) age >= 0 AND status IN ("a", "b")
+func FormatExpr(e *Expr) string {
+	switch e.Kind {
+	case EX_column:
+		return e.Name
+	case EX_literal:
+		switch e.Value.Token_type {
+		case SC_string:
+			return QuoteTffString(e.Value.Token.(string))
+		case SC_null:
+			return "NULL"
+		}
+		return fmt.Sprintf("%v", e.Value.Token)
+	case EX_compare:
+		return FormatExpr(e.Left) + " " + e.Op + " " + FormatExpr(e.Right)
+	case EX_and:
+		return formatOperand(e.Left, EX_and) + " AND " + formatOperand(e.Right, EX_and)
+	case EX_or:
+		return FormatExpr(e.Left) + " OR " + FormatExpr(e.Right)
+	case EX_not:
+		return "NOT " + formatOperand(e.Left, EX_not)
+	case EX_in:
+		items := make([]string, len(e.List))
+		for i, item := range e.List {
+			items[i] = FormatExpr(item)
+		}
+		op := " IN ("
+		if e.Negated {
+			op = " NOT IN ("
+		}
+		return FormatExpr(e.Left) + op + strings.Join(items, ", ") + ")"
+	case EX_isNull:
+		if e.Negated {
+			return FormatExpr(e.Left) + " IS NOT NULL"
+		}
+		return FormatExpr(e.Left) + " IS NULL"
+	case EX_aggregate:
+		if e.Left == nil {
+			return strings.ToUpper(e.Name) + "(*)"
+		}
+		if e.Distinct {
+			return strings.ToUpper(e.Name) + "(DISTINCT " + FormatExpr(e.Left) + ")"
+		}
+		return strings.ToUpper(e.Name) + "(" + FormatExpr(e.Left) + ")"
+	}
+	return ""
+}
+
+// formatOperand는 parent 연산자의 피연산자 e를 표기하며, e가 더 느슨하게 묶이면 괄호로 감쌉니다.
+func formatOperand(e *Expr, parent Expr_kind) string {
+	if e.Kind == EX_or || (parent == EX_not && e.Kind == EX_and) {
+		return "(" + FormatExpr(e) + ")"
+	}
+	return FormatExpr(e)
+}
+
+// ExprColumns는 조건식이 참조하는 열 이름을 처음 나온 순서대로 반환합니다.
+func ExprColumns(e *Expr) []string {
+	var names []string
+	seen := make(map[string]bool)
+	var walk func(e *Expr)
+	walk = func(e *Expr) {
+		if e == nil {
+			return
+		}
+		if e.Kind == EX_column && !seen[e.Name] {
+			seen[e.Name] = true
+			names = append(names, e.Name)
+		}
+		walk(e.Left)
+		walk(e.Right)
+		for _, item := range e.List {
+			walk(item)
+		}
+	}
+	walk(e)
+	return names
+}
+
+// ParseCondition은 FormatExpr로 기록한 조건식 문자열을 다시 구문 트리로 읽습니다.
+func ParseCondition(text string) (*Expr, string) {
+	var tokens []SC_token
+	// 조건식 안의 식별자를 열 이름으로 읽도록 CHECK ( ... ) 문맥에서 토큰화합니다.
+	if Parsing_script("check ("+text+")", &tokens) != 0 {
+		return nil, "syntax error: invalid condition"
+	}
+	expr, next, errMsg := ParseExpr(tokens, 2)
+	if errMsg != "" {
+		return nil, errMsg
+	}
+	if next != len(tokens)-1 {
+		return nil, "syntax error: unexpected tokens after condition"
+	}
+	return expr, ""
+}
@@ -0,0 +1,56 @@
+package parsers
+
+import "testing"
+
+func TestFormatExprRoundTrip(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`stock >= 0`, `stock >= 0`},
+		{`status in ('draft', "active")`, `status IN ("draft", "active")`},
+		{`not (a = 1 or b <> 2) and c is not null`, `NOT (a = 1 OR b != 2) AND c IS NOT NULL`},
+		{`(a = 1 or b = 2) and c not in (3)`, `(a = 1 OR b = 2) AND c NOT IN (3)`},
+		{`a = 1 or b = 2 and not flag`, `a = 1 OR b = 2 AND NOT flag`},
+		{`name = "say \"hi\""`, `name = "say \"hi\""`},
+	}
+	for _, tt := range tests {
+		expr, errMsg := ParseCondition(tt.text)
+		if errMsg != "" {
+			t.Errorf("%s: %s", tt.text, errMsg)
+			continue
+		}
+		got := FormatExpr(expr)
+		if got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.text, got, tt.want)
+		}
+		// 정규화된 표기는 다시 읽어도 같은 표기가 됩니다.
+		again, errMsg := ParseCondition(got)
+		if errMsg != "" || FormatExpr(again) != got {
+			t.Errorf("%s: does not round-trip (%s)", got, errMsg)
+		}
+	}
+
+	for _, text := range []string{`a =`, `a in ()`, `a is 1`, `(a = 1`, `a = 1 b`} {
+		if _, errMsg := ParseCondition(text); errMsg == "" {
+			t.Errorf("%s: expected error", text)
+		}
+	}
+}
+
+func TestExprColumns(t *testing.T) {
+	expr, errMsg := ParseCondition(`b > a and (c in (a, 1) or b is null)`)
+	if errMsg != "" {
+		t.Fatal(errMsg)
+	}
+	got := ExprColumns(expr)
+	want := []string{"b", "a", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
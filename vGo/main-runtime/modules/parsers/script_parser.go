@@ -11,32 +11,93 @@ const (
 	SC_none Sc_tokenT = iota
 
 	// DB조작 키워드
-	SC_createTable // 테이블 생성
-	SC_add         // 데이터 추가
-	SC_update      // 데이터 업데이트
-	SC_get         // 데이터 가져오기
-	SC_delete      // 데이터 삭제
+	SC_createTable  // 테이블 생성
+	SC_add          // 데이터 추가
+	SC_update       // 데이터 업데이트
+	SC_get          // 데이터 가져오기
+	SC_delete       // 데이터 삭제
+	SC_convertTable // 테이블 파일 형식 변환
+	SC_verify       // 테이블 파일 검사
+	SC_alterTable   // 테이블 구조 변경
+	SC_dropTable    // 테이블 삭제 (archive로 이동)
+	SC_renameTable  // 테이블 이름 변경
+	SC_truncate     // 테이블의 모든 행 삭제
+	SC_describe     // 테이블 구조 보기
+	SC_showTables   // 테이블 목록 보기
+	SC_showIndexes  // 인덱스 목록 보기
+	SC_migrate      // 디렉토리의 .dcl 마이그레이션 적용
+	SC_select       // 조건에 맞는 행 조회
+
+	// ALTER_TABLE 동작 키워드
+	SC_addColumn    // 열 추가
+	SC_dropColumn   // 열 삭제
+	SC_renameColumn // 열 이름 변경
+	SC_modifyColumn // 열 타입 변경
 
 	// 특수 키워드
-	SC_key     // 열 키 지정
-	SC_notNull // 열 널 허용 하지 아니함
+	SC_key        // 열 키 지정
+	SC_notNull    // 열 널 허용 하지 아니함
+	SC_unique     // 열 값 중복 허용 하지 아니함
+	SC_generated  // 키 값 자동 생성 (Token은 소문자 키워드, 예: auto_increment)
+	SC_binary     // 바이너리 파일 형식
+	SC_option     // --옵션 (Token은 소문자 옵션 이름)
+	SC_to         // rename_column의 새 이름 앞
+	SC_default    // 기본값 지정
+	SC_check      // 검사 제약 CHECK (조건식)
+	SC_constraint // 제약 이름 지정 CONSTRAINT 이름
+	SC_references // 외래 키 REFERENCES 테이블
+	SC_on         // ON DELETE 동작 앞
+	SC_set        // ON DELETE SET NULL
+	SC_refAction  // ON DELETE 동작 (Token은 소문자, restrict 또는 cascade)
+	SC_where      // SELECT의 조건절 WHERE
+	SC_distinct   // SELECT DISTINCT, 집계 함수의 DISTINCT
+	SC_aggregate  // 집계 함수 (Token은 소문자, count, sum, avg, min, max 중 하나)
+	SC_group      // GROUP BY 묶음 앞
+	SC_having     // GROUP BY 결과의 조건절 HAVING
+	SC_order      // ORDER BY 정렬 앞
+	SC_by         // ORDER BY, GROUP BY의 BY
+	SC_sortDir    // 정렬 방향 (Token은 소문자, asc 또는 desc)
+	SC_limit      // LIMIT 행 수
+	SC_offset     // OFFSET 건너뛸 행 수
+	SC_cursor     // CURSOR 이어서 조회할 위치
 
 	// 일반 토큰 타입
 	SC_number // 숫자 타입 토큰
 	SC_string // 문자열 값
+	SC_null   // NULL 값
+	SC_bool   // true / false 값
+	SC_func   // DEFAULT 뒤의 함수 이름 (Token은 소문자, 예: now)
+
+	// 조건식
+	SC_operator // 비교 연산자 (Token은 =, !=, <>, <, <=, >, >= 중 하나)
+	SC_and      // AND
+	SC_or       // OR
+	SC_not      // NOT
+	SC_in       // IN (값 목록)
+	SC_is       // IS [NOT] NULL
 
 	// 열 타입
-	SC_columnNumber // 열 타입 숫자
-	SC_columnText   // 열 타입 문자/문자열
+	SC_columnNumber    // 열 타입 숫자
+	SC_columnText      // 열 타입 문자/문자열
+	SC_columnInteger   // 열 타입 정수
+	SC_columnFloat     // 열 타입 실수
+	SC_columnDecimal   // 열 타입 고정 소수점 (decimal(자릿수))
+	SC_columnBool      // 열 타입 참/거짓
+	SC_columnDate      // 열 타입 날짜
+	SC_columnTimestamp // 열 타입 시각 (시간대 포함)
+	SC_columnBlob      // 열 타입 바이너리 데이터
+	SC_columnJson      // 열 타입 JSON
 
 	// 특수 토큰 타입
-	SC_tableName  // 테이블 이름
-	SC_columnName // 열 이름
+	SC_tableName      // 테이블 이름
+	SC_columnName     // 열 이름
+	SC_constraintName // CONSTRAINT 뒤의 제약 이름
 
 	// 특수문자
 	SC_comma      // , <- 콤마
 	SC_parenOpen  // ( <- 소괄호 열림
 	SC_parenClose // ) <- 소괄호 닫힘
+	SC_star       // * <- COUNT(*)
 	SC_endCmd     // ; <- 명령어 종료
 )
 
@@ -77,9 +138,27 @@ func Parsing_script(input string, tokens *[]SC_token) int {
 			*tokens = append(*tokens, SC_token{Token: ";", Token_type: SC_endCmd})
 			i++
 			continue
-		case '"':
+		case '*':
+			*tokens = append(*tokens, SC_token{Token: "*", Token_type: SC_star})
+			i++
+			continue
+		case '=', '<', '>', '!':
+			// 비교 연산자: =, !=, <>, <, <=, >, >=
+			op := string(c)
+			if i+1 < n && (input[i+1] == '=' || (c == '<' && input[i+1] == '>')) {
+				op += string(input[i+1])
+			}
+			if op == "!" {
+				return 1
+			}
+			*tokens = append(*tokens, SC_token{Token: op, Token_type: SC_operator})
+			i += len(op)
+			continue
+		case '"', '\'':
+			// 문자열은 큰따옴표 또는 작은따옴표로 감쌉니다.
+			quote := c
 			i++
-			start := i
+			var sb strings.Builder
 			escaped := false
 			for i < n {
 				if input[i] == '\\' && !escaped {
@@ -87,8 +166,23 @@ func Parsing_script(input string, tokens *[]SC_token) int {
 					i++
 					continue
 				}
-				if input[i] == '"' && !escaped {
-					break // 종료 큰따옴표 발견
+				if input[i] == quote && !escaped {
+					break // 종료 따옴표 발견
+				}
+				if escaped {
+					// 이스케이프 문자 해석: \n, \r, \t 외에는 문자 그대로 (\", \\ 등)
+					switch input[i] {
+					case 'n':
+						sb.WriteByte('\n')
+					case 'r':
+						sb.WriteByte('\r')
+					case 't':
+						sb.WriteByte('\t')
+					default:
+						sb.WriteByte(input[i])
+					}
+				} else {
+					sb.WriteByte(input[i])
 				}
 				escaped = false
 				i++
@@ -96,9 +190,9 @@ func Parsing_script(input string, tokens *[]SC_token) int {
 			if i >= n {
 				return 1 // 에러: 문자열 종료 없음
 			}
-			strVal := input[start:i]
+			strVal := sb.String()
 			*tokens = append(*tokens, SC_token{Token: strVal, Token_type: SC_string})
-			i++ // 종료 큰따옴표 넘김
+			i++ // 종료 따옴표 넘김
 			continue
 
 		default:
@@ -112,7 +206,13 @@ func Parsing_script(input string, tokens *[]SC_token) int {
 				word := input[start:i]
 				lowerWord := strings.ToLower(word)
 
-				switch lowerWord {
+				// 문맥 키워드는 키워드 자리가 아니면 식별자로 처리합니다.
+				keyword := lowerWord
+				if isContextKeyword(lowerWord) && !inKeywordPosition(lowerWord, *tokens, input[i:]) {
+					keyword = ""
+				}
+
+				switch keyword {
 				case "create_table", "createtable":
 					tok := SC_token{Token: word, Token_type: SC_createTable}
 					*tokens = append(*tokens, tok)
@@ -133,6 +233,174 @@ func Parsing_script(input string, tokens *[]SC_token) int {
 					tok := SC_token{Token: word, Token_type: SC_delete}
 					*tokens = append(*tokens, tok)
 					last_token = tok
+				case "convert_table", "converttable":
+					tok := SC_token{Token: word, Token_type: SC_convertTable}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "verify":
+					tok := SC_token{Token: word, Token_type: SC_verify}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "alter_table", "altertable":
+					tok := SC_token{Token: word, Token_type: SC_alterTable}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "drop_table", "droptable":
+					tok := SC_token{Token: word, Token_type: SC_dropTable}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "rename_table", "renametable":
+					tok := SC_token{Token: word, Token_type: SC_renameTable}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "truncate":
+					tok := SC_token{Token: word, Token_type: SC_truncate}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "describe":
+					tok := SC_token{Token: word, Token_type: SC_describe}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "show_tables", "showtables":
+					tok := SC_token{Token: word, Token_type: SC_showTables}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "show_indexes", "showindexes":
+					tok := SC_token{Token: word, Token_type: SC_showIndexes}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "migrate":
+					tok := SC_token{Token: word, Token_type: SC_migrate}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "select":
+					tok := SC_token{Token: word, Token_type: SC_select}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "add_column":
+					tok := SC_token{Token: word, Token_type: SC_addColumn}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "drop_column":
+					tok := SC_token{Token: word, Token_type: SC_dropColumn}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "rename_column":
+					tok := SC_token{Token: word, Token_type: SC_renameColumn}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "modify_column":
+					tok := SC_token{Token: word, Token_type: SC_modifyColumn}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "to":
+					tok := SC_token{Token: word, Token_type: SC_to}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "default":
+					tok := SC_token{Token: word, Token_type: SC_default}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "check":
+					tok := SC_token{Token: word, Token_type: SC_check}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "constraint":
+					tok := SC_token{Token: word, Token_type: SC_constraint}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "references":
+					tok := SC_token{Token: word, Token_type: SC_references}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "on":
+					tok := SC_token{Token: word, Token_type: SC_on}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "set":
+					tok := SC_token{Token: word, Token_type: SC_set}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "restrict", "cascade":
+					tok := SC_token{Token: lowerWord, Token_type: SC_refAction}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "where":
+					tok := SC_token{Token: word, Token_type: SC_where}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "distinct":
+					tok := SC_token{Token: word, Token_type: SC_distinct}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "count", "sum", "avg", "min", "max":
+					tok := SC_token{Token: lowerWord, Token_type: SC_aggregate}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "group":
+					tok := SC_token{Token: word, Token_type: SC_group}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "having":
+					tok := SC_token{Token: word, Token_type: SC_having}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "order":
+					tok := SC_token{Token: word, Token_type: SC_order}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "by":
+					tok := SC_token{Token: word, Token_type: SC_by}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "asc", "desc":
+					tok := SC_token{Token: lowerWord, Token_type: SC_sortDir}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "limit":
+					tok := SC_token{Token: word, Token_type: SC_limit}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "offset":
+					tok := SC_token{Token: word, Token_type: SC_offset}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "cursor":
+					tok := SC_token{Token: word, Token_type: SC_cursor}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "and":
+					tok := SC_token{Token: word, Token_type: SC_and}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "or":
+					tok := SC_token{Token: word, Token_type: SC_or}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "not":
+					tok := SC_token{Token: word, Token_type: SC_not}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "in":
+					tok := SC_token{Token: word, Token_type: SC_in}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "is":
+					tok := SC_token{Token: word, Token_type: SC_is}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "binary":
+					tok := SC_token{Token: word, Token_type: SC_binary}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "null":
+					tok := SC_token{Token: word, Token_type: SC_null}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "true", "false":
+					tok := SC_token{Token: lowerWord, Token_type: SC_bool}
+					*tokens = append(*tokens, tok)
+					last_token = tok
 				case "key":
 					tok := SC_token{Token: word, Token_type: SC_key}
 					*tokens = append(*tokens, tok)
@@ -141,6 +409,14 @@ func Parsing_script(input string, tokens *[]SC_token) int {
 					tok := SC_token{Token: word, Token_type: SC_notNull}
 					*tokens = append(*tokens, tok)
 					last_token = tok
+				case "unique":
+					tok := SC_token{Token: word, Token_type: SC_unique}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "auto_increment", "autoincrement", "auto_uuid", "auto_ulid":
+					tok := SC_token{Token: lowerWord, Token_type: SC_generated}
+					*tokens = append(*tokens, tok)
+					last_token = tok
 				case "number":
 					tok := SC_token{Token: word, Token_type: SC_columnNumber}
 					*tokens = append(*tokens, tok)
@@ -149,12 +425,79 @@ func Parsing_script(input string, tokens *[]SC_token) int {
 					tok := SC_token{Token: word, Token_type: SC_columnText}
 					*tokens = append(*tokens, tok)
 					last_token = tok
+				case "integer", "int":
+					tok := SC_token{Token: word, Token_type: SC_columnInteger}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "float":
+					tok := SC_token{Token: word, Token_type: SC_columnFloat}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "decimal":
+					tok := SC_token{Token: word, Token_type: SC_columnDecimal}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "bool", "boolean":
+					tok := SC_token{Token: word, Token_type: SC_columnBool}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "date":
+					tok := SC_token{Token: word, Token_type: SC_columnDate}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "timestamp":
+					tok := SC_token{Token: word, Token_type: SC_columnTimestamp}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "blob":
+					tok := SC_token{Token: word, Token_type: SC_columnBlob}
+					*tokens = append(*tokens, tok)
+					last_token = tok
+				case "json":
+					tok := SC_token{Token: word, Token_type: SC_columnJson}
+					*tokens = append(*tokens, tok)
+					last_token = tok
 				default:
-					if last_token.Token_type == SC_columnNumber || last_token.Token_type == SC_columnText {
+					if last_token.Token_type == SC_default {
+						tok := SC_token{Token: lowerWord, Token_type: SC_func}
+						*tokens = append(*tokens, tok)
+						last_token = tok
+					} else if last_token.Token_type == SC_to && statementCommand(*tokens) == SC_renameTable {
+						tok := SC_token{Token: word, Token_type: SC_tableName}
+						*tokens = append(*tokens, tok)
+						last_token = tok
+					} else if last_token.Token_type == SC_constraint {
+						tok := SC_token{Token: word, Token_type: SC_constraintName}
+						*tokens = append(*tokens, tok)
+						last_token = tok
+					} else if isColumnType(last_token.Token_type) ||
+						inKeyClause(*tokens) ||
+						inSelectList(*tokens) ||
+						inAggregate(*tokens) ||
+						inByClause(*tokens) ||
+						inExpression(*tokens) ||
+						last_token.Token_type == SC_dropColumn ||
+						last_token.Token_type == SC_renameColumn ||
+						last_token.Token_type == SC_modifyColumn ||
+						last_token.Token_type == SC_to {
 						tok := SC_token{Token: word, Token_type: SC_columnName}
 						*tokens = append(*tokens, tok)
 						last_token = tok
-					} else if last_token.Token_type == SC_createTable {
+					} else if last_token.Token_type == SC_createTable ||
+						last_token.Token_type == SC_add ||
+						last_token.Token_type == SC_update ||
+						last_token.Token_type == SC_get ||
+						last_token.Token_type == SC_delete ||
+						last_token.Token_type == SC_convertTable ||
+						last_token.Token_type == SC_alterTable ||
+						last_token.Token_type == SC_dropTable ||
+						last_token.Token_type == SC_renameTable ||
+						last_token.Token_type == SC_truncate ||
+						last_token.Token_type == SC_describe ||
+						last_token.Token_type == SC_showIndexes ||
+						last_token.Token_type == SC_select ||
+						(last_token.Token_type == SC_distinct && statementCommand(*tokens) == SC_select) ||
+						last_token.Token_type == SC_references {
 						tok := SC_token{Token: word, Token_type: SC_tableName}
 						*tokens = append(*tokens, tok)
 						last_token = tok
@@ -165,6 +508,33 @@ func Parsing_script(input string, tokens *[]SC_token) int {
 				continue
 			}
 
+			// 옵션 처리: --repair, --dry-run 등
+			if c == '-' && i+1 < n && input[i+1] == '-' {
+				start := i + 2
+				i = start
+				for i < n && (isIdentPart(input[i]) || input[i] == '-') {
+					i++
+				}
+				if i == start {
+					return 1
+				}
+				tok := SC_token{Token: strings.ToLower(input[start:i]), Token_type: SC_option}
+				*tokens = append(*tokens, tok)
+				last_token = tok
+				continue
+			}
+
+			// 16진수 바이너리 값: 0xDEADBEEF
+			if c == '0' && i+1 < n && (input[i+1] == 'x' || input[i+1] == 'X') {
+				start := i
+				i += 2
+				for i < n && isHexDigit(input[i]) {
+					i++
+				}
+				*tokens = append(*tokens, SC_token{Token: input[start:i], Token_type: SC_number})
+				continue
+			}
+
 			// 숫자 처리: 정수, 실수 (부호 포함)
 			if unicode.IsDigit(rune(c)) || c == '-' {
 				start := i
@@ -196,6 +566,305 @@ func Parsing_script(input string, tokens *[]SC_token) int {
 	return 0
 }
 
+// SplitScript는 여러 명령이 담긴 스크립트(.dcl 파일 등)를 명령 단위로 나눕니다.
+// 따옴표 밖의 ;에서 나누며 각 명령은 끝의 ;를 포함합니다. 공백뿐인 명령은 버립니다.
+// 마지막 명령 뒤의 ;는 생략할 수 있습니다. 문자열이 닫히지 않았으면 1을 반환합니다.
+func SplitScript(input string, statements *[]string) int {
+	start := 0
+	var quote byte
+	escaped := false
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case quote != 0:
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ';':
+			if stmt := strings.TrimSpace(input[start : i+1]); stmt != ";" {
+				*statements = append(*statements, stmt)
+			}
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return 1
+	}
+	if stmt := strings.TrimSpace(input[start:]); stmt != "" {
+		*statements = append(*statements, stmt)
+	}
+	return 0
+}
+
+// statementStart는 현재 명령(마지막 ; 이후)의 첫 번째 토큰 위치를 반환합니다.
+func statementStart(tokens []SC_token) int {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i].Token_type == SC_endCmd {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// statementCommand는 현재 명령(마지막 ; 이후)의 첫 번째 토큰 타입을 반환합니다.
+func statementCommand(tokens []SC_token) Sc_tokenT {
+	start := statementStart(tokens)
+	if start >= len(tokens) {
+		return SC_none
+	}
+	return tokens[start].Token_type
+}
+
+// inKeyClause는 마지막 토큰이 테이블 단위 KEY (열1, 열2, ...) 절의 열 이름 자리인지 확인합니다.
+func inKeyClause(tokens []SC_token) bool {
+	if n := len(tokens); n == 0 || (tokens[n-1].Token_type != SC_parenOpen && tokens[n-1].Token_type != SC_comma) {
+		return false
+	}
+	for i := len(tokens) - 1; i >= 0; i-- {
+		switch tokens[i].Token_type {
+		case SC_comma, SC_columnName:
+			continue
+		case SC_parenOpen:
+			return i > 0 && tokens[i-1].Token_type == SC_key &&
+				(i < 2 || tokens[i-2].Token_type == SC_comma || tokens[i-2].Token_type == SC_parenOpen)
+		}
+		return false
+	}
+	return false
+}
+
+// inSelectList는 마지막 토큰 뒤가 SELECT [DISTINCT] [테이블] ( ... ) 결과 열 목록 안인지 확인합니다.
+func inSelectList(tokens []SC_token) bool {
+	i := statementStart(tokens)
+	if i >= len(tokens) || tokens[i].Token_type != SC_select {
+		return false
+	}
+	i++
+	if i < len(tokens) && tokens[i].Token_type == SC_distinct {
+		i++
+	}
+	if i+1 >= len(tokens) || tokens[i].Token_type != SC_tableName || tokens[i+1].Token_type != SC_parenOpen {
+		return false
+	}
+	depth := 0
+	for _, tok := range tokens[i+1:] {
+		switch tok.Token_type {
+		case SC_parenOpen:
+			depth++
+		case SC_parenClose:
+			depth--
+			if depth == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// inAggregate는 마지막 토큰이 집계 함수의 ( 또는 ( DISTINCT 로, 다음이 인자 열 이름 자리인지 확인합니다.
+func inAggregate(tokens []SC_token) bool {
+	n := len(tokens)
+	if n >= 1 && tokens[n-1].Token_type == SC_distinct {
+		n--
+	}
+	return n >= 2 && tokens[n-1].Token_type == SC_parenOpen && tokens[n-2].Token_type == SC_aggregate
+}
+
+// inByClause는 마지막 토큰이 ORDER BY 또는 GROUP BY 목록의 열 이름 자리인지 확인합니다.
+// ORDER BY 목록에는 정렬 방향과 집계 함수가 올 수 있습니다. 예) ORDER BY COUNT(*) DESC, region
+func inByClause(tokens []SC_token) bool {
+	if n := len(tokens); n == 0 || (tokens[n-1].Token_type != SC_by && tokens[n-1].Token_type != SC_comma) {
+		return false
+	}
+	for i := len(tokens) - 1; i >= 0; i-- {
+		switch tokens[i].Token_type {
+		case SC_comma, SC_columnName, SC_sortDir, SC_aggregate, SC_parenOpen, SC_parenClose, SC_star, SC_distinct:
+			continue
+		case SC_by:
+			return i > 0 && (tokens[i-1].Token_type == SC_order || tokens[i-1].Token_type == SC_group)
+		}
+		return false
+	}
+	return false
+}
+
+// inExpression은 마지막 토큰 뒤가 조건식 안인지 확인합니다.
+// 조건식은 CHECK 뒤의 괄호 안이거나 WHERE, HAVING 뒤이며, 조건식 안의 식별자는 열 이름입니다.
+func inExpression(tokens []SC_token) bool {
+	depth := 0
+	for i := len(tokens) - 1; i >= 0; i-- {
+		switch tokens[i].Token_type {
+		case SC_endCmd:
+			return false
+		case SC_where, SC_having:
+			return true
+		case SC_parenClose:
+			depth++
+		case SC_parenOpen:
+			if depth > 0 {
+				depth--
+				continue
+			}
+			// 닫히지 않은 괄호: CHECK의 괄호이거나 조건식 안의 괄호이면 조건식입니다.
+			if i == 0 {
+				return false
+			}
+			switch tokens[i-1].Token_type {
+			case SC_check, SC_where, SC_having, SC_and, SC_or, SC_not, SC_operator, SC_in:
+				return true
+			case SC_parenOpen:
+				return inExpression(tokens[:i])
+			}
+			return false
+		}
+	}
+	return false
+}
+
+// isContextKeyword는 키워드 자리에서만 키워드이고 그 밖에서는 테이블이나 열 이름으로 쓸 수 있는 단어인지 확인합니다.
+func isContextKeyword(lowerWord string) bool {
+	switch lowerWord {
+	case "to", "on", "set", "restrict", "cascade",
+		"order", "by", "asc", "desc", "limit", "offset", "cursor",
+		"distinct", "count", "sum", "avg", "min", "max", "group", "having":
+		return true
+	}
+	return false
+}
+
+// inKeywordPosition은 문맥 키워드가 지금 위치에서 키워드인지 확인합니다.
+// tokens는 지금까지의 토큰, rest는 단어 뒤의 입력입니다.
+func inKeywordPosition(lowerWord string, tokens []SC_token, rest string) bool {
+	last, prev := SC_none, SC_none
+	if n := len(tokens); n > 0 {
+		last = tokens[n-1].Token_type
+		if n > 1 {
+			prev = tokens[n-2].Token_type
+		}
+	}
+
+	switch lowerWord {
+	case "to":
+		// RENAME_TABLE [테이블] TO, RENAME_COLUMN [열] TO
+		return (last == SC_tableName && statementCommand(tokens) == SC_renameTable) ||
+			(last == SC_columnName && prev == SC_renameColumn)
+	case "on":
+		// REFERENCES [테이블] ON DELETE
+		return last == SC_tableName && prev == SC_references
+	case "set", "restrict", "cascade":
+		// ON DELETE RESTRICT|CASCADE|SET NULL
+		return last == SC_delete && prev == SC_on
+	case "by":
+		return last == SC_order || last == SC_group
+	case "asc", "desc":
+		return inOrderByItem(tokens)
+	}
+
+	next := strings.TrimLeftFunc(rest, unicode.IsSpace)
+	switch lowerWord {
+	case "count", "sum", "avg", "min", "max":
+		// 집계 함수는 열 이름 자리에서 뒤에 (가 올 때만입니다.
+		return strings.HasPrefix(next, "(") &&
+			(inSelectList(tokens) || inByClause(tokens) || inExpression(tokens))
+	case "distinct":
+		// 집계 함수( DISTINCT [열] )
+		if last == SC_parenOpen && prev == SC_aggregate {
+			return nextWord(next) != ""
+		}
+	}
+
+	if statementCommand(tokens) != SC_select {
+		return false
+	}
+	switch lowerWord {
+	case "distinct":
+		// SELECT DISTINCT [테이블]
+		return last == SC_select && nextWord(next) != "" && !startsClause(next)
+	case "having":
+		// HAVING은 테이블 이름, 결과 열 목록, GROUP BY 목록, 조건식의 값 뒤에 옵니다.
+		return !inSelectList(tokens) && !inAggregate(tokens) && endsOperand(last)
+	}
+
+	// 절 키워드: 테이블 이름 자리, 결과 열 목록, 집계 함수 인자 밖에서, 뒤에 오는 것으로 판단합니다.
+	if last == SC_select || (last == SC_distinct && prev == SC_select) || inSelectList(tokens) || inAggregate(tokens) {
+		return false
+	}
+	return startsClause(lowerWord + rest)
+}
+
+// startsClause는 입력이 SELECT의 절(WHERE, ORDER BY, GROUP BY, LIMIT, OFFSET, CURSOR)로 시작하는지 확인합니다.
+// ORDER와 GROUP은 뒤에 BY가, LIMIT과 OFFSET은 숫자가, CURSOR는 문자열이 와야 절입니다.
+func startsClause(input string) bool {
+	word := nextWord(input)
+	next := strings.TrimLeftFunc(input[len(word):], unicode.IsSpace)
+	switch word {
+	case "where":
+		return true
+	case "order", "group":
+		return nextWord(next) == "by"
+	case "limit", "offset":
+		return next != "" && (unicode.IsDigit(rune(next[0])) || next[0] == '-')
+	case "cursor":
+		return next != "" && (next[0] == '"' || next[0] == '\'')
+	}
+	return false
+}
+
+// endsOperand는 토큰이 테이블 이름, 열 이름, 값, 닫는 괄호처럼 뒤에 절이 올 수 있는 토큰인지 확인합니다.
+func endsOperand(t Sc_tokenT) bool {
+	switch t {
+	case SC_tableName, SC_columnName, SC_number, SC_string, SC_null, SC_bool, SC_parenClose:
+		return true
+	}
+	return false
+}
+
+// inOrderByItem은 마지막 토큰이 ORDER BY 목록의 정렬 기준(열 이름 또는 집계 함수)의 끝인지 확인합니다.
+func inOrderByItem(tokens []SC_token) bool {
+	n := len(tokens)
+	if n == 0 || (tokens[n-1].Token_type != SC_columnName && tokens[n-1].Token_type != SC_parenClose) {
+		return false
+	}
+	for i := n - 1; i >= 0; i-- {
+		switch tokens[i].Token_type {
+		case SC_comma, SC_columnName, SC_sortDir, SC_aggregate, SC_parenOpen, SC_parenClose, SC_star, SC_distinct:
+			continue
+		case SC_by:
+			return i > 0 && tokens[i-1].Token_type == SC_order
+		}
+		return false
+	}
+	return false
+}
+
+// nextWord는 입력의 첫 번째 식별자를 소문자로 반환합니다. 식별자로 시작하지 않으면 빈 문자열입니다.
+func nextWord(input string) string {
+	i := 0
+	for i < len(input) && isIdentPart(input[i]) {
+		i++
+	}
+	if i == 0 || !isIdentStart(input[0]) {
+		return ""
+	}
+	return strings.ToLower(input[:i])
+}
+
+// isColumnType은 토큰 타입이 열 타입 키워드인지 확인합니다.
+func isColumnType(t Sc_tokenT) bool {
+	switch t {
+	case SC_columnNumber, SC_columnText, SC_columnInteger, SC_columnFloat, SC_columnDecimal,
+		SC_columnBool, SC_columnDate, SC_columnTimestamp, SC_columnBlob, SC_columnJson:
+		return true
+	}
+	return false
+}
+
 func isIdentStart(c byte) bool {
 	return (c >= 'A' && c <= 'Z') ||
 		(c >= 'a' && c <= 'z') ||
@@ -204,4 +873,10 @@ func isIdentStart(c byte) bool {
 
 func isIdentPart(c byte) bool {
 	return isIdentStart(c) || (c >= '0' && c <= '9')
-}
\ No newline at end of file
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') ||
+		(c >= 'a' && c <= 'f') ||
+		(c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,163 @@
+package parsers
+
+import "testing"
+
+// tokenTypes는 스크립트를 파싱하여 토큰 타입 목록을 반환합니다.
+func tokenTypes(t *testing.T, script string) []Sc_tokenT {
+	t.Helper()
+	var tokens []SC_token
+	if Parsing_script(script, &tokens) != 0 {
+		t.Fatalf("%s: parse failed", script)
+	}
+	types := make([]Sc_tokenT, len(tokens))
+	for i, tok := range tokens {
+		types[i] = tok.Token_type
+	}
+	return types
+}
+
+func checkTokenTypes(t *testing.T, script string, want ...Sc_tokenT) {
+	t.Helper()
+	got := tokenTypes(t, script)
+	if len(got) != len(want) {
+		t.Errorf("%s: got %v, want %v", script, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s: token %d is %v, want %v (all: %v)", script, i, got[i], want[i], got)
+			return
+		}
+	}
+}
+
+func TestStringLiteralEscapes(t *testing.T) {
+	var tokens []SC_token
+	if Parsing_script(`"a \"b\", \\c\n\td"`, &tokens) != 0 {
+		t.Fatal("parse failed")
+	}
+	want := "a \"b\", \\c\n\td"
+	if len(tokens) != 1 || tokens[0].Token_type != SC_string || tokens[0].Token != want {
+		t.Errorf("got %v, want one string %q", tokens, want)
+	}
+}
+
+func TestNullLiteral(t *testing.T) {
+	var tokens []SC_token
+	if Parsing_script(`add t (1, NULL, null, "NULL", "");`, &tokens) != 0 {
+		t.Fatal("parse failed")
+	}
+	want := []Sc_tokenT{
+		SC_add, SC_tableName, SC_parenOpen, SC_number, SC_comma, SC_null, SC_comma, SC_null, SC_comma,
+		SC_string, SC_comma, SC_string, SC_parenClose, SC_endCmd,
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %d tokens %v, want %d", len(tokens), tokens, len(want))
+	}
+	for i := range want {
+		if tokens[i].Token_type != want[i] {
+			t.Errorf("token %d (%v): got type %d, want %d", i, tokens[i].Token, tokens[i].Token_type, want[i])
+		}
+	}
+}
+
+func TestTypedLiterals(t *testing.T) {
+	var tokens []SC_token
+	if Parsing_script(`add t (1, TRUE, false, 0xDEADbeef, 0x);`, &tokens) != 0 {
+		t.Fatal("parse failed")
+	}
+	want := []SC_token{
+		{Token: "true", Token_type: SC_bool}, {Token: "false", Token_type: SC_bool},
+		{Token: "0xDEADbeef", Token_type: SC_number}, {Token: "0x", Token_type: SC_number},
+	}
+	var got []SC_token
+	for _, tok := range tokens {
+		if tok.Token_type == SC_bool || (tok.Token_type == SC_number && tok.Token != "1") {
+			got = append(got, tok)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].Token_type != want[i].Token_type || got[i].Token != want[i].Token {
+			t.Errorf("token %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestContextKeywordsAsNames(t *testing.T) {
+	// 키워드 자리가 아닌 문맥 키워드는 테이블이나 열 이름입니다.
+	checkTokenTypes(t, "create_table order (integer limit notnull key, text by, text to, text on, text set, text cursor);",
+		SC_createTable, SC_tableName, SC_parenOpen,
+		SC_columnInteger, SC_columnName, SC_notNull, SC_key, SC_comma,
+		SC_columnText, SC_columnName, SC_comma,
+		SC_columnText, SC_columnName, SC_comma,
+		SC_columnText, SC_columnName, SC_comma,
+		SC_columnText, SC_columnName, SC_comma,
+		SC_columnText, SC_columnName, SC_parenClose, SC_endCmd)
+	checkTokenTypes(t, "rename_table order to offset;",
+		SC_renameTable, SC_tableName, SC_to, SC_tableName, SC_endCmd)
+	checkTokenTypes(t, "alter_table t rename_column to to set;",
+		SC_alterTable, SC_tableName, SC_renameColumn, SC_columnName, SC_to, SC_columnName, SC_endCmd)
+	checkTokenTypes(t, "select order where limit = 1 and cursor is null;",
+		SC_select, SC_tableName, SC_where, SC_columnName, SC_operator, SC_number,
+		SC_and, SC_columnName, SC_is, SC_null, SC_endCmd)
+	checkTokenTypes(t, "select t order by by desc, desc limit 10 offset 5 cursor \"c\";",
+		SC_select, SC_tableName, SC_order, SC_by, SC_columnName, SC_sortDir, SC_comma, SC_columnName,
+		SC_limit, SC_number, SC_offset, SC_number, SC_cursor, SC_string, SC_endCmd)
+	checkTokenTypes(t, "select t (order, limit) where offset > 0 order by order;",
+		SC_select, SC_tableName, SC_parenOpen, SC_columnName, SC_comma, SC_columnName, SC_parenClose,
+		SC_where, SC_columnName, SC_operator, SC_number, SC_order, SC_by, SC_columnName, SC_endCmd)
+}
+
+func TestForeignKeyKeywords(t *testing.T) {
+	checkTokenTypes(t, "create_table c (integer id notnull key, integer on references set on delete set null);",
+		SC_createTable, SC_tableName, SC_parenOpen,
+		SC_columnInteger, SC_columnName, SC_notNull, SC_key, SC_comma,
+		SC_columnInteger, SC_columnName, SC_references, SC_tableName, SC_on, SC_delete, SC_set, SC_null,
+		SC_parenClose, SC_endCmd)
+	checkTokenTypes(t, "create_table c (integer id notnull key references p on delete cascade);",
+		SC_createTable, SC_tableName, SC_parenOpen,
+		SC_columnInteger, SC_columnName, SC_notNull, SC_key, SC_references, SC_tableName, SC_on, SC_delete, SC_refAction,
+		SC_parenClose, SC_endCmd)
+}
+
+func TestAggregateWordsAsNames(t *testing.T) {
+	// 집계 함수 이름은 뒤에 (가 올 때만 집계 함수입니다.
+	checkTokenTypes(t, "create_table count (integer sum notnull key, integer min, text group, text having, text distinct);",
+		SC_createTable, SC_tableName, SC_parenOpen,
+		SC_columnInteger, SC_columnName, SC_notNull, SC_key, SC_comma,
+		SC_columnInteger, SC_columnName, SC_comma,
+		SC_columnText, SC_columnName, SC_comma,
+		SC_columnText, SC_columnName, SC_comma,
+		SC_columnText, SC_columnName, SC_parenClose, SC_endCmd)
+	checkTokenTypes(t, "select count (group, max(sum), count(distinct distinct)) where min > 0 group by group having count(*) > 1 order by max(sum) desc;",
+		SC_select, SC_tableName, SC_parenOpen,
+		SC_columnName, SC_comma,
+		SC_aggregate, SC_parenOpen, SC_columnName, SC_parenClose, SC_comma,
+		SC_aggregate, SC_parenOpen, SC_distinct, SC_columnName, SC_parenClose, SC_parenClose,
+		SC_where, SC_columnName, SC_operator, SC_number,
+		SC_group, SC_by, SC_columnName,
+		SC_having, SC_aggregate, SC_parenOpen, SC_star, SC_parenClose, SC_operator, SC_number,
+		SC_order, SC_by, SC_aggregate, SC_parenOpen, SC_columnName, SC_parenClose, SC_sortDir, SC_endCmd)
+	checkTokenTypes(t, "select distinct having (count);",
+		SC_select, SC_distinct, SC_tableName, SC_parenOpen, SC_columnName, SC_parenClose, SC_endCmd)
+	checkTokenTypes(t, "select distinct where having = 1;",
+		SC_select, SC_tableName, SC_where, SC_columnName, SC_operator, SC_number, SC_endCmd)
+	checkTokenTypes(t, "select t group by having, count;",
+		SC_select, SC_tableName, SC_group, SC_by, SC_columnName, SC_comma, SC_columnName, SC_endCmd)
+}
+
+func TestAggregateOutsideSelect(t *testing.T) {
+	// CHECK 조건식 안의 집계 함수는 집계 함수로 읽고, 실행할 때 거부합니다.
+	checkTokenTypes(t, "create_table t (integer id notnull key check (count(*) > 0));",
+		SC_createTable, SC_tableName, SC_parenOpen,
+		SC_columnInteger, SC_columnName, SC_notNull, SC_key,
+		SC_check, SC_parenOpen, SC_aggregate, SC_parenOpen, SC_star, SC_parenClose, SC_operator, SC_number, SC_parenClose,
+		SC_parenClose, SC_endCmd)
+	checkTokenTypes(t, "select o (count(*)) where having = 1 having count(*) > 1;",
+		SC_select, SC_tableName, SC_parenOpen, SC_aggregate, SC_parenOpen, SC_star, SC_parenClose, SC_parenClose,
+		SC_where, SC_columnName, SC_operator, SC_number,
+		SC_having, SC_aggregate, SC_parenOpen, SC_star, SC_parenClose, SC_operator, SC_number, SC_endCmd)
+}
@@ -14,21 +14,38 @@ const (
 	Tff_TableS
 	Tff_Cnumber
 	Tff_Ctext
+	Tff_Cinteger
+	Tff_Cfloat
+	Tff_Cdecimal // Token은 소수 자릿수(int)
+	Tff_Cbool
+	Tff_Cdate
+	Tff_Ctimestamp
+	Tff_Cblob
+	Tff_Cjson
 	Tff_ColumnName
 	Tff_dataSection
 
 	// 속성
 	Tff_Notnull
 	Tff_Key
+	Tff_Unique
+	Tff_Generated  // AUTO_INCREMENT(다음 토큰이 카운터의 다음 값, Tff_numeric), AUTO_UUID, AUTO_ULID
+	Tff_Default    // 다음 토큰이 기본값 (값 토큰 또는 Tff_function)
+	Tff_Check      // CHECK 제약: Token은 제약 이름, 다음 토큰이 조건식 (Tff_string)
+	Tff_References // 외래 키: Token은 참조하는 테이블 이름
+	Tff_OnDelete   // 외래 키의 삭제 동작: Token은 RESTRICT, CASCADE, SET_NULL 중 하나
 
 	// 데이터 타입
 	Tff_string
-	Tff_float64
+	Tff_numeric // Token은 숫자 리터럴 원문(string), 정밀도 손실 없이 열 타입에 맞게 변환
+	Tff_null
+	Tff_function // Token은 소문자 함수 이름 (예: "now")
 
 	// 특수 토큰
 	Tff_begin
 	Tff_end
 	Tff_dataStart
+	Tff_tombstoneStart
 	Tff_dataEnd
 	Tff_comma
 )
@@ -52,6 +69,23 @@ func isNumeric(s string) bool {
 	return true
 }
 
+// parseDecimalScale은 "decimal(2)" 형식에서 소수 자릿수를 읽습니다.
+// 자릿수가 없으면 0입니다.
+func parseDecimalScale(typeName string) (int, bool) {
+	rest := strings.TrimPrefix(typeName, "decimal")
+	if rest == "" {
+		return 0, true
+	}
+	if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+		return 0, false
+	}
+	scale, err := strconv.Atoi(rest[1 : len(rest)-1])
+	if err != nil || scale < 0 {
+		return 0, false
+	}
+	return scale, true
+}
+
 // ParseHeader : DATA_SECTION 전까지 파싱
 func ParseHeader(input string, tokens *[]Tff_token) int {
 	lines := strings.Split(input, "\n")
@@ -59,14 +93,25 @@ func ParseHeader(input string, tokens *[]Tff_token) int {
 
 	// 속성 매핑
 	attrMap := map[string]Tff_tokenT{
-		"NOTNULL": Tff_Notnull,
-		"KEY":     Tff_Key,
+		"NOTNULL":   Tff_Notnull,
+		"KEY":       Tff_Key,
+		"UNIQUE":    Tff_Unique,
+		"AUTO_UUID": Tff_Generated,
+		"AUTO_ULID": Tff_Generated,
 	}
 
 	// 열 타입 매핑
 	typeMap := map[string]Tff_tokenT{
-		"number": Tff_Cnumber,
-		"text":   Tff_Ctext,
+		"number":    Tff_Cnumber,
+		"text":      Tff_Ctext,
+		"integer":   Tff_Cinteger,
+		"float":     Tff_Cfloat,
+		"bool":      Tff_Cbool,
+		"boolean":   Tff_Cbool,
+		"date":      Tff_Cdate,
+		"timestamp": Tff_Ctimestamp,
+		"blob":      Tff_Cblob,
+		"json":      Tff_Cjson,
 	}
 
 	*tokens = make([]Tff_token, 0, len(lines)*2) // 대략 capacity 예측
@@ -112,36 +157,84 @@ func ParseHeader(input string, tokens *[]Tff_token) int {
 
 		// 테이블 내용
 		if inTable {
-			// , 단위로만 쪼개기
-			start := 0
-			for i := 0; i <= len(line); i++ {
-				if i == len(line) || line[i] == ',' {
-					part := strings.TrimSpace(line[start:i])
-					start = i + 1
-					if part == "" {
-						continue
-					}
+			// 따옴표 밖의 , 단위로 열 정의를 나눔
+			defs, ok := splitHeaderLine(line)
+			if !ok {
+				return 1
+			}
+			for _, fields := range defs {
+				if len(fields) < 2 {
+					return 1
+				}
 
-					fields := strings.Fields(part)
-					if len(fields) < 2 {
+				// CHECK 이름 "조건식"
+				if strings.ToUpper(fields[0]) == "CHECK" {
+					if len(fields) != 3 || !strings.HasPrefix(fields[2], "\"") {
+						return 1
+					}
+					expr, _, ok := unquoteTffString(fields[2], 0)
+					if !ok {
 						return 1
 					}
+					*tokens = append(*tokens, Tff_token{fields[1], Tff_Check}, Tff_token{expr, Tff_string})
+					continue
+				}
 
-					colType := strings.ToLower(fields[0])
-					if t, ok := typeMap[colType]; ok {
-						*tokens = append(*tokens, Tff_token{fields[0], t})
-					} else {
-						*tokens = append(*tokens, Tff_token{fields[0], Tff_none})
+				colType := strings.ToLower(fields[0])
+				if t, ok := typeMap[colType]; ok {
+					*tokens = append(*tokens, Tff_token{fields[0], t})
+				} else if strings.HasPrefix(colType, "decimal") {
+					// DECIMAL(자릿수)
+					scale, ok := parseDecimalScale(colType)
+					if !ok {
+						return 1
 					}
+					*tokens = append(*tokens, Tff_token{scale, Tff_Cdecimal})
+				} else {
+					*tokens = append(*tokens, Tff_token{fields[0], Tff_none})
+				}
 
-					*tokens = append(*tokens, Tff_token{fields[1], Tff_ColumnName})
+				*tokens = append(*tokens, Tff_token{fields[1], Tff_ColumnName})
 
-					if len(fields) > 2 {
-						for _, attr := range fields[2:] {
-							if t, ok := attrMap[strings.ToUpper(attr)]; ok {
-								*tokens = append(*tokens, Tff_token{attr, t})
-							}
+				for j := 2; j < len(fields); j++ {
+					attr := fields[j]
+					if strings.ToUpper(attr) == "DEFAULT" {
+						if j+1 >= len(fields) {
+							return 1
 						}
+						j++
+						value, ok := parseDefaultValue(fields[j])
+						if !ok {
+							return 1
+						}
+						*tokens = append(*tokens, Tff_token{attr, Tff_Default}, value)
+						continue
+					}
+					if strings.ToUpper(attr) == "AUTO_INCREMENT" {
+						if j+1 >= len(fields) {
+							return 1
+						}
+						j++
+						if _, err := strconv.ParseInt(fields[j], 10, 64); err != nil {
+							return 1
+						}
+						*tokens = append(*tokens, Tff_token{attr, Tff_Generated}, Tff_token{fields[j], Tff_numeric})
+						continue
+					}
+					if upper := strings.ToUpper(attr); upper == "REFERENCES" || upper == "ON_DELETE" {
+						if j+1 >= len(fields) {
+							return 1
+						}
+						j++
+						if upper == "REFERENCES" {
+							*tokens = append(*tokens, Tff_token{fields[j], Tff_References})
+						} else {
+							*tokens = append(*tokens, Tff_token{strings.ToUpper(fields[j]), Tff_OnDelete})
+						}
+						continue
+					}
+					if t, ok := attrMap[strings.ToUpper(attr)]; ok {
+						*tokens = append(*tokens, Tff_token{attr, t})
 					}
 				}
 			}
@@ -151,36 +244,155 @@ func ParseHeader(input string, tokens *[]Tff_token) int {
 	return 0
 }
 
+// splitHeaderLine은 TABLE_S 블록의 한 줄을 열 정의별 필드 목록으로 나눕니다.
+// 큰따옴표로 감싼 값(텍스트 기본값)은 공백과 콤마를 포함해도 하나의 필드입니다.
+func splitHeaderLine(line string) ([][]string, bool) {
+	var defs [][]string
+	var fields []string
+	i := 0
+	for i < len(line) {
+		switch c := line[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == ',':
+			if len(fields) > 0 {
+				defs = append(defs, fields)
+			}
+			fields = nil
+			i++
+		case c == '"':
+			_, next, ok := unquoteTffString(line, i)
+			if !ok {
+				return nil, false
+			}
+			fields = append(fields, line[i:next])
+			i = next
+		default:
+			start := i
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' && line[i] != ',' {
+				i++
+			}
+			fields = append(fields, line[start:i])
+		}
+	}
+	if len(fields) > 0 {
+		defs = append(defs, fields)
+	}
+	return defs, true
+}
+
+// parseDefaultValue는 DEFAULT 뒤의 필드를 값 토큰으로 변환합니다.
+// 따옴표 값은 Tff_string, NULL은 Tff_null, NOW() 같은 함수는 Tff_function,
+// 그 외는 데이터 줄의 따옴표 없는 값과 같이 Tff_numeric 또는 Tff_string입니다.
+func parseDefaultValue(field string) (Tff_token, bool) {
+	switch {
+	case strings.HasPrefix(field, "\""):
+		val, next, ok := unquoteTffString(field, 0)
+		if !ok || next != len(field) {
+			return Tff_token{}, false
+		}
+		return Tff_token{val, Tff_string}, true
+	case field == "NULL":
+		return Tff_token{nil, Tff_null}, true
+	case strings.HasSuffix(field, "()"):
+		return Tff_token{strings.ToLower(strings.TrimSuffix(field, "()")), Tff_function}, true
+	case isNumeric(field):
+		return Tff_token{field, Tff_numeric}, true
+	}
+	return Tff_token{field, Tff_string}, true
+}
+
 // ParseDataLine : Data-> [ ... ] ->End
+// 큰따옴표로 감싼 값은 QuoteTffString의 이스케이프 규칙으로 해석하고,
+// 따옴표 없는 값은 이전 형식과 같이 다음 콤마까지를 값으로 봅니다.
+// 따옴표 없는 NULL은 NULL 값(Tff_null)이며, 문자열 "NULL"과 구별됩니다(이전 형식 줄은 ParseLegacyDataLine).
+// 이전 형식의 빈 칸(예: [2, , 4])은 빈 문자열 값입니다.
 func ParseDataLine(line string, tokens *[]Tff_token) int {
+	return parseRecordLine(line, "Data->", Tff_dataStart, true, tokens)
+}
+
+// ParseLegacyDataLine은 NULL 표기가 생기기 전에 기록된 Data-> 줄을 파싱합니다.
+// 이전 형식에는 NULL 값이 없었으므로 따옴표 없는 NULL도 문자열 "NULL"(Tff_string)입니다.
+func ParseLegacyDataLine(line string, tokens *[]Tff_token) int {
+	return parseRecordLine(line, "Data->", Tff_dataStart, false, tokens)
+}
+
+// ParseTombstoneLine : Del-> [키] ->End
+// 키 값이 삭제되었음을 나타내는 레코드이며, 값 표기는 ParseDataLine과 같습니다.
+func ParseTombstoneLine(line string, tokens *[]Tff_token) int {
+	return parseRecordLine(line, "Del->", Tff_tombstoneStart, true, tokens)
+}
+
+// parseRecordLine은 [prefix] [ ... ] ->End 형식의 레코드 줄을 파싱합니다.
+// nullLiteral이 false이면 따옴표 없는 NULL을 문자열로 읽습니다.
+func parseRecordLine(line string, prefix string, startType Tff_tokenT, nullLiteral bool, tokens *[]Tff_token) int {
 	line = strings.TrimSpace(line)
-	if !strings.HasPrefix(line, "Data->") || !strings.HasSuffix(line, "->End") {
+	if !strings.HasPrefix(line, prefix) || !strings.HasSuffix(line, "->End") {
 		return 1
 	}
 
-	*tokens = append(*tokens, Tff_token{"Data->", Tff_dataStart})
+	*tokens = append(*tokens, Tff_token{prefix, startType})
 
-	body := strings.TrimPrefix(line, "Data->")
+	body := strings.TrimPrefix(line, prefix)
 	body = strings.TrimSuffix(body, "->End")
 	body = strings.TrimSpace(body)
-	body = strings.Trim(body, "[]")
+	body = strings.TrimPrefix(body, "[")
+	body = strings.TrimSuffix(body, "]")
 
-	start := 0
-	for i := 0; i <= len(body); i++ {
-		if i == len(body) || body[i] == ',' {
-			part := strings.TrimSpace(body[start:i])
-			start = i + 1
-			if part == "" {
-				continue
+	i := 0
+	for i < len(body) {
+		// 값 앞의 공백 건너뛰기
+		for i < len(body) && (body[i] == ' ' || body[i] == '\t') {
+			i++
+		}
+		if i >= len(body) {
+			break
+		}
+
+		if body[i] == '"' {
+			// 따옴표로 감싼 문자열
+			val, next, ok := unquoteTffString(body, i)
+			if !ok {
+				return 1
+			}
+			*tokens = append(*tokens, Tff_token{val, Tff_string})
+			i = next
+
+			// 닫는 따옴표 뒤에는 공백과 콤마만 올 수 있음
+			for i < len(body) && (body[i] == ' ' || body[i] == '\t') {
+				i++
+			}
+			if i < len(body) && body[i] != ',' {
+				return 1
+			}
+		} else {
+			// 따옴표 없는 값 (숫자 또는 이전 형식의 문자열)
+			end := strings.IndexByte(body[i:], ',')
+			if end == -1 {
+				end = len(body)
+			} else {
+				end += i
 			}
-			if isNumeric(part) {
-				f, _ := strconv.ParseFloat(part, 64)
-				*tokens = append(*tokens, Tff_token{f, Tff_float64})
+			part := strings.TrimSpace(body[i:end])
+			i = end
+			if part == "" {
+				// 이전 형식은 빈 문자열을 따옴표 없이 빈 칸으로 기록했으므로 값 하나로 읽음
+				*tokens = append(*tokens, Tff_token{"", Tff_string})
+			} else if part == "NULL" && nullLiteral {
+				*tokens = append(*tokens, Tff_token{nil, Tff_null})
+			} else if isNumeric(part) {
+				*tokens = append(*tokens, Tff_token{part, Tff_numeric})
 			} else {
 				*tokens = append(*tokens, Tff_token{part, Tff_string})
 			}
-			if i < len(body) {
-				*tokens = append(*tokens, Tff_token{",", Tff_comma})
+		}
+
+		if i < len(body) {
+			*tokens = append(*tokens, Tff_token{",", Tff_comma})
+			i++ // 콤마 넘김
+			if strings.TrimSpace(body[i:]) == "" {
+				// 마지막 콤마 뒤의 빈 칸도 빈 값
+				*tokens = append(*tokens, Tff_token{"", Tff_string})
 			}
 		}
 	}
@@ -188,3 +400,97 @@ func ParseDataLine(line string, tokens *[]Tff_token) int {
 	*tokens = append(*tokens, Tff_token{"->End", Tff_dataEnd})
 	return 0
 }
+
+// QuoteTffString은 문자열을 데이터 줄에 기록할 수 있도록 큰따옴표로 감쌉니다.
+// \\, \", 줄바꿈(\n), 캐리지 리턴(\r), 탭(\t)을 이스케이프하므로
+// 콤마, 대괄호, ->End 등을 포함한 어떤 문자열도 한 줄로 손실 없이 기록됩니다.
+func QuoteTffString(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// unquoteTffString은 s[start]의 여는 따옴표부터 닫는 따옴표까지를 해석합니다.
+// 해석된 문자열과 닫는 따옴표 다음 위치를 반환합니다.
+func unquoteTffString(s string, start int) (string, int, bool) {
+	var b strings.Builder
+	i := start + 1
+	for i < len(s) {
+		c := s[i]
+		if c == '"' {
+			return b.String(), i + 1, true
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+
+		if i+1 >= len(s) {
+			return "", 0, false
+		}
+		switch s[i+1] {
+		case '\\':
+			b.WriteByte('\\')
+		case '"':
+			b.WriteByte('"')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			return "", 0, false
+		}
+		i += 2
+	}
+	return "", 0, false // 닫는 따옴표 없음
+}
+
+// ParseLsnLine : Lsn-> [번호] [파일 체크섬] ->End
+// 테이블에 마지막으로 반영된 WAL 레코드 번호와, 그 줄 앞까지의 파일 체크섬을 읽습니다.
+// 파일 체크섬이 없는 이전 형식이면 fileSum은 빈 문자열입니다.
+func ParseLsnLine(line string, lsn *int64, fileSum *string) int {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "Lsn->") || !strings.HasSuffix(line, "->End") {
+		return 1
+	}
+
+	body := strings.TrimPrefix(line, "Lsn->")
+	body = strings.TrimSuffix(body, "->End")
+	fields := strings.Fields(body)
+	if len(fields) == 0 || len(fields) > 2 {
+		return 1
+	}
+
+	n, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil || n < 0 {
+		return 1
+	}
+
+	*fileSum = ""
+	if len(fields) == 2 {
+		*fileSum = fields[1]
+	}
+	*lsn = n
+	return 0
+}
@@ -0,0 +1,152 @@
+package parsers
+
+import "testing"
+
+// dataValues는 ParseDataLine이 만든 토큰에서 값 토큰만 꺼냅니다.
+func dataValues(t *testing.T, line string) []Tff_token {
+	t.Helper()
+	var tokens []Tff_token
+	if ParseDataLine(line, &tokens) != 0 {
+		t.Fatalf("%s: parse failed", line)
+	}
+	var values []Tff_token
+	for _, tok := range tokens {
+		switch tok.Token_type {
+		case Tff_string, Tff_numeric, Tff_null:
+			values = append(values, tok)
+		}
+	}
+	return values
+}
+
+func TestParseDataLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want []Tff_token
+	}{
+		{`Data-> [1, "a, [b]", NULL, "NULL", ""] ->End`, []Tff_token{
+			{"1", Tff_numeric}, {"a, [b]", Tff_string}, {nil, Tff_null}, {"NULL", Tff_string}, {"", Tff_string},
+		}},
+		{`Data-> [1, "say \"hi\" ->End"] ->End`, []Tff_token{
+			{"1", Tff_numeric}, {`say "hi" ->End`, Tff_string},
+		}},
+		// 따옴표 없는 값은 이전 형식과 같이 다음 콤마까지입니다.
+		{`Data-> [3, kim lee, x] ->End`, []Tff_token{
+			{"3", Tff_numeric}, {"kim lee", Tff_string}, {"x", Tff_string},
+		}},
+		// 이전 형식의 빈 칸은 빈 문자열 값입니다.
+		{`Data-> [2, , 4] ->End`, []Tff_token{
+			{"2", Tff_numeric}, {"", Tff_string}, {"4", Tff_numeric},
+		}},
+		{`Data-> [3, lee, ] ->End`, []Tff_token{
+			{"3", Tff_numeric}, {"lee", Tff_string}, {"", Tff_string},
+		}},
+	}
+	for _, tt := range tests {
+		got := dataValues(t, tt.line)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %d values %v, want %v", tt.line, len(got), got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: value %d = %v, want %v", tt.line, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestParseLegacyDataLine(t *testing.T) {
+	// 이전 형식에는 NULL 값이 없었으므로 따옴표 없는 NULL은 문자열입니다.
+	var tokens []Tff_token
+	if ParseLegacyDataLine(`Data-> [1, NULL, "NULL", 2] ->End`, &tokens) != 0 {
+		t.Fatal("parse failed")
+	}
+	want := []Tff_token{
+		{"Data->", Tff_dataStart}, {"1", Tff_numeric}, {"NULL", Tff_string}, {"NULL", Tff_string}, {"2", Tff_numeric},
+	}
+	var got []Tff_token
+	for _, tok := range tokens {
+		if tok.Token_type != Tff_comma && tok.Token_type != Tff_dataEnd {
+			got = append(got, tok)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("token %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestQuoteTffStringRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"a, [b]",
+		`say "hi" ->End`,
+		"line\nnext\r\ttab",
+		`C:\dir\`,
+		"한글, 값",
+	} {
+		line := "Data-> [" + QuoteTffString(s) + "] ->End"
+		got := dataValues(t, line)
+		if len(got) != 1 || got[0] != (Tff_token{s, Tff_string}) {
+			t.Errorf("%q: %s read back as %v", s, line, got)
+		}
+	}
+}
+
+func TestParseDataLineInvalid(t *testing.T) {
+	for _, line := range []string{
+		`Data-> [1, "open] ->End`,
+		`Data-> [1, "a" b] ->End`,
+		`Data-> [1, "bad \x escape"] ->End`,
+		`Data-> [1, 2]`,
+	} {
+		var tokens []Tff_token
+		if ParseDataLine(line, &tokens) == 0 {
+			t.Errorf("%s: expected parse error", line)
+		}
+	}
+}
+
+func TestParseHeaderDefaults(t *testing.T) {
+	header := "Title : \"t\"\n\nTABLE_S BEGIN\n" +
+		"    TEXT id NOTNULL KEY DEFAULT UUID(),\n" +
+		"    TEXT name NOTNULL DEFAULT \"a, \\\"b\\\"\",\n" +
+		"    INTEGER visits DEFAULT 0, TIMESTAMP created DEFAULT NOW(), TEXT note DEFAULT NULL\n" +
+		"END\n"
+	var tokens []Tff_token
+	if ParseHeader(header, &tokens) != 0 {
+		t.Fatal("parse failed")
+	}
+
+	var defaults []Tff_token
+	for i, tok := range tokens {
+		if tok.Token_type == Tff_Default && i+1 < len(tokens) {
+			defaults = append(defaults, tokens[i+1])
+		}
+	}
+	want := []Tff_token{
+		{"uuid", Tff_function},
+		{"a, \"b\"", Tff_string},
+		{"0", Tff_numeric},
+		{"now", Tff_function},
+		{nil, Tff_null},
+	}
+	if len(defaults) != len(want) {
+		t.Fatalf("got %v, want %v", defaults, want)
+	}
+	for i := range want {
+		if defaults[i] != want[i] {
+			t.Errorf("default %d: got %v, want %v", i, defaults[i], want[i])
+		}
+	}
+
+	bad := "TABLE_S BEGIN\n    TEXT id KEY DEFAULT\nEND\n"
+	if ParseHeader(bad, &tokens) == 0 {
+		t.Error("DEFAULT without a value was accepted")
+	}
+}
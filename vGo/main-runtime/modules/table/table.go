@@ -3,21 +3,72 @@ package table
 type Column_type int
 
 const (
-	CT_none Column_type = iota
-	CT_number
+	CT_none   Column_type = iota
+	CT_number             // legacy number, stored as float64
 	CT_text
+	CT_integer   // int64
+	CT_float     // float64
+	CT_decimal   // exact decimal with a fixed scale
+	CT_bool      // true or false
+	CT_date      // calendar date without a time zone
+	CT_timestamp // point in time, stored in UTC
+	CT_blob      // raw bytes
+	CT_json      // JSON document, validated on insert
+)
+
+type Default_kind int
+
+const (
+	DK_none  Default_kind = iota
+	DK_value              // literal value in Default (canonical text form)
+	DK_null               // explicit DEFAULT NULL
+	DK_now                // current time, NOW()
+	DK_uuid               // random UUID (version 4), UUID()
+	DK_ulid               // time-ordered random ULID, ULID()
+)
+
+type Generated_kind int
+
+const (
+	GK_none      Generated_kind = iota
+	GK_increment                // AUTO_INCREMENT: next value of the table's counter (INTEGER key)
+	GK_uuid                     // AUTO_UUID: random UUID (TEXT key)
+	GK_ulid                     // AUTO_ULID: time-ordered ULID (TEXT key)
+)
+
+type Ref_action int
+
+const (
+	RA_restrict Ref_action = iota // ON DELETE RESTRICT: referenced rows cannot be deleted
+	RA_cascade                    // ON DELETE CASCADE: referencing rows are deleted too
+	RA_setNull                    // ON DELETE SET NULL: referencing values become NULL
 )
 
 type Column struct {
-	Type     Column_type
-	Name     string
-	Is_key   bool
-	Not_null bool
+	Type         Column_type
+	Name         string
+	Is_key       bool
+	Not_null     bool
+	Unique       bool           // no two rows may share a non-NULL value
+	Generated    Generated_kind // key value generated by ADD when omitted
+	Scale        int            // fractional digits of a CT_decimal column
+	Default_kind Default_kind
+	Default      string     // literal default when Default_kind is DK_value
+	References   string     // table whose KEY this column refers to (foreign key), empty if none
+	On_delete    Ref_action // what happens to this row when the referenced row is deleted
+}
+
+// Check is a named CHECK constraint. Expr is the condition in canonical
+// script form; a row is rejected when the condition evaluates to false.
+type Check struct {
+	Name string
+	Expr string
 }
 
 type Table struct {
 	Table_name     string
 	Columns_struct []Column
+	Checks         []Check
 }
 
 // NewTable creates a new Table
@@ -58,9 +109,133 @@ func AddNumber(t *Table, name string, isKey bool, notNull bool) int {
 	return AddColumn(t, name, CT_number, isKey, notNull)
 }
 
-// Reset clears all columns
+// AddInteger adds an integer column
+// Returns: 0 on success, -1 on error
+func AddInteger(t *Table, name string, isKey bool, notNull bool) int {
+	return AddColumn(t, name, CT_integer, isKey, notNull)
+}
+
+// AddFloat adds a float column
+// Returns: 0 on success, -1 on error
+func AddFloat(t *Table, name string, isKey bool, notNull bool) int {
+	return AddColumn(t, name, CT_float, isKey, notNull)
+}
+
+// AddDecimal adds a decimal column with a fixed number of fractional digits
+// Returns: 0 on success, -1 on error
+func AddDecimal(t *Table, name string, scale int, isKey bool, notNull bool) int {
+	if scale < 0 {
+		return -1
+	}
+	if AddColumn(t, name, CT_decimal, isKey, notNull) != 0 {
+		return -1
+	}
+	t.Columns_struct[len(t.Columns_struct)-1].Scale = scale
+	return 0
+}
+
+// AddBool adds a boolean column
+// Returns: 0 on success, -1 on error
+func AddBool(t *Table, name string, isKey bool, notNull bool) int {
+	return AddColumn(t, name, CT_bool, isKey, notNull)
+}
+
+// AddDate adds a date column
+// Returns: 0 on success, -1 on error
+func AddDate(t *Table, name string, isKey bool, notNull bool) int {
+	return AddColumn(t, name, CT_date, isKey, notNull)
+}
+
+// AddTimestamp adds a timestamp column
+// Returns: 0 on success, -1 on error
+func AddTimestamp(t *Table, name string, isKey bool, notNull bool) int {
+	return AddColumn(t, name, CT_timestamp, isKey, notNull)
+}
+
+// AddBlob adds a binary data column
+// Returns: 0 on success, -1 on error
+func AddBlob(t *Table, name string, isKey bool, notNull bool) int {
+	return AddColumn(t, name, CT_blob, isKey, notNull)
+}
+
+// AddJSON adds a JSON column
+// Returns: 0 on success, -1 on error
+func AddJSON(t *Table, name string, isKey bool, notNull bool) int {
+	return AddColumn(t, name, CT_json, isKey, notNull)
+}
+
+// SetDefault sets the default value of a column
+// Returns: 0 on success, -1 on error
+func SetDefault(t *Table, name string, kind Default_kind, value string) int {
+	for i := range t.Columns_struct {
+		if t.Columns_struct[i].Name == name {
+			t.Columns_struct[i].Default_kind = kind
+			t.Columns_struct[i].Default = value
+			return 0
+		}
+	}
+	return -1
+}
+
+// SetUnique marks a column as UNIQUE
+// Returns: 0 on success, -1 on error
+func SetUnique(t *Table, name string) int {
+	for i := range t.Columns_struct {
+		if t.Columns_struct[i].Name == name {
+			t.Columns_struct[i].Unique = true
+			return 0
+		}
+	}
+	return -1
+}
+
+// SetGenerated sets how the key value of a column is generated
+// Returns: 0 on success, -1 on error
+func SetGenerated(t *Table, name string, kind Generated_kind) int {
+	for i := range t.Columns_struct {
+		if t.Columns_struct[i].Name == name {
+			t.Columns_struct[i].Generated = kind
+			return 0
+		}
+	}
+	return -1
+}
+
+// SetReferences makes a column a foreign key to the KEY of another table
+// Returns: 0 on success, -1 on error
+func SetReferences(t *Table, name string, parent string, onDelete Ref_action) int {
+	if parent == "" {
+		return -1
+	}
+	for i := range t.Columns_struct {
+		if t.Columns_struct[i].Name == name {
+			t.Columns_struct[i].References = parent
+			t.Columns_struct[i].On_delete = onDelete
+			return 0
+		}
+	}
+	return -1
+}
+
+// AddCheck adds a named CHECK constraint to the table
+// Returns: 0 on success, -1 on error (empty or duplicate name)
+func AddCheck(t *Table, name string, expr string) int {
+	if name == "" || expr == "" {
+		return -1
+	}
+	for _, check := range t.Checks {
+		if check.Name == name {
+			return -1
+		}
+	}
+	t.Checks = append(t.Checks, Check{Name: name, Expr: expr})
+	return 0
+}
+
+// Reset clears all columns and CHECK constraints
 // Returns: 0 on success
 func Reset(t *Table) int {
 	t.Columns_struct = make([]Column, 0)
+	t.Checks = nil
 	return 0
 }